@@ -0,0 +1,533 @@
+package goesprima
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// An ApplyFunc is invoked by Apply for each node n, even if n is nil,
+// before and/or after the node's children, using a Cursor describing
+// the current node and providing operations on it.
+//
+// The return value of ApplyFunc controls the syntax tree traversal.
+// See Apply for details.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses a syntax tree recursively, starting with root,
+// and calling pre and post for each node as described below.
+// Apply returns the syntax tree, possibly modified.
+//
+// If pre is not nil, it is called for each node before the node's
+// children are traversed (pre-order). If pre returns false, no
+// children are traversed, and post is not called for that node.
+//
+// If post is not nil, and a prior call of pre didn't return false,
+// post is called for each node after its children are traversed
+// (post-order). If post returns false, traversal is terminated and
+// Apply returns immediately.
+//
+// Only fields that refer to nodes are traversed. Children are traversed
+// in the same order as Walk. Value-typed children such as BlockStatement
+// bodies and VariableDeclarators are presented as pointers to the field.
+func Apply(root JSElement, pre, post ApplyFunc) (result JSElement) {
+	parent := &applyRoot{root}
+	defer func() {
+		if r := recover(); r != nil && r != abort {
+			panic(r)
+		}
+		result = parent.Root
+	}()
+	a := &application{pre: pre, post: post}
+	a.apply(parent, "Root", nil, root)
+	return
+}
+
+var abort = new(int) // singleton, to signal termination of Apply
+
+// applyRoot holds the root of an Apply traversal so that it can be
+// replaced like any other node.
+type applyRoot struct {
+	Root JSElement
+}
+
+func (r *applyRoot) String() string {
+	return r.Root.String()
+}
+
+// A Cursor describes a node encountered during Apply.
+// Information about the node and its parent is available
+// from the Node, Parent, Name, and Index methods.
+//
+// If p is a variable of type and value of the current parent node
+// c.Parent(), and f is the field identifier with name c.Name(),
+// the following invariants hold:
+//
+//	p.f            == c.Node()  if c.Index() <  0
+//	p.f[c.Index()] == c.Node()  if c.Index() >= 0
+//
+// For value-typed fields such as ArrowFunctionExpression.Body,
+// c.Node() is a pointer to the field.
+//
+// The methods Replace, Delete, InsertBefore, and InsertAfter
+// can be used to change the AST without disrupting Apply.
+type Cursor struct {
+	parent JSElement
+	name   string
+	iter   *iterator // valid if non-nil
+	node   JSElement
+}
+
+// Node returns the current Node.
+func (c *Cursor) Node() JSElement { return c.node }
+
+// Parent returns the parent of the current Node, or nil for the root.
+func (c *Cursor) Parent() JSElement {
+	if _, ok := c.parent.(*applyRoot); ok {
+		return nil
+	}
+	return c.parent
+}
+
+// Name returns the name of the parent Node field that contains the current
+// Node. If the parent is a *Program and the current Node is a statement,
+// Name returns "Body".
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index >= 0 of the current Node in the slice of Nodes
+// that contains it, or a value < 0 if the current Node is not part of a
+// slice. The index of the current node changes if InsertBefore is called
+// while processing the current node.
+func (c *Cursor) Index() int {
+	if c.iter != nil {
+		return c.iter.index
+	}
+	return -1
+}
+
+// field returns the current node's parent field value.
+func (c *Cursor) field() reflect.Value {
+	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
+}
+
+// Replace replaces the current Node with n. The replacement node is not
+// walked by Apply. It panics if n cannot be stored in the parent field,
+// eg. a Statement where a []ObjectExpressionProperty element is expected.
+func (c *Cursor) Replace(n JSElement) {
+	v := c.field()
+	if i := c.Index(); i >= 0 {
+		v = v.Index(i)
+	}
+	v.Set(c.convert(v.Type(), n))
+	if v.Kind() != reflect.Struct {
+		c.node = n
+	}
+}
+
+// Delete deletes the current Node from its containing slice.
+// If the current Node is not part of a slice, Delete panics.
+func (c *Cursor) Delete() {
+	i := c.Index()
+	if i < 0 {
+		panic("Delete node not contained in slice")
+	}
+	v := c.field()
+	l := v.Len()
+	reflect.Copy(v.Slice(i, l), v.Slice(i+1, l))
+	v.Index(l - 1).Set(reflect.Zero(v.Type().Elem()))
+	v.SetLen(l - 1)
+	c.iter.step--
+}
+
+// InsertAfter inserts n after the current Node in its containing slice.
+// If the current Node is not part of a slice, InsertAfter panics.
+// Apply does not walk n.
+func (c *Cursor) InsertAfter(n JSElement) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertAfter node not contained in slice")
+	}
+	v := c.field()
+	value := c.convert(v.Type().Elem(), n)
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+2, l), v.Slice(i+1, l))
+	v.Index(i + 1).Set(value)
+	c.iter.step++
+}
+
+// InsertBefore inserts n before the current Node in its containing slice.
+// If the current Node is not part of a slice, InsertBefore panics.
+// Apply will not walk n.
+func (c *Cursor) InsertBefore(n JSElement) {
+	i := c.Index()
+	if i < 0 {
+		panic("InsertBefore node not contained in slice")
+	}
+	v := c.field()
+	value := c.convert(v.Type().Elem(), n)
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	l := v.Len()
+	reflect.Copy(v.Slice(i+1, l), v.Slice(i, l))
+	v.Index(i).Set(value)
+	c.iter.index++
+}
+
+// convert checks that n can be stored in a field of type t. Value-typed
+// fields accept a pointer to their type.
+func (c *Cursor) convert(t reflect.Type, n JSElement) reflect.Value {
+	if n == nil {
+		if t.Kind() == reflect.Interface || t.Kind() == reflect.Ptr {
+			return reflect.Zero(t)
+		}
+	} else {
+		v := reflect.ValueOf(n)
+		if v.Type().AssignableTo(t) {
+			return v
+		}
+		if t.Kind() == reflect.Struct && v.Type() == reflect.PtrTo(t) && !v.IsNil() {
+			return v.Elem()
+		}
+	}
+	panic(fmt.Sprintf("goesprima: cannot use %T as %s in %T.%s", n, t, c.parent, c.name))
+}
+
+// application carries all the shared data so we can pass it around cheaply.
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+	iter      iterator
+}
+
+func (a *application) apply(parent JSElement, name string, iter *iterator, n JSElement) {
+	// convert typed nil into untyped nil
+	if isNilValue(reflect.ValueOf(n)) {
+		n = nil
+	}
+
+	// avoid heap-allocating a new cursor for each apply call; reuse a.cursor instead
+	saved := a.cursor
+	a.cursor.parent = parent
+	a.cursor.name = name
+	a.cursor.iter = iter
+	a.cursor.node = n
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		a.cursor = saved
+		return
+	}
+
+	// walk children
+	// (the order of the cases matches the order of the corresponding node types in walk.go)
+	switch n := n.(type) {
+	case nil:
+		// nothing to do
+
+	// Program
+	case *Generator:
+		a.applyList(n, "Statements")
+
+	case *Program:
+		a.applyList(n, "Body")
+
+	// Exports
+	case *ExportAllDeclaration:
+		a.apply(n, "Exported", nil, n.Exported)
+		a.apply(n, "Source", nil, n.Source)
+
+	case *ExportDefaultDeclaration:
+		a.apply(n, "Declaration", nil, n.Declaration)
+
+	case *ExportNamedDeclaration:
+		a.apply(n, "Declaration", nil, n.Declaration)
+		a.applyList(n, "Specifiers")
+		a.apply(n, "Source", nil, n.Source)
+
+	case *ExportSpecifier:
+		a.apply(n, "Exported", nil, n.Exported)
+		a.apply(n, "Local", nil, n.Local)
+
+	// Patterns
+	case *ArrayPattern:
+		a.applyList(n, "Elements")
+
+	case *ObjectPattern:
+		a.applyList(n, "Properties")
+
+	case *AssignmentPattern:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *RestElement:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *PropertyPattern:
+		a.apply(n, "Key", nil, n.Key)
+		a.apply(n, "Value", nil, n.Value)
+
+	// Leaves
+	case *Identifier, *literalValueNull, *literalValueUndefined,
+		*LiteralValueString, *LiteralValueBool, *LiteralValueNumber,
+		*LiteralValueBigFloat, *TemplateElement, *DebuggerStatement,
+		*EmptyStatement, *ThisExpression, *Super, *Import:
+		// nothing to do
+
+	// Expressions
+	case *ArrayExpression:
+		a.applyList(n, "Elements")
+
+	case *ArrowFunctionExpression:
+		a.applyList(n, "Params")
+		if n.Expression != nil {
+			a.apply(n, "Expression", nil, n.Expression)
+		} else {
+			a.apply(n, "Body", nil, &n.Body)
+		}
+
+	case *AwaitExpression:
+		a.apply(n, "Arguement", nil, n.Arguement)
+
+	case *AssignmentExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *BinaryExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *LogicalExpression:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+
+	case *CallExpression:
+		a.apply(n, "Callee", nil, n.Callee)
+		a.applyList(n, "Arguments")
+
+	case *ChainExpression:
+		a.apply(n, "Expression", nil, n.Expression)
+
+	case *ClassExpression:
+		a.apply(n, "ID", nil, n.ID)
+		a.apply(n, "SuperClass", nil, n.SuperClass)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ComputedMemberExpression:
+		a.apply(n, "Object", nil, n.Object)
+		a.apply(n, "Property", nil, n.Property)
+
+	case *ConditionalExpression:
+		a.apply(n, "Test", nil, n.Test)
+		a.apply(n, "Consequent", nil, n.Consequent)
+		a.apply(n, "Alternate", nil, n.Alternate)
+
+	case *FunctionExpression:
+		a.apply(n, "ID", nil, n.ID)
+		a.applyList(n, "Params")
+		a.apply(n, "Body", nil, &n.Body)
+
+	case *MetaProperty:
+		a.apply(n, "Meta", nil, &n.Meta)
+		a.apply(n, "Property", nil, &n.Property)
+
+	case *NewExpression:
+		a.apply(n, "Callee", nil, n.Callee)
+		a.applyList(n, "Arguments")
+
+	case *ObjectExpression:
+		a.applyList(n, "Properties")
+
+	case *Property:
+		a.apply(n, "Key", nil, n.Key)
+		a.apply(n, "Value", nil, n.Value)
+
+	case *SequenceExpression:
+		a.applyList(n, "Expressions")
+
+	case *SpreadElement:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *StaticMemberExpression:
+		a.apply(n, "Object", nil, n.Object)
+		a.apply(n, "Property", nil, n.Property)
+
+	case *TaggedTemplateExpression:
+		a.apply(n, "Tag", nil, n.Tag)
+		a.apply(n, "Quasi", nil, &n.Quasi)
+
+	case *TemplateLiteral:
+		a.applyList(n, "Quasis")
+		a.applyList(n, "Expressions")
+
+	case *UnaryExpression:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *UpdateExpression:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *YieldExpression:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	// Classes
+	case *ClassDeclaration:
+		a.apply(n, "ID", nil, n.ID)
+		a.apply(n, "SuperClass", nil, n.SuperClass)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ClassBody:
+		a.applyList(n, "Properties")
+
+	case *MethodDefinition:
+		a.apply(n, "Key", nil, n.Key)
+		a.apply(n, "Value", nil, &n.Value)
+
+	case *PropertyDefinition:
+		a.apply(n, "Key", nil, n.Key)
+		a.apply(n, "Value", nil, n.Value)
+
+	// Declarations
+	case *FunctionDeclaration:
+		a.apply(n, "ID", nil, n.ID)
+		a.applyList(n, "Params")
+		a.apply(n, "Body", nil, &n.Body)
+
+	case *ImportDeclaration:
+		a.applyList(n, "Specifiers")
+
+	case *ImportDefaultSpecifier:
+		a.apply(n, "Local", nil, n.Local)
+
+	case *ImportNamespaceSpecifier:
+		a.apply(n, "Local", nil, n.Local)
+
+	case *ImportSpecifier:
+		a.applyList(n, "NamedImports")
+
+	case *NamedImport:
+		a.apply(n, "Imported", nil, n.Imported)
+		a.apply(n, "Local", nil, n.Local)
+
+	case *VariableDeclaration:
+		a.applyList(n, "Declarations")
+
+	case *VariableDeclarator:
+		a.apply(n, "ID", nil, n.ID)
+		a.apply(n, "Init", nil, n.Init)
+
+	// Statements
+	case *BlockStatement:
+		a.applyList(n, "Items")
+
+	case *BreakStatement:
+		a.apply(n, "Label", nil, n.Label)
+
+	case *ContinueStatement:
+		a.apply(n, "Label", nil, n.Label)
+
+	case *DoWhileStatement:
+		a.apply(n, "Body", nil, &n.Body)
+		a.apply(n, "Test", nil, n.Test)
+
+	case *ExpressionStatement:
+		a.apply(n, "Expression", nil, n.Expression)
+
+	case *Directive:
+		a.apply(n, "Expression", nil, n.Expression)
+
+	case *ForStatement:
+		a.apply(n, "Init", nil, n.Init)
+		a.apply(n, "Test", nil, n.Test)
+		a.apply(n, "Update", nil, n.Update)
+		a.apply(n, "Body", nil, &n.Body)
+
+	case *ForInStatement:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+		a.apply(n, "Body", nil, &n.Body)
+
+	case *ForOfStatement:
+		a.apply(n, "Left", nil, n.Left)
+		a.apply(n, "Right", nil, n.Right)
+		a.apply(n, "Body", nil, &n.Body)
+
+	case *IfStatement:
+		a.apply(n, "Test", nil, n.Test)
+		a.apply(n, "Consequent", nil, n.Consequent)
+		a.apply(n, "Alternate", nil, n.Alternate)
+
+	case *LabeledStatement:
+		a.apply(n, "Label", nil, &n.Label)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ReturnStatement:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *SwitchStatement:
+		a.apply(n, "Discriminant", nil, n.Discriminant)
+		a.applyList(n, "Cases")
+
+	case *SwitchCase:
+		a.apply(n, "Test", nil, n.Test)
+		a.apply(n, "Consequent", nil, &n.Consequent)
+
+	case *ThrowStatement:
+		a.apply(n, "Argument", nil, n.Argument)
+
+	case *TryStatement:
+		a.apply(n, "Block", nil, &n.Block)
+		if n.HasHandler() {
+			a.apply(n, "Handler", nil, &n.Handler)
+		}
+		a.apply(n, "Finalizer", nil, n.Finalizer)
+
+	case *CatchClause:
+		a.apply(n, "BindingIdentifierOrPattern", nil, n.BindingIdentifierOrPattern)
+		a.apply(n, "Body", nil, &n.Body)
+
+	case *WhileStatement:
+		a.apply(n, "Test", nil, n.Test)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *WithStatement:
+		a.apply(n, "Object", nil, n.Object)
+		a.apply(n, "Body", nil, n.Body)
+
+	default:
+		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(abort)
+	}
+
+	a.cursor = saved
+}
+
+// An iterator controls iteration over a slice of nodes.
+type iterator struct {
+	index, step int
+}
+
+func (a *application) applyList(parent JSElement, name string) {
+	// avoid heap-allocating a new iterator for each applyList call; reuse a.iter instead
+	saved := a.iter
+	a.iter.index = 0
+	for {
+		// must reload parent.name each time, since cursor modifications might change it
+		v := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
+		if a.iter.index >= v.Len() {
+			break
+		}
+
+		// element x may be nil in a bad AST - be cautious
+		var x JSElement
+		if e := v.Index(a.iter.index); e.Kind() == reflect.Struct {
+			x = e.Addr().Interface().(JSElement)
+		} else if !e.IsNil() {
+			x = e.Interface().(JSElement)
+		}
+
+		a.iter.step = 1
+		a.apply(parent, name, &a.iter, x)
+		a.iter.index += a.iter.step
+	}
+	a.iter = saved
+}
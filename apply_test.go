@@ -0,0 +1,97 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	program := &Program{
+		Body: []StatementListItem{
+			&DebuggerStatement{},
+			&ExpressionStatement{
+				Expression: &CallExpression{
+					Callee:    &Identifier{Name: "configure"},
+					Arguments: []ArgumentListElement{&Identifier{Name: "config"}},
+				},
+			},
+			&VariableDeclaration{
+				Kind: VariableDeclarationTypeConst,
+				Declarations: []VariableDeclarator{
+					{ID: &Identifier{Name: "a"}, Init: &ObjectExpression{
+						Properties: []ObjectExpressionProperty{
+							&Property{Key: &Identifier{Name: "b"}, Value: &Identifier{Name: "config"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	result := Apply(program, func(c *Cursor) bool {
+		switch n := c.Node().(type) {
+		case *DebuggerStatement:
+			assert.Equal(t, "Body", c.Name())
+			assert.Equal(t, 0, c.Index())
+			assert.Same(t, program, c.Parent())
+			c.Delete()
+		case *ExpressionStatement:
+			c.InsertBefore(&VariableDeclaration{
+				Kind: VariableDeclarationTypeLet,
+				Declarations: []VariableDeclarator{
+					{ID: &Identifier{Name: "config"}, Init: LiteralValueNull},
+				},
+			})
+		case *Identifier:
+			if n.Name == "config" {
+				c.Replace(&StaticMemberExpression{
+					Object:   &Identifier{Name: "window"},
+					Property: &Identifier{Name: "config"},
+				})
+			}
+		case *Property:
+			c.InsertAfter(&SpreadElement{Argument: &Identifier{Name: "defaults"}})
+			return false
+		}
+		return true
+	}, nil)
+
+	assert.Same(t, program, result)
+	assert.Equal(t, `let config = null
+configure(window.config);
+const a = {
+  b: config,
+  ...defaults,
+}`, program.String())
+
+	assert.PanicsWithValue(t, "goesprima: cannot use *goesprima.ReturnStatement as goesprima.ObjectExpressionProperty in *goesprima.ObjectExpression.Properties", func() {
+		Apply(program, func(c *Cursor) bool {
+			if _, ok := c.Node().(*Property); ok {
+				c.Replace(&ReturnStatement{})
+			}
+			return true
+		}, nil)
+	})
+}
+
+func TestApplyValueFields(t *testing.T) {
+	arrow := &ArrowFunctionExpression{
+		Body: BlockStatement{Items: []Statement{&ReturnStatement{}}},
+	}
+	Apply(arrow, nil, func(c *Cursor) bool {
+		if _, ok := c.Node().(*BlockStatement); ok && c.Name() == "Body" {
+			c.Replace(&BlockStatement{Items: []Statement{&DebuggerStatement{}}})
+		}
+		return true
+	})
+	assert.Equal(t, "() => {\n  debugger;\n}", arrow.String())
+
+	var root JSElement = &Identifier{Name: "a"}
+	root = Apply(root, func(c *Cursor) bool {
+		assert.Nil(t, c.Parent())
+		c.Replace(&Identifier{Name: "b"})
+		return false
+	}, nil)
+	assert.Equal(t, "b", root.String())
+}
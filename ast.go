@@ -1,25 +1,9 @@
 package goesprima
 
 import (
-	"encoding/json"
-	"fmt"
 	"math/big"
-	"strconv"
-	"strings"
-	"sync"
 )
 
-var (
-	indentor Indentor = &Spaces{2}
-	mu       sync.Mutex
-)
-
-func SetGlobaIndentor(i Indentor) {
-	mu.Lock()
-	defer mu.Unlock()
-	indentor = i
-}
-
 type JSElement interface {
 	String() string
 }
@@ -60,9 +44,14 @@ var (
 	_ Expression = new(SequenceExpression)
 	_ Expression = new(StaticMemberExpression)
 	_ Expression = new(TaggedTemplateExpression)
+	_ Expression = new(TemplateLiteral)
 	_ Expression = new(UnaryExpression)
 	_ Expression = new(UpdateExpression)
 	_ Expression = new(YieldExpression)
+	_ Expression = new(ThisExpression)
+	_ Expression = new(Super)
+	_ Expression = new(MetaProperty)
+	_ Expression = new(Import)
 
 	// Declarations
 	_ Declaration = new(ClassDeclaration)
@@ -71,6 +60,9 @@ var (
 	_ Declaration = new(VariableDeclaration)
 
 	// Statements
+	_ Statement = new(ClassDeclaration)
+	_ Statement = new(FunctionDeclaration)
+	_ Statement = new(VariableDeclaration)
 	_ Statement = new(BreakStatement)
 	_ Statement = new(ContinueStatement)
 	_ Statement = new(DebuggerStatement)
@@ -89,6 +81,7 @@ var (
 	_ Statement = new(WhileStatement)
 	_ Statement = new(WithStatement)
 	_ Statement = new(BlockStatement)
+	_ Statement = new(LabeledStatement)
 
 	// StatementListItems
 	_ StatementListItem = new(ClassDeclaration)
@@ -113,6 +106,7 @@ var (
 	_ StatementListItem = new(WhileStatement)
 	_ StatementListItem = new(WithStatement)
 	_ StatementListItem = new(BlockStatement)
+	_ StatementListItem = new(LabeledStatement)
 
 	// ArrayPatternElements
 	_ ArrayPatternElement = new(AssignmentPattern)
@@ -138,6 +132,11 @@ var (
 
 	// FunctionParameter
 	_ FunctionParameter = new(Identifier)
+	_ FunctionParameter = new(AssignmentPattern)
+	_ FunctionParameter = new(RestElement)
+
+	// ForInit
+	_ ForInit = new(VariableDeclaration)
 
 	// ImportDeclarationSpecifiers
 	_ ImportDeclarationSpecifier = new(ImportDefaultSpecifier)
@@ -223,6 +222,8 @@ type Expression interface {
 	ArgumentListElement
 	ArrayExpressionElement
 	ExpressionOrImport
+	ForInit
+	PropertyKey
 }
 
 type Declaration interface {
@@ -234,6 +235,7 @@ type Declaration interface {
 
 type Statement interface {
 	JSElement
+	StatementListItem
 	statement()
 }
 
@@ -269,7 +271,7 @@ type FunctionParameter interface {
 
 type ChainElement interface {
 	JSElement
-	chainElementToString() string
+	chainElement()
 }
 
 type ExportableDefaultDeclaration interface {
@@ -302,15 +304,24 @@ type ExpressionOrImport interface {
 	expressionOrImport()
 }
 
+// Initializer of a for statement or left side of a for-in/of statement,
+// either an expression or a variable declaration.
+type ForInit interface {
+	JSElement
+	forInit()
+}
+
 // Structs
 
 type ExportAllDeclaration struct {
 	Source Literal
+	// Exported is the namespace name of export * as ns from "module".
+	Exported *Identifier
 	*Node
 }
 
 func (e *ExportAllDeclaration) String() string {
-	panic("ExportAllDeclaration not implemented")
+	return sprint(e)
 }
 
 type ExportDefaultDeclaration struct {
@@ -319,40 +330,31 @@ type ExportDefaultDeclaration struct {
 }
 
 func (e *ExportDefaultDeclaration) String() string {
-	return "export default " + e.Declaration.String()
+	return sprint(e)
 }
 
 type ExportNamedDeclaration struct {
 	Declaration ExportableNamedDeclaration
 	Specifiers  []ExportSpecifier
+	// Source is the module re-exported from, eg. export { a } from "./a.js".
+	Source Literal
 	*Node
 }
 
-func (e *ExportNamedDeclaration) String() (s string) {
-	s = "export "
-	if e.Declaration != nil {
-		s += e.Declaration.String()
-	} else {
-		spec := jsElementsToString(e.Specifiers)
-
-		s += "{ " + strings.Join(spec, ", ") + " }"
-	}
-	s += ";"
-	return
+func (e *ExportNamedDeclaration) String() string {
+	return sprint(e)
 }
 
+// ExportSpecifier exports the local binding Local under the name Exported.
+// Local may be omitted when both names are the same.
 type ExportSpecifier struct {
 	Exported *Identifier
 	Local    *Identifier
 	*Node
 }
 
-func (e ExportSpecifier) String() (s string) {
-	s = e.Exported.String()
-	if e.Local != nil {
-		s += " as " + e.Local.String()
-	}
-	return
+func (e ExportSpecifier) String() string {
+	return sprint(&e)
 }
 
 type BlockStatement struct {
@@ -361,8 +363,7 @@ type BlockStatement struct {
 }
 
 func (b *BlockStatement) String() string {
-	r := jsElementsToString(b.Items)
-	return strings.Join(r, "\n")
+	return sprint(b)
 }
 
 type ArrayPattern struct {
@@ -370,14 +371,8 @@ type ArrayPattern struct {
 	*Node
 }
 
-func (a *ArrayPattern) String() (s string) {
-	if len(a.Elements) == 0 {
-		return "[]"
-	}
-	s = "[\n"
-	r := jsElementsToString(a.Elements)
-	s += indentor.Indent(strings.Join(r, ", ")) + "\n]"
-	return
+func (a *ArrayPattern) String() string {
+	return sprint(a)
 }
 
 type ObjectPattern struct {
@@ -385,15 +380,8 @@ type ObjectPattern struct {
 	*Node
 }
 
-func (o *ObjectPattern) String() (s string) {
-	l := len(o.Properties)
-	if l == 0 {
-		return "{}"
-	}
-	s = "{\n"
-	props := indentor.IndentArray(jsElementsToString(o.Properties))
-	s += strings.Join(props, ",\n") + ",\n}"
-	return
+func (o *ObjectPattern) String() string {
+	return sprint(o)
 }
 
 type Identifier struct {
@@ -402,54 +390,55 @@ type Identifier struct {
 }
 
 func (i *Identifier) String() string {
-	return i.Name
+	return sprint(i)
 }
 
 type AssignmentPattern struct {
 	Left  BindingIdentifierOrPattern
 	Right Expression
+	*Node
 }
 
 func (a *AssignmentPattern) String() string {
-	return a.Left.String() + "=" + a.Right.String()
+	return sprint(a)
 }
 
 type literalValueNull struct{}
 
 func (l *literalValueNull) String() string {
-	return "null"
+	return sprint(l)
 }
 
 type literalValueUndefined struct{}
 
 func (l *literalValueUndefined) String() string {
-	return "undefined"
+	return sprint(l)
 }
 
+// LiteralValueString is the value of a string literal in UTF-8. Lone
+// surrogates, which UTF-8 can't hold, are encoded as in WTF-8.
 type LiteralValueString string
 
 func (l *LiteralValueString) String() string {
-	b, _ := json.Marshal(l)
-	return string(b)
+	return sprint(l)
 }
 
 type LiteralValueBool bool
 
 func (l *LiteralValueBool) String() string {
-	return strconv.FormatBool(bool(*l))
+	return sprint(l)
 }
 
 type LiteralValueNumber float64
 
 func (l *LiteralValueNumber) String() string {
-	return strconv.FormatFloat(float64(*l), 'f', 6, 64)
+	return sprint(l)
 }
 
 type LiteralValueBigFloat big.Float
 
 func (l *LiteralValueBigFloat) String() string {
-	n := big.Float(*l)
-	return n.String()
+	return sprint(l)
 }
 
 // Expressions
@@ -458,31 +447,22 @@ type ArrayExpression struct {
 	*Node
 }
 
-func (a *ArrayExpression) String() (s string) {
-	if len(a.Elements) == 0 {
-		return "[]"
-	}
-	s = "[\n"
-	r := jsElementsToString(a.Elements)
-	s += indentor.Indent(strings.Join(r, ", ")) + ",\n]"
-	return
+func (a *ArrayExpression) String() string {
+	return sprint(a)
 }
 
 type ArrowFunctionExpression struct {
 	Params []FunctionParameter
 	Body   BlockStatement
-	Async  bool
+	// Expression is the body of a concise arrow function such as
+	// x => x + 1. Body is ignored when it is set.
+	Expression Expression
+	Async      bool
 	*Node
 }
 
-func (a *ArrowFunctionExpression) String() (s string) {
-	if a.Async {
-		s = "async "
-	}
-
-	params := jsElementsToString(a.Params)
-	s += "(" + strings.Join(params, ", ") + ") => {\n" + indentor.Indent((&a.Body).String()) + "\n}"
-	return
+func (a *ArrowFunctionExpression) String() string {
+	return sprint(a)
 }
 
 type AwaitExpression struct {
@@ -491,7 +471,7 @@ type AwaitExpression struct {
 }
 
 func (a *AwaitExpression) String() string {
-	return "await " + a.Arguement.String()
+	return sprint(a)
 }
 
 type AssignmentExpression struct {
@@ -502,18 +482,28 @@ type AssignmentExpression struct {
 }
 
 func (a *AssignmentExpression) String() string {
-	return a.Left.String() + " " + string(a.Operator) + " " + a.Right.String()
+	return sprint(a)
 }
 
 type assignmentOperator string
 
 const (
-	AssignmentOperatorEq     assignmentOperator = "="
-	AssignmentOperatorPlus   assignmentOperator = "+="
-	AssignmentOperatorMinus  assignmentOperator = "-="
-	AssignmentOperatorTimes  assignmentOperator = "*="
-	AssignmentOperatorDivide assignmentOperator = "/="
-	AssignmentOperatorMod    assignmentOperator = "%="
+	AssignmentOperatorEq                 assignmentOperator = "="
+	AssignmentOperatorPlus               assignmentOperator = "+="
+	AssignmentOperatorMinus              assignmentOperator = "-="
+	AssignmentOperatorTimes              assignmentOperator = "*="
+	AssignmentOperatorDivide             assignmentOperator = "/="
+	AssignmentOperatorMod                assignmentOperator = "%="
+	AssignmentOperatorExponent           assignmentOperator = "**="
+	AssignmentOperatorShiftLeft          assignmentOperator = "<<="
+	AssignmentOperatorShiftRight         assignmentOperator = ">>="
+	AssignmentOperatorZeroFillShiftRight assignmentOperator = ">>>="
+	AssignmentOperatorAND                assignmentOperator = "&="
+	AssignmentOperatorOR                 assignmentOperator = "|="
+	AssignmentOperatorXOR                assignmentOperator = "^="
+	AssignmentOperatorLogicalAnd         assignmentOperator = "&&="
+	AssignmentOperatorLogicalOr          assignmentOperator = "||="
+	AssignmentOperatorNullish            assignmentOperator = "??="
 )
 
 type BinaryExpression struct {
@@ -524,7 +514,7 @@ type BinaryExpression struct {
 }
 
 func (b *BinaryExpression) String() string {
-	return b.Left.String() + " " + string(b.Operator) + " " + b.Right.String()
+	return sprint(b)
 }
 
 type binaryOperator string
@@ -533,7 +523,7 @@ const (
 	BinaryOperatorADD                binaryOperator = "+"
 	BinaryOperatorMinus              binaryOperator = "-"
 	BinaryOperatorMultiply           binaryOperator = "*"
-	BinaryOperatorExponent           binaryOperator = "*"
+	BinaryOperatorExponent           binaryOperator = "**"
 	BinaryOperatorDivide             binaryOperator = "/"
 	BinaryOperatorModulus            binaryOperator = "%"
 	BinaryOperatorAND                binaryOperator = "&"
@@ -543,6 +533,16 @@ const (
 	BinaryOperatorSHIFTLEFT          binaryOperator = "<<"
 	BinaryOperatorSHIFTRIGHT         binaryOperator = ">>"
 	BinaryOperatorZEROFILLSHIFTRIGHT binaryOperator = ">>>"
+	BinaryOperatorEqual              binaryOperator = "=="
+	BinaryOperatorNotEqual           binaryOperator = "!="
+	BinaryOperatorStrictEqual        binaryOperator = "==="
+	BinaryOperatorStrictNotEqual     binaryOperator = "!=="
+	BinaryOperatorLess               binaryOperator = "<"
+	BinaryOperatorLessEqual          binaryOperator = "<="
+	BinaryOperatorGreater            binaryOperator = ">"
+	BinaryOperatorGreaterEqual       binaryOperator = ">="
+	BinaryOperatorIn                 binaryOperator = "in"
+	BinaryOperatorInstanceOf         binaryOperator = "instanceof"
 )
 
 type LogicalExpression struct {
@@ -553,7 +553,7 @@ type LogicalExpression struct {
 }
 
 func (l *LogicalExpression) String() string {
-	return l.Left.String() + " " + string(l.Operator) + " " + l.Right.String()
+	return sprint(l)
 }
 
 type logicalOperator string
@@ -572,11 +572,7 @@ type CallExpression struct {
 }
 
 func (c *CallExpression) String() string {
-	return c.Callee.String() + c.argsToString()
-}
-
-func (c *CallExpression) argsToString() string {
-	return "(" + argListToString(c.Arguments) + ")"
+	return sprint(c)
 }
 
 type CatchClause struct {
@@ -586,41 +582,36 @@ type CatchClause struct {
 }
 
 func (c CatchClause) String() string {
-	return "catch (" + c.BindingIdentifierOrPattern.String() + ") {\n" + indentor.Indent(c.Body.String()) + "\n}"
+	return sprint(&c)
 }
 
+// Import is the callee of a dynamic import, eg. import("./module.js").
 type Import struct {
 	*Node
 }
 
+func (i *Import) String() string {
+	return sprint(i)
+}
+
 type ChainExpression struct {
 	Expression ChainElement
 	*Node
 }
 
-func (c *ChainExpression) String() (s string) {
-	return c.Expression.chainElementToString()
+func (c *ChainExpression) String() string {
+	return sprint(c)
 }
 
 type ClassExpression struct {
 	ID         *Identifier
-	SuperClass *Identifier
+	SuperClass Expression
 	Body       *ClassBody
 	*Node
 }
 
-func (c *ClassExpression) String() (s string) {
-	s = "class "
-
-	if c.ID != nil {
-		s += c.ID.String() + " "
-	}
-
-	if c.SuperClass != nil {
-		s += "extends " + c.SuperClass.String() + " "
-	}
-	s += "{\n" + indentor.Indent(c.Body.String()) + "\n}"
-	return
+func (c *ClassExpression) String() string {
+	return sprint(c)
 }
 
 type ComputedMemberExpression struct {
@@ -631,7 +622,7 @@ type ComputedMemberExpression struct {
 }
 
 func (c *ComputedMemberExpression) String() string {
-	return c.Object.String() + "[" + c.Property.String() + "]"
+	return sprint(c)
 }
 
 type ConditionalExpression struct {
@@ -642,7 +633,7 @@ type ConditionalExpression struct {
 }
 
 func (c *ConditionalExpression) String() string {
-	return c.Test.String() + "? " + c.Consequent.String() + ": " + c.Alternate.String()
+	return sprint(c)
 }
 
 type FunctionExpression struct {
@@ -653,20 +644,8 @@ type FunctionExpression struct {
 	*Node
 }
 
-func (f *FunctionExpression) String() (s string) {
-	switch f.FunctionType {
-	case FunctionTypeAsync:
-		s = "async function "
-	case FunctionTypeGenerator:
-		s = "function* "
-	default:
-		s = "function "
-	}
-	if f.ID != nil {
-		s += f.ID.String()
-	}
-	s += "(" + functionParametersToString(f.Params) + ") {\n" + f.Body.String() + "\n}"
-	return
+func (f *FunctionExpression) String() string {
+	return sprint(f)
 }
 
 type NewExpression struct {
@@ -676,18 +655,7 @@ type NewExpression struct {
 }
 
 func (n *NewExpression) String() string {
-	return "new " + n.calleeToString() + "(" + argListToString(n.Arguments) + ")"
-}
-
-func (n *NewExpression) calleeToString() string {
-	switch v := n.Callee.(type) {
-	case *Identifier:
-		return v.String()
-	case *StaticMemberExpression:
-		return v.String()
-	default:
-		return "(" + n.Callee.String() + ")"
-	}
+	return sprint(n)
 }
 
 type ObjectExpression struct {
@@ -696,8 +664,7 @@ type ObjectExpression struct {
 }
 
 func (o *ObjectExpression) String() string {
-	args := objectExpressionPropertiesToString(o.Properties)
-	return "{\n" + indentor.Indent(args) + "\n}"
+	return sprint(o)
 }
 
 type SequenceExpression struct {
@@ -706,45 +673,18 @@ type SequenceExpression struct {
 }
 
 func (s *SequenceExpression) String() string {
-	out := jsElementsToString(s.Expressions)
-	return strings.Join(out, ", ")
+	return sprint(s)
 }
 
 type StaticMemberExpression struct {
 	Object   Expression
 	Property Expression
+	Optional bool
 	*Node
 }
 
 func (s *StaticMemberExpression) String() string {
-	return s.objectToString() + "." + s.propertyToString()
-}
-
-func (s *StaticMemberExpression) objectToString() string {
-	switch v := s.Object.(type) {
-	case *CallExpression:
-		return v.String()
-	case *Identifier:
-		return v.String()
-	case *StaticMemberExpression:
-		return v.String()
-	case *ComputedMemberExpression:
-		return v.String()
-	}
-	return "(" + s.Object.String() + ")"
-}
-
-func (s *StaticMemberExpression) propertyToString() string {
-	switch v := s.Property.(type) {
-	case *Identifier:
-		return v.Name
-	case *StaticMemberExpression:
-		return v.String()
-	case *CallExpression:
-		return v.String()
-	default:
-		return "(" + v.String() + ")"
-	}
+	return sprint(s)
 }
 
 type SwitchCase struct {
@@ -753,7 +693,7 @@ type SwitchCase struct {
 }
 
 func (s SwitchCase) String() string {
-	return "case " + s.Test.String() + ":\n" + indentor.Indent(s.Consequent.String())
+	return sprint(&s)
 }
 
 type TaggedTemplateExpression struct {
@@ -763,7 +703,7 @@ type TaggedTemplateExpression struct {
 }
 
 func (t *TaggedTemplateExpression) String() string {
-	panic("Not Implemented")
+	return sprint(t)
 }
 
 type TemplateLiteral struct {
@@ -772,7 +712,21 @@ type TemplateLiteral struct {
 	*Node
 }
 
+func (t *TemplateLiteral) String() string {
+	return sprint(t)
+}
+
+// TemplateElement is the raw text between the substitutions of a template
+// literal. Cooked holds the text with escape sequences interpreted.
 type TemplateElement struct {
+	Raw    string
+	Cooked string
+	Tail   bool
+	*Node
+}
+
+func (t *TemplateElement) String() string {
+	return sprint(t)
 }
 
 type UnaryExpression struct {
@@ -782,17 +736,7 @@ type UnaryExpression struct {
 }
 
 func (u *UnaryExpression) String() string {
-	var expr string
-	switch v := u.Argument.(type) {
-	case *Identifier:
-		expr = v.String()
-	case *StaticMemberExpression:
-		expr = v.String()
-
-	default:
-		expr = "(" + v.String() + ")"
-	}
-	return fmt.Sprintf(string(u.Operator), expr)
+	return sprint(u)
 }
 
 type UnaryOperatorType string
@@ -804,6 +748,11 @@ const (
 	UnaryOperatorTypeIncrementPostfix UnaryOperatorType = "%s++"
 	UnaryOperatorTypeDecrementPrefix  UnaryOperatorType = "--%s"
 	UnaryOperatorTypeDecrementPostfix UnaryOperatorType = "%s--"
+	UnaryOperatorTypeNot              UnaryOperatorType = "!%s"
+	UnaryOperatorTypeBitwiseNot       UnaryOperatorType = "~%s"
+	UnaryOperatorTypeTypeof           UnaryOperatorType = "typeof %s"
+	UnaryOperatorTypeVoid             UnaryOperatorType = "void %s"
+	UnaryOperatorTypeDelete           UnaryOperatorType = "delete %s"
 )
 
 type UpdateExpression struct {
@@ -812,7 +761,7 @@ type UpdateExpression struct {
 }
 
 func (u *UpdateExpression) String() string {
-	return u.Argument.String()
+	return sprint(u)
 }
 
 type YieldExpression struct {
@@ -821,15 +770,8 @@ type YieldExpression struct {
 	*Node
 }
 
-func (y *YieldExpression) String() (s string) {
-	s = "yield"
-	if y.Delegate {
-		s += "*"
-	}
-	if y.Argument != nil {
-		s += " " + y.Argument.String()
-	}
-	return
+func (y *YieldExpression) String() string {
+	return sprint(y)
 }
 
 // Declarations
@@ -840,39 +782,8 @@ type ClassDeclaration struct {
 	*Node
 }
 
-func (c *ClassDeclaration) String() (s string) {
-	s = "class "
-
-	if c.ID != nil {
-		s += c.ID.String() + " "
-	}
-
-	if c.SuperClass != nil {
-		s += superClassToString(c.SuperClass) + " "
-	}
-	s += "{\n" + indentor.Indent(c.Body.String()) + "\n}"
-	return
-}
-
-func superClassToString(exp Expression) (s string) {
-	s = "extends "
-	var computed bool
-	switch exp.(type) {
-	case *BinaryExpression:
-		computed = true
-	case *LogicalExpression:
-		computed = true
-	case Literal:
-		computed = true
-	}
-	if computed {
-		s += "("
-	}
-	s += exp.String()
-	if computed {
-		s += ")"
-	}
-	return
+func (c *ClassDeclaration) String() string {
+	return sprint(c)
 }
 
 type ClassBody struct {
@@ -881,68 +792,34 @@ type ClassBody struct {
 }
 
 func (c *ClassBody) String() string {
-	props := jsElementsToString(c.Properties)
-	return strings.Join(props, "\n")
+	return sprint(c)
 }
 
 type MethodDefinition struct {
-	Static bool
-	Key    PropertyKey
-	Value  FunctionExpression
+	Static   bool
+	Computed bool
+	Key      PropertyKey
+	Value    FunctionExpression
+	// Kind is one of "constructor", "method", "get" or "set". Empty means
+	// "method".
+	Kind string
 	*Node
 }
 
-func (m *MethodDefinition) String() (s string) {
-	if m.Static {
-		s = "static "
-	}
-
-	switch m.Value.FunctionType {
-	case FunctionTypeAsync:
-		s = "async "
-	case FunctionTypeGenerator:
-		s = "*"
-	}
-	s += m.keyToString() + m.valueToString()
-	return
-}
-
-func (m *MethodDefinition) valueToString() (s string) {
-	s += "(" + functionParametersToString(m.Value.Params) + ") {\n" + m.Value.Body.String() + "\n}"
-	return
-}
-
-func (m *MethodDefinition) keyToString() string {
-	switch v := m.Key.(type) {
-	case *Identifier:
-		return v.String()
-	default:
-		return "[" + v.String() + "]"
-	}
+func (m *MethodDefinition) String() string {
+	return sprint(m)
 }
 
 type PropertyDefinition struct {
-	Static bool
-	Key    PropertyKey
-	Value  Expression
+	Static   bool
+	Computed bool
+	Key      PropertyKey
+	Value    Expression
 	*Node
 }
 
-func (p *PropertyDefinition) String() (s string) {
-	if p.Static {
-		s = "static "
-	}
-	s += p.keyToString() + " = " + p.Value.String() + ";"
-	return
-}
-
-func (p *PropertyDefinition) keyToString() string {
-	switch v := p.Key.(type) {
-	case *Identifier:
-		return v.String()
-	default:
-		return "[" + v.String() + "]"
-	}
+func (p *PropertyDefinition) String() string {
+	return sprint(p)
 }
 
 type PropertyPattern struct {
@@ -955,55 +832,23 @@ type PropertyPattern struct {
 	*Node
 }
 
-func (p *PropertyPattern) String() (s string) {
-	if p.Computed {
-		s += "["
-	}
-	s += p.keyToString()
-	if p.Computed {
-		s += "]"
-	}
-
-	if p.Value != nil {
-		s += p.Value.String()
-	}
-	return
-}
-
-func (p *PropertyPattern) keyToString() string {
-	switch v := p.Key.(type) {
-	case *Identifier:
-		return v.String()
-	default:
-		return "[" + v.String() + "]"
-	}
+func (p *PropertyPattern) String() string {
+	return sprint(p)
 }
 
 type Property struct {
-	Key       PropertyKey
-	Value     Expression
+	Key      PropertyKey
+	Computed bool
+	Value    Expression
+	// Kind is one of "init", "get" or "set". Empty means "init".
 	Kind      string
 	Method    bool
 	ShortHand bool
 	*Node
 }
 
-func (p *Property) String() (s string) {
-	s = p.keyToString()
-
-	if p.Value != nil {
-		s += ": " + p.Value.String()
-	}
-	return
-}
-
-func (p *Property) keyToString() string {
-	switch v := p.Key.(type) {
-	case *Identifier:
-		return v.String()
-	default:
-		return "[" + v.String() + "]"
-	}
+func (p *Property) String() string {
+	return sprint(p)
 }
 
 type FunctionDeclaration struct {
@@ -1014,20 +859,8 @@ type FunctionDeclaration struct {
 	*Node
 }
 
-func (f *FunctionDeclaration) String() (s string) {
-	switch f.FunctionType {
-	case FunctionTypeAsync:
-		s = "async function "
-	case FunctionTypeGenerator:
-		s = "function* "
-	default:
-		s = "function "
-	}
-	if f.ID != nil {
-		s += f.ID.String()
-	}
-	s += "(" + functionParametersToString(f.Params) + ") {\n" + f.Body.String() + "\n}"
-	return
+func (f *FunctionDeclaration) String() string {
+	return sprint(f)
 }
 
 type FunctionType string
@@ -1036,6 +869,8 @@ const (
 	FunctionTypeNormal    FunctionType = "normal"
 	FunctionTypeAsync     FunctionType = "async"
 	FunctionTypeGenerator FunctionType = "generator"
+	// async function*
+	FunctionTypeAsyncGenerator FunctionType = "asyncGenerator"
 )
 
 type ImportDeclaration struct {
@@ -1044,48 +879,8 @@ type ImportDeclaration struct {
 	*Node
 }
 
-func (i *ImportDeclaration) String() (s string) {
-	s = "import "
-	if l := len(i.Specifiers); l > 0 {
-		var defaultImport string
-		var namespaceImport string
-		var namedImports []string
-
-		for _, spec := range i.Specifiers {
-			switch v := spec.(type) {
-			case *ImportDefaultSpecifier:
-				defaultImport = v.String()
-			case *ImportNamespaceSpecifier:
-				namespaceImport = v.String()
-			case *ImportSpecifier:
-				named := jsElementsToString(v.NamedImports)
-				namedImports = append(namedImports, named...)
-			}
-		}
-
-		if defaultImport != "" {
-			s += defaultImport
-		}
-
-		if namespaceImport != "" {
-			if defaultImport != "" {
-				s += ", "
-			}
-			s += namespaceImport
-		}
-
-		if len(namedImports) > 0 {
-			named := strings.Join(namedImports, ", ")
-			if defaultImport != "" || namespaceImport != "" {
-				s += ", "
-			}
-			s += "{ " + named + " }"
-		}
-		s += " from "
-	}
-	b, _ := json.Marshal(i.Source)
-	s += string(b) + ";"
-	return
+func (i *ImportDeclaration) String() string {
+	return sprint(i)
 }
 
 type VariableDeclaration struct {
@@ -1094,13 +889,8 @@ type VariableDeclaration struct {
 	*Node
 }
 
-func (v *VariableDeclaration) String() (s string) {
-	decl := make([]string, len(v.Declarations))
-	for n, x := range v.Declarations {
-		decl[n] = x.ID.String() + " = " + x.Init.String()
-	}
-	s = string(v.Kind) + " " + strings.Join(decl, ",")
-	return
+func (v *VariableDeclaration) String() string {
+	return sprint(v)
 }
 
 type VariableDeclarationType string
@@ -1116,19 +906,18 @@ type VariableDeclarator struct {
 	Init Expression
 }
 
+func (v VariableDeclarator) String() string {
+	return sprint(&v)
+}
+
 // Statements
 type BreakStatement struct {
 	Label *Identifier
 	*Node
 }
 
-func (b *BreakStatement) String() (s string) {
-	s = "break"
-	if b.Label != nil {
-		s += " " + b.Label.String()
-	}
-	s += ";"
-	return
+func (b *BreakStatement) String() string {
+	return sprint(b)
 }
 
 type ContinueStatement struct {
@@ -1136,13 +925,8 @@ type ContinueStatement struct {
 	*Node
 }
 
-func (c *ContinueStatement) String() (s string) {
-	s = "continue"
-	if c.Label != nil {
-		s += " " + c.Label.String()
-	}
-	s += ";"
-	return
+func (c *ContinueStatement) String() string {
+	return sprint(c)
 }
 
 type DebuggerStatement struct {
@@ -1150,7 +934,7 @@ type DebuggerStatement struct {
 }
 
 func (d *DebuggerStatement) String() string {
-	return "debugger;"
+	return sprint(d)
 }
 
 type DoWhileStatement struct {
@@ -1160,7 +944,7 @@ type DoWhileStatement struct {
 }
 
 func (d *DoWhileStatement) String() string {
-	return "do {\n" + indentor.Indent(d.Body.String()) + "\n} while(" + d.Test.String() + ");"
+	return sprint(d)
 }
 
 type EmptyStatement struct {
@@ -1168,7 +952,7 @@ type EmptyStatement struct {
 }
 
 func (e *EmptyStatement) String() string {
-	return ""
+	return sprint(e)
 }
 
 type ExpressionStatement struct {
@@ -1177,7 +961,7 @@ type ExpressionStatement struct {
 }
 
 func (e *ExpressionStatement) String() string {
-	return e.Expression.String() + ";"
+	return sprint(e)
 }
 
 type Directive struct {
@@ -1187,11 +971,11 @@ type Directive struct {
 }
 
 func (d *Directive) String() string {
-	panic("Not Supported")
+	return sprint(d)
 }
 
 type ForStatement struct {
-	Init   Expression
+	Init   ForInit
 	Test   Expression
 	Update Expression
 	Body   BlockStatement
@@ -1199,11 +983,11 @@ type ForStatement struct {
 }
 
 func (f *ForStatement) String() string {
-	return "for(" + f.Init.String() + ", " + f.Test.String() + ", " + f.Update.String() + "){\n" + indentor.Indent(f.Body.String()) + "\n}"
+	return sprint(f)
 }
 
 type ForInStatement struct {
-	Left  Expression
+	Left  ForInit
 	Right Expression
 	Body  BlockStatement
 	Each  bool
@@ -1211,24 +995,19 @@ type ForInStatement struct {
 }
 
 func (f *ForInStatement) String() string {
-	return "for(" + f.Left.String() + " in " + f.Right.String() + "){\n" + indentor.Indent(f.Body.String()) + "\n}"
+	return sprint(f)
 }
 
 type ForOfStatement struct {
 	Await bool
-	Left  Expression
+	Left  ForInit
 	Right Expression
 	Body  BlockStatement
 	*Node
 }
 
-func (f *ForOfStatement) String() (s string) {
-	s = "for"
-	if f.Await {
-		s += " await"
-	}
-	s += "(" + f.Left.String() + " in " + f.Right.String() + "){\n" + indentor.Indent(f.Body.String()) + "\n}"
-	return
+func (f *ForOfStatement) String() string {
+	return sprint(f)
 }
 
 type IfStatement struct {
@@ -1238,20 +1017,8 @@ type IfStatement struct {
 	*Node
 }
 
-func (f *IfStatement) String() (s string) {
-	s = "if (" + f.Test.String() + ") {\n" + indentor.Indent(f.Consequent.String()) + "\n}"
-	if f.Alternate != nil {
-		s += " else "
-		var isIf bool
-		if _, isIf := f.Alternate.(*IfStatement); !isIf {
-			s += "{\n"
-		}
-		s += indentor.Indent(f.Alternate.String())
-		if !isIf {
-			s += "\n}"
-		}
-	}
-	return s
+func (f *IfStatement) String() string {
+	return sprint(f)
 }
 
 type ReturnStatement struct {
@@ -1259,13 +1026,8 @@ type ReturnStatement struct {
 	*Node
 }
 
-func (r *ReturnStatement) String() (s string) {
-	s = "return"
-	if r.Argument != nil {
-		s += " " + r.Argument.String()
-	}
-	s += ";"
-	return
+func (r *ReturnStatement) String() string {
+	return sprint(r)
 }
 
 type SwitchStatement struct {
@@ -1275,8 +1037,7 @@ type SwitchStatement struct {
 }
 
 func (r *SwitchStatement) String() string {
-	cases := jsElementsToString(r.Cases)
-	return "switch {\n" + indentor.Indent(strings.Join(cases, "\n")) + "\n}"
+	return sprint(r)
 }
 
 type ThrowStatement struct {
@@ -1285,7 +1046,7 @@ type ThrowStatement struct {
 }
 
 func (t *ThrowStatement) String() string {
-	return "throw " + t.Argument.String() + ";"
+	return sprint(t)
 }
 
 type TryStatement struct {
@@ -1295,12 +1056,15 @@ type TryStatement struct {
 	*Node
 }
 
-func (t *TryStatement) String() (s string) {
-	s = "try {\n" + indentor.Indent(t.Block.String()) + "\n} " + t.Handler.String()
-	if t.Finalizer != nil {
-		s += " finally {\n" + indentor.Indent(t.Finalizer.String()) + "\n}"
-	}
-	return
+func (t *TryStatement) String() string {
+	return sprint(t)
+}
+
+// HasHandler reports whether the statement has a catch clause. A zero
+// Handler means the statement only has a finally block.
+func (t *TryStatement) HasHandler() bool {
+	h := t.Handler
+	return h.BindingIdentifierOrPattern != nil || len(h.Body.Items) > 0 || h.Node != nil || h.Body.Node != nil
 }
 
 type WhileStatement struct {
@@ -1310,7 +1074,7 @@ type WhileStatement struct {
 }
 
 func (w *WhileStatement) String() string {
-	return "while (" + w.Test.String() + ") {\n" + indentor.Indent(w.Body.String()) + "\n}"
+	return sprint(w)
 }
 
 type WithStatement struct {
@@ -1320,7 +1084,7 @@ type WithStatement struct {
 }
 
 func (w *WithStatement) String() string {
-	return "with (" + w.Object.String() + ") {\n" + indentor.Indent(w.Body.String()) + "\n}"
+	return sprint(w)
 }
 
 // Imports
@@ -1330,7 +1094,7 @@ type ImportDefaultSpecifier struct {
 }
 
 func (i *ImportDefaultSpecifier) String() string {
-	return i.Local.String()
+	return sprint(i)
 }
 
 type ImportNamespaceSpecifier struct {
@@ -1339,7 +1103,7 @@ type ImportNamespaceSpecifier struct {
 }
 
 func (i *ImportNamespaceSpecifier) String() string {
-	return "* as " + i.Local.String()
+	return sprint(i)
 }
 
 type ImportSpecifier struct {
@@ -1347,38 +1111,20 @@ type ImportSpecifier struct {
 	*Node
 }
 
-func (i ImportSpecifier) String() (s string) {
-	if len(i.NamedImports) == 0 {
-		return "{}"
-	}
-
-	return "{ " + strings.Join(i.namedImports(), ", ") + " }"
-}
-
-func (i *ImportSpecifier) namedImports() []string {
-	named := make([]string, len(i.NamedImports))
-	for n, ni := range i.NamedImports {
-		out := ni.Imported.Name
-		if ni.Local != nil {
-			out += " as " + ni.Local.Name
-		}
-		named[n] = out
-	}
-	return named
+func (i ImportSpecifier) String() string {
+	return sprint(&i)
 }
 
+// NamedImport imports the export Imported under the local name Local.
+// Local may be omitted when both names are the same.
 type NamedImport struct {
 	Local    *Identifier
 	Imported *Identifier
 	*Node
 }
 
-func (n NamedImport) String() (s string) {
-	s = n.Imported.Name
-	if n.Local != nil {
-		s += " as " + n.Local.Name
-	}
-	return
+func (n NamedImport) String() string {
+	return sprint(&n)
 }
 
 type LabeledStatement struct {
@@ -1387,12 +1133,21 @@ type LabeledStatement struct {
 	*Node
 }
 
+func (l *LabeledStatement) String() string {
+	return sprint(l)
+}
+
+// MetaProperty is new.target or import.meta
 type MetaProperty struct {
 	Meta     Identifier
 	Property Identifier
 	*Node
 }
 
+func (m *MetaProperty) String() string {
+	return sprint(m)
+}
+
 // misc
 type RestElement struct {
 	Argument BindingIdentifierOrPattern
@@ -1400,7 +1155,7 @@ type RestElement struct {
 }
 
 func (r *RestElement) String() string {
-	return "..." + r.Argument.String()
+	return sprint(r)
 }
 
 type SpreadElement struct {
@@ -1409,13 +1164,25 @@ type SpreadElement struct {
 }
 
 func (s *SpreadElement) String() string {
-	return "..." + s.Argument.String()
+	return sprint(s)
 }
 
 type Super struct {
 	*Node
 }
 
+func (s *Super) String() string {
+	return sprint(s)
+}
+
+type ThisExpression struct {
+	*Node
+}
+
+func (t *ThisExpression) String() string {
+	return sprint(t)
+}
+
 // Type safety
 
 // ArgumentListElements
@@ -1438,6 +1205,11 @@ func (s *ObjectExpression) argumentListElement()         {}
 func (s *SequenceExpression) argumentListElement()       {}
 func (s *StaticMemberExpression) argumentListElement()   {}
 func (s *TaggedTemplateExpression) argumentListElement() {}
+func (s *TemplateLiteral) argumentListElement()          {}
+func (n *ThisExpression) argumentListElement()           {}
+func (n *Super) argumentListElement()                    {}
+func (n *MetaProperty) argumentListElement()             {}
+func (n *Import) argumentListElement()                   {}
 func (s *UnaryExpression) argumentListElement()          {}
 func (s *UpdateExpression) argumentListElement()         {}
 func (s *YieldExpression) argumentListElement()          {}
@@ -1468,6 +1240,11 @@ func (s *ObjectExpression) arrayExpressionElement()         {}
 func (s *SequenceExpression) arrayExpressionElement()       {}
 func (s *StaticMemberExpression) arrayExpressionElement()   {}
 func (s *TaggedTemplateExpression) arrayExpressionElement() {}
+func (s *TemplateLiteral) arrayExpressionElement()          {}
+func (n *ThisExpression) arrayExpressionElement()           {}
+func (n *Super) arrayExpressionElement()                    {}
+func (n *MetaProperty) arrayExpressionElement()             {}
+func (n *Import) arrayExpressionElement()                   {}
 func (s *UnaryExpression) arrayExpressionElement()          {}
 func (s *UpdateExpression) arrayExpressionElement()         {}
 func (s *YieldExpression) arrayExpressionElement()          {}
@@ -1512,6 +1289,11 @@ func (n *ObjectExpression) expression()         {}
 func (n *SequenceExpression) expression()       {}
 func (n *StaticMemberExpression) expression()   {}
 func (n *TaggedTemplateExpression) expression() {}
+func (n *TemplateLiteral) expression()          {}
+func (n *ThisExpression) expression()           {}
+func (n *Super) expression()                    {}
+func (n *MetaProperty) expression()             {}
+func (n *Import) expression()                   {}
 func (n *UnaryExpression) expression()          {}
 func (n *UpdateExpression) expression()         {}
 func (n *YieldExpression) expression()          {}
@@ -1532,6 +1314,10 @@ func (s *ExportDefaultDeclaration) declaration() {}
 func (s *ExportNamedDeclaration) declaration()   {}
 
 // Statements
+func (s *ClassDeclaration) statement()    {}
+func (s *FunctionDeclaration) statement() {}
+func (s *VariableDeclaration) statement() {}
+func (s *LabeledStatement) statement()    {}
 func (s *BreakStatement) statement()      {}
 func (s *ContinueStatement) statement()   {}
 func (s *DebuggerStatement) statement()   {}
@@ -1574,6 +1360,7 @@ func (s *TryStatement) statementListItem()             {}
 func (s *WhileStatement) statementListItem()           {}
 func (s *WithStatement) statementListItem()            {}
 func (s *BlockStatement) statementListItem()           {}
+func (s *LabeledStatement) statementListItem()         {}
 func (s *ExportAllDeclaration) statementListItem()     {}
 func (s *ExportDefaultDeclaration) statementListItem() {}
 func (s *ExportNamedDeclaration) statementListItem()   {}
@@ -1586,15 +1373,9 @@ func (s *ObjectPattern) arrayPatternElement()     {}
 func (s *RestElement) arrayPatternElement()       {}
 
 // ChainElements
-func (s *CallExpression) chainElementToString() string {
-	return s.Callee.String() + "?." + s.argsToString()
-}
-func (s *ComputedMemberExpression) chainElementToString() string {
-	return s.Object.String() + "?.[" + s.Property.String() + "]"
-}
-func (s *StaticMemberExpression) chainElementToString() string {
-	return s.Object.String() + "?." + s.propertyToString()
-}
+func (s *CallExpression) chainElement()           {}
+func (s *ComputedMemberExpression) chainElement() {}
+func (s *StaticMemberExpression) chainElement()   {}
 
 // ExportableDefaultDeclarations
 func (s *Identifier) exportableDefaultDeclaration()               {}
@@ -1619,6 +1400,11 @@ func (n *ObjectExpression) exportableDefaultDeclaration()         {}
 func (n *SequenceExpression) exportableDefaultDeclaration()       {}
 func (n *StaticMemberExpression) exportableDefaultDeclaration()   {}
 func (n *TaggedTemplateExpression) exportableDefaultDeclaration() {}
+func (n *TemplateLiteral) exportableDefaultDeclaration()          {}
+func (n *ThisExpression) exportableDefaultDeclaration()           {}
+func (n *Super) exportableDefaultDeclaration()                    {}
+func (n *MetaProperty) exportableDefaultDeclaration()             {}
+func (n *Import) exportableDefaultDeclaration()                   {}
 func (n *UnaryExpression) exportableDefaultDeclaration()          {}
 func (n *UpdateExpression) exportableDefaultDeclaration()         {}
 func (n *YieldExpression) exportableDefaultDeclaration()          {}
@@ -1635,9 +1421,11 @@ func (n *FunctionDeclaration) exportableNamedDeclaration() {}
 func (n *VariableDeclaration) exportableNamedDeclaration() {}
 
 // FunctionParameters
-func (s *ArrayPattern) functionParameter()  {}
-func (s *ObjectPattern) functionParameter() {}
-func (s *Identifier) functionParameter()    {}
+func (s *AssignmentPattern) functionParameter() {}
+func (s *RestElement) functionParameter()       {}
+func (s *ArrayPattern) functionParameter()      {}
+func (s *ObjectPattern) functionParameter()     {}
+func (s *Identifier) functionParameter()        {}
 
 // ImportDeclarationSpecifiers
 func (s *ImportDefaultSpecifier) importDeclarationSpecifier()   {}
@@ -1653,13 +1441,38 @@ func (s *PropertyPattern) objectPatternProperty() {}
 func (s *RestElement) objectPatternProperty()     {}
 
 // PropertyKeys
-func (s *Identifier) propertyKey()            {}
-func (s *literalValueUndefined) propertyKey() {}
-func (s *literalValueNull) propertyKey()      {}
-func (s *LiteralValueString) propertyKey()    {}
-func (s *LiteralValueBool) propertyKey()      {}
-func (s *LiteralValueNumber) propertyKey()    {}
-func (s *LiteralValueBigFloat) propertyKey()  {}
+func (s *Identifier) propertyKey()               {}
+func (s *literalValueUndefined) propertyKey()    {}
+func (s *literalValueNull) propertyKey()         {}
+func (s *LiteralValueString) propertyKey()       {}
+func (s *LiteralValueBool) propertyKey()         {}
+func (s *LiteralValueNumber) propertyKey()       {}
+func (s *LiteralValueBigFloat) propertyKey()     {}
+func (n *ArrayExpression) propertyKey()          {}
+func (n *ArrowFunctionExpression) propertyKey()  {}
+func (n *AssignmentExpression) propertyKey()     {}
+func (n *AwaitExpression) propertyKey()          {}
+func (n *BinaryExpression) propertyKey()         {}
+func (n *LogicalExpression) propertyKey()        {}
+func (n *CallExpression) propertyKey()           {}
+func (n *ChainExpression) propertyKey()          {}
+func (n *ClassExpression) propertyKey()          {}
+func (n *ComputedMemberExpression) propertyKey() {}
+func (n *ConditionalExpression) propertyKey()    {}
+func (n *FunctionExpression) propertyKey()       {}
+func (n *NewExpression) propertyKey()            {}
+func (n *ObjectExpression) propertyKey()         {}
+func (n *SequenceExpression) propertyKey()       {}
+func (n *StaticMemberExpression) propertyKey()   {}
+func (n *TaggedTemplateExpression) propertyKey() {}
+func (n *TemplateLiteral) propertyKey()          {}
+func (n *UnaryExpression) propertyKey()          {}
+func (n *UpdateExpression) propertyKey()         {}
+func (n *YieldExpression) propertyKey()          {}
+func (n *ThisExpression) propertyKey()           {}
+func (n *Super) propertyKey()                    {}
+func (n *MetaProperty) propertyKey()             {}
+func (n *Import) propertyKey()                   {}
 
 // PropertyValues
 func (s *Identifier) propertyValue()         {}
@@ -1687,6 +1500,11 @@ func (n *ObjectExpression) expressionOrImport()         {}
 func (n *SequenceExpression) expressionOrImport()       {}
 func (n *StaticMemberExpression) expressionOrImport()   {}
 func (n *TaggedTemplateExpression) expressionOrImport() {}
+func (n *TemplateLiteral) expressionOrImport()          {}
+func (n *ThisExpression) expressionOrImport()           {}
+func (n *Super) expressionOrImport()                    {}
+func (n *MetaProperty) expressionOrImport()             {}
+func (n *Import) expressionOrImport()                   {}
 func (n *UnaryExpression) expressionOrImport()          {}
 func (n *UpdateExpression) expressionOrImport()         {}
 func (n *YieldExpression) expressionOrImport()          {}
@@ -1697,6 +1515,41 @@ func (s *LiteralValueBool) expressionOrImport()         {}
 func (s *LiteralValueNumber) expressionOrImport()       {}
 func (s *LiteralValueBigFloat) expressionOrImport()     {}
 
+// ForInits
+func (n *Identifier) forInit()               {}
+func (n *ArrayExpression) forInit()          {}
+func (n *ArrowFunctionExpression) forInit()  {}
+func (n *AssignmentExpression) forInit()     {}
+func (n *AwaitExpression) forInit()          {}
+func (n *BinaryExpression) forInit()         {}
+func (n *LogicalExpression) forInit()        {}
+func (n *CallExpression) forInit()           {}
+func (n *ChainExpression) forInit()          {}
+func (n *ClassExpression) forInit()          {}
+func (n *ComputedMemberExpression) forInit() {}
+func (n *ConditionalExpression) forInit()    {}
+func (n *FunctionExpression) forInit()       {}
+func (n *NewExpression) forInit()            {}
+func (n *ObjectExpression) forInit()         {}
+func (n *SequenceExpression) forInit()       {}
+func (n *StaticMemberExpression) forInit()   {}
+func (n *TaggedTemplateExpression) forInit() {}
+func (n *TemplateLiteral) forInit()          {}
+func (n *UnaryExpression) forInit()          {}
+func (n *UpdateExpression) forInit()         {}
+func (n *YieldExpression) forInit()          {}
+func (n *literalValueUndefined) forInit()    {}
+func (n *literalValueNull) forInit()         {}
+func (n *LiteralValueString) forInit()       {}
+func (n *LiteralValueBool) forInit()         {}
+func (n *LiteralValueNumber) forInit()       {}
+func (n *LiteralValueBigFloat) forInit()     {}
+func (n *ThisExpression) forInit()           {}
+func (n *Super) forInit()                    {}
+func (n *MetaProperty) forInit()             {}
+func (n *Import) forInit()                   {}
+func (n *VariableDeclaration) forInit()      {}
+
 // ExportDeclaration
 func (s *ExportAllDeclaration) exportDeclaration()     {}
 func (s *ExportDefaultDeclaration) exportDeclaration() {}
@@ -1705,24 +1558,3 @@ func (s *ExportNamedDeclaration) exportDeclaration()   {}
 // ClassProperty
 func (s *MethodDefinition) classProperty()   {}
 func (s *PropertyDefinition) classProperty() {}
-
-// Helpers
-func argListToString(args []ArgumentListElement) string {
-	out := jsElementsToString(args)
-	return strings.Join(out, ", ")
-}
-
-func objectExpressionPropertiesToString(props []ObjectExpressionProperty) (s string) {
-	pl := len(props)
-	result := jsElementsToString(props)
-	s = strings.Join(result, ",\n")
-	if pl > 0 {
-		s += ","
-	}
-	return
-}
-
-func functionParametersToString(params []FunctionParameter) string {
-	out := jsElementsToString(params)
-	return strings.Join(out, ", ")
-}
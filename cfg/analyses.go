@@ -0,0 +1,437 @@
+package cfg
+
+import (
+	"math"
+
+	esp "github.com/MichaelCombs28/goesprima"
+)
+
+// ReachingDefs is the result of ReachingDefinitions.
+type ReachingDefs struct {
+	// Defs and Uses of the function in evaluation order.
+	Defs  []*Def
+	Uses  []*Use
+	reach map[*esp.Identifier][]*Def
+}
+
+// Reaching returns the defs that may reach the use id, in evaluation
+// order, or nil if id isn't a use.
+func (r *ReachingDefs) Reaching(id *esp.Identifier) []*Def {
+	return r.reach[id]
+}
+
+// ReachingDefinitions computes which defs, such as assignments and
+// initializers, may reach every use of a variable in g.
+func ReachingDefinitions(g *CFG) *ReachingDefs {
+	f := newFlow(g)
+	byVar := make(map[int][]int)
+	for _, d := range f.defs {
+		byVar[d.v] = append(byVar[d.v], d.index)
+	}
+	step := func(in bits, ev event) bits {
+		if ev.def == nil {
+			return in
+		}
+		for _, i := range byVar[ev.def.v] {
+			in = in.without(i)
+		}
+		return in.with(ev.def.index)
+	}
+	res := Solve(g, Problem[bits]{
+		Direction: Forward,
+		Join:      bits.union,
+		Equal:     bits.equal,
+		Transfer: func(b *Block, in bits) bits {
+			for _, ev := range f.events[b.Index] {
+				in = step(in, ev)
+			}
+			return in
+		},
+	})
+
+	r := &ReachingDefs{Defs: f.defs, Uses: f.uses, reach: make(map[*esp.Identifier][]*Def)}
+	for _, b := range g.Blocks {
+		in := res.In[b.Index]
+		for _, ev := range f.events[b.Index] {
+			if ev.use != nil {
+				var defs []*Def
+				for _, i := range byVar[ev.use.v] {
+					if in.has(i) {
+						defs = append(defs, f.defs[i])
+					}
+				}
+				r.reach[ev.use.Ident] = defs
+			}
+			in = step(in, ev)
+		}
+	}
+	return r
+}
+
+// Liveness is the result of LiveVariables.
+type Liveness struct {
+	f   *flow
+	res Result[bits]
+}
+
+// LiveVariables computes the variables whose current value may be read
+// later, at the start and end of every block of g.
+func LiveVariables(g *CFG) *Liveness {
+	f := newFlow(g)
+	res := Solve(g, Problem[bits]{
+		Direction: Backward,
+		Join:      bits.union,
+		Equal:     bits.equal,
+		Transfer: func(b *Block, out bits) bits {
+			events := f.events[b.Index]
+			for i := len(events) - 1; i >= 0; i-- {
+				out = liveStep(out, events[i])
+			}
+			return out
+		},
+	})
+	return &Liveness{f: f, res: res}
+}
+
+func liveStep(out bits, ev event) bits {
+	if ev.def != nil {
+		return out.without(ev.def.v)
+	}
+	return out.with(ev.use.v)
+}
+
+// LiveIn returns the names of the variables live at the start of b.
+func (l *Liveness) LiveIn(b *Block) []string {
+	return l.names(l.res.In[b.Index])
+}
+
+// LiveOut returns the names of the variables live at the end of b.
+func (l *Liveness) LiveOut(b *Block) []string {
+	return l.names(l.res.Out[b.Index])
+}
+
+func (l *Liveness) names(s bits) (out []string) {
+	for i, name := range l.f.names {
+		if s.has(i) {
+			out = append(out, name)
+		}
+	}
+	return
+}
+
+// DeadStores returns the defs whose value is never read, in evaluation
+// order. Variables declared outside the function, at the top level of a
+// module or referenced by nested functions are never dead, as they may be
+// read elsewhere.
+func (l *Liveness) DeadStores() (out []*Def) {
+	dead := make(map[*Def]bool)
+	for _, b := range l.f.g.Blocks {
+		live := l.res.Out[b.Index]
+		events := l.f.events[b.Index]
+		for i := len(events) - 1; i >= 0; i-- {
+			if d := events[i].def; d != nil && !live.has(d.v) && local(d.Variable) {
+				dead[d] = true
+			}
+			live = liveStep(live, events[i])
+		}
+	}
+	for _, d := range l.f.defs {
+		if dead[d] {
+			out = append(out, d)
+		}
+	}
+	return
+}
+
+// local tells whether v is only referenced by the function declaring it.
+func local(v *esp.Variable) bool {
+	if v == nil || v.Scope.VariableScope.Type != esp.ScopeFunction {
+		return false
+	}
+	for _, r := range v.References {
+		if r.From.VariableScope != v.Scope.VariableScope {
+			return false
+		}
+	}
+	return true
+}
+
+// Constants is the result of ConstantPropagation.
+type Constants struct {
+	values map[*esp.Identifier]esp.Literal
+}
+
+// Value returns the constant value of the use id, if it has one.
+func (c *Constants) Value(id *esp.Identifier) (esp.Literal, bool) {
+	l, ok := c.values[id]
+	return l, ok
+}
+
+// constFact maps variables to their constant value. Variables missing
+// from a reached fact aren't constant.
+type constFact struct {
+	reached bool
+	vals    map[int]esp.Literal
+}
+
+// ConstantPropagation computes the uses of g whose value is the same
+// primitive on every path. Literals, undefined and the unary, binary,
+// logical and conditional operators on them are folded; variables written
+// by nested functions are never constant.
+func ConstantPropagation(g *CFG) *Constants {
+	f := newFlow(g)
+	escaped := make(map[int]bool)
+	for key, i := range f.vars {
+		if v, ok := key.(*esp.Variable); ok {
+			for _, r := range v.References {
+				if r.IsWrite() && r.From.VariableScope != v.Scope.VariableScope {
+					escaped[i] = true
+				}
+			}
+		}
+	}
+	lookup := func(fact constFact) func(*esp.Identifier) (esp.Literal, bool) {
+		return func(id *esp.Identifier) (esp.Literal, bool) {
+			key := interface{}(id.Name)
+			if v := f.scopes.VariableOf(id); v != nil {
+				key = v
+			} else if id.Name == "undefined" {
+				return esp.LiteralValueUndefined, true
+			}
+			i, ok := f.vars[key]
+			if !ok {
+				return nil, false
+			}
+			l, ok := fact.vals[i]
+			return l, ok
+		}
+	}
+	step := func(in constFact, ev event) constFact {
+		d := ev.def
+		if d == nil {
+			return in
+		}
+		var value esp.Literal
+		var ok bool
+		if d.Value != nil && !escaped[d.v] {
+			value, ok = evalConst(d.Value, lookup(in))
+		}
+		out := constFact{reached: true, vals: make(map[int]esp.Literal, len(in.vals)+1)}
+		for k, v := range in.vals {
+			out.vals[k] = v
+		}
+		if ok {
+			out.vals[d.v] = value
+		} else {
+			delete(out.vals, d.v)
+		}
+		return out
+	}
+	res := Solve(g, Problem[constFact]{
+		Direction: Forward,
+		Boundary:  constFact{reached: true},
+		Join: func(a, b constFact) constFact {
+			if !a.reached {
+				return b
+			}
+			if !b.reached {
+				return a
+			}
+			out := constFact{reached: true, vals: make(map[int]esp.Literal)}
+			for k, v := range a.vals {
+				if w, ok := b.vals[k]; ok && strictEqual(v, w) {
+					out.vals[k] = v
+				}
+			}
+			return out
+		},
+		Equal: func(a, b constFact) bool {
+			if a.reached != b.reached || len(a.vals) != len(b.vals) {
+				return false
+			}
+			for k, v := range a.vals {
+				if w, ok := b.vals[k]; !ok || !strictEqual(v, w) {
+					return false
+				}
+			}
+			return true
+		},
+		Transfer: func(b *Block, in constFact) constFact {
+			for _, ev := range f.events[b.Index] {
+				in = step(in, ev)
+			}
+			return in
+		},
+	})
+
+	c := &Constants{values: make(map[*esp.Identifier]esp.Literal)}
+	for _, b := range g.Blocks {
+		in := res.In[b.Index]
+		for _, ev := range f.events[b.Index] {
+			if u := ev.use; u != nil && in.reached {
+				if l, ok := in.vals[u.v]; ok && !escaped[u.v] {
+					c.values[u.Ident] = l
+				}
+			}
+			in = step(in, ev)
+		}
+	}
+	return c
+}
+
+// evalConst folds e to a primitive, looking up identifiers with lookup.
+func evalConst(e esp.Expression, lookup func(*esp.Identifier) (esp.Literal, bool)) (esp.Literal, bool) {
+	switch e := e.(type) {
+	case *esp.LiteralValueNumber, *esp.LiteralValueString, *esp.LiteralValueBool:
+		return e.(esp.Literal), true
+	case *esp.Identifier:
+		return lookup(e)
+	case *esp.UnaryExpression:
+		arg, ok := evalConst(e.Argument, lookup)
+		if !ok {
+			return nil, false
+		}
+		switch e.Operator {
+		case esp.UnaryOperatorTypeNot:
+			return esp.BoolLiteral(!truthy(arg)), true
+		case esp.UnaryOperatorTypeVoid:
+			return esp.LiteralValueUndefined, true
+		case esp.UnaryOperatorTypeTypeof:
+			return esp.StringLiteral(typeOf(arg)), true
+		case esp.UnaryOperatorTypeMinus, esp.UnaryOperatorTypePlus:
+			n, ok := arg.(*esp.LiteralValueNumber)
+			if !ok {
+				return nil, false
+			}
+			if e.Operator == esp.UnaryOperatorTypeMinus {
+				return esp.NumberLiteral(-float64(*n)), true
+			}
+			return n, true
+		}
+	case *esp.BinaryExpression:
+		l, ok := evalConst(e.Left, lookup)
+		if !ok {
+			return nil, false
+		}
+		r, ok := evalConst(e.Right, lookup)
+		if !ok {
+			return nil, false
+		}
+		switch e.Operator {
+		case esp.BinaryOperatorStrictEqual:
+			return esp.BoolLiteral(strictEqual(l, r)), true
+		case esp.BinaryOperatorStrictNotEqual:
+			return esp.BoolLiteral(!strictEqual(l, r)), true
+		}
+		if ls, ok := l.(*esp.LiteralValueString); ok && e.Operator == esp.BinaryOperatorADD {
+			if rs, ok := r.(*esp.LiteralValueString); ok {
+				return esp.StringLiteral(string(*ls) + string(*rs)), true
+			}
+			return nil, false
+		}
+		ln, lok := l.(*esp.LiteralValueNumber)
+		rn, rok := r.(*esp.LiteralValueNumber)
+		if !lok || !rok {
+			return nil, false
+		}
+		x, y := float64(*ln), float64(*rn)
+		switch e.Operator {
+		case esp.BinaryOperatorADD:
+			return esp.NumberLiteral(x + y), true
+		case esp.BinaryOperatorMinus:
+			return esp.NumberLiteral(x - y), true
+		case esp.BinaryOperatorMultiply:
+			return esp.NumberLiteral(x * y), true
+		case esp.BinaryOperatorDivide:
+			return esp.NumberLiteral(x / y), true
+		case esp.BinaryOperatorModulus:
+			return esp.NumberLiteral(math.Mod(x, y)), true
+		case esp.BinaryOperatorExponent:
+			return esp.NumberLiteral(math.Pow(x, y)), true
+		case esp.BinaryOperatorLess:
+			return esp.BoolLiteral(x < y), true
+		case esp.BinaryOperatorLessEqual:
+			return esp.BoolLiteral(x <= y), true
+		case esp.BinaryOperatorGreater:
+			return esp.BoolLiteral(x > y), true
+		case esp.BinaryOperatorGreaterEqual:
+			return esp.BoolLiteral(x >= y), true
+		}
+	case *esp.LogicalExpression:
+		l, ok := evalConst(e.Left, lookup)
+		if !ok {
+			return nil, false
+		}
+		switch e.Operator {
+		case esp.LogicalOperatorAnd:
+			if !truthy(l) {
+				return l, true
+			}
+		case esp.LogicalOperatorOr:
+			if truthy(l) {
+				return l, true
+			}
+		case esp.LogicalOperatorNullishCoelescing:
+			if l != esp.LiteralValueNull && l != esp.LiteralValueUndefined {
+				return l, true
+			}
+		}
+		return evalConst(e.Right, lookup)
+	case *esp.ConditionalExpression:
+		test, ok := evalConst(e.Test, lookup)
+		if !ok {
+			return nil, false
+		}
+		if truthy(test) {
+			return evalConst(e.Consequent, lookup)
+		}
+		return evalConst(e.Alternate, lookup)
+	default:
+		if e == esp.LiteralValueNull || e == esp.LiteralValueUndefined {
+			return e.(esp.Literal), true
+		}
+	}
+	return nil, false
+}
+
+func truthy(l esp.Literal) bool {
+	switch l := l.(type) {
+	case *esp.LiteralValueBool:
+		return bool(*l)
+	case *esp.LiteralValueNumber:
+		return *l != 0 && !math.IsNaN(float64(*l))
+	case *esp.LiteralValueString:
+		return *l != ""
+	}
+	return false
+}
+
+func typeOf(l esp.Literal) string {
+	switch l.(type) {
+	case *esp.LiteralValueBool:
+		return "boolean"
+	case *esp.LiteralValueNumber:
+		return "number"
+	case *esp.LiteralValueString:
+		return "string"
+	}
+	if l == esp.LiteralValueNull {
+		return "object"
+	}
+	return "undefined"
+}
+
+func strictEqual(a, b esp.Literal) bool {
+	switch a := a.(type) {
+	case *esp.LiteralValueBool:
+		b, ok := b.(*esp.LiteralValueBool)
+		return ok && *a == *b
+	case *esp.LiteralValueNumber:
+		b, ok := b.(*esp.LiteralValueNumber)
+		return ok && *a == *b
+	case *esp.LiteralValueString:
+		b, ok := b.(*esp.LiteralValueString)
+		return ok && *a == *b
+	}
+	return a == b
+}
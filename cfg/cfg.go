@@ -0,0 +1,764 @@
+// Package cfg builds control flow graphs of JavaScript functions.
+//
+// A CFG is made of basic blocks holding the statements and expressions
+// evaluated in sequence, connected by edges labelled with the reason
+// control flows along them. Conditions of if statements, loops and
+// ConditionalExpression, and both sides of LogicalExpression, are split
+// into their own blocks so that short-circuit evaluation is explicit.
+// Nested functions are not part of the graph of the enclosing function.
+package cfg
+
+import (
+	"fmt"
+
+	esp "github.com/MichaelCombs28/goesprima"
+)
+
+// EdgeKind tells why control flows along an edge.
+type EdgeKind string
+
+const (
+	EdgeNormal EdgeKind = "normal"
+	// EdgeTrue and EdgeFalse leave a block ending in a condition. For ??,
+	// EdgeTrue is taken when the left operand is nullish.
+	EdgeTrue  EdgeKind = "true"
+	EdgeFalse EdgeKind = "false"
+	// EdgeFallthrough falls from a switch case into the next one.
+	EdgeFallthrough EdgeKind = "fallthrough"
+	EdgeBreak       EdgeKind = "break"
+	EdgeContinue    EdgeKind = "continue"
+	EdgeReturn      EdgeKind = "return"
+	EdgeThrow       EdgeKind = "throw"
+	// EdgeException leaves a block of a try statement for its handler or
+	// finalizer, as any of its statements may throw.
+	EdgeException EdgeKind = "exception"
+)
+
+// A CFG is the control flow graph of a function or program.
+type CFG struct {
+	// Func is the function or program the graph was built for.
+	Func esp.JSElement
+	// Blocks in order of creation. Blocks[0] is the entry and Blocks[1]
+	// the exit.
+	Blocks []*Block
+}
+
+// A Block is a basic block.
+type Block struct {
+	Index int
+	// Kind describes the role of the block, eg. "if.then" or "for.loop".
+	Kind string
+	// Nodes evaluated in order: statements, and the conditions and
+	// operands split out of them.
+	Nodes []esp.JSElement
+	// Stmt is the statement that created the block, if any.
+	Stmt  esp.JSElement
+	Succs []*Edge
+	Preds []*Edge
+	// Live is set for blocks reachable from the entry.
+	Live bool
+}
+
+// An Edge connects two blocks.
+type Edge struct {
+	Kind     EdgeKind
+	From, To *Block
+}
+
+func (b *Block) String() string {
+	return fmt.Sprintf("block %d (%s)", b.Index, b.Kind)
+}
+
+// Entry returns the entry block.
+func (g *CFG) Entry() *Block { return g.Blocks[0] }
+
+// Exit returns the exit block, reached by returns, throws and falling off
+// the end of the function.
+func (g *CFG) Exit() *Block { return g.Blocks[1] }
+
+// New builds the graph of fn, which is a *Program, *Generator, function,
+// arrow function or *MethodDefinition. Parameters are the first nodes of
+// the entry block.
+func New(fn esp.JSElement) *CFG {
+	b := &builder{g: &CFG{Func: fn}}
+	entry := b.newBlock("entry", nil)
+	entry.Live = true
+	exit := b.newBlock("exit", nil)
+	b.cur = entry
+
+	switch f := fn.(type) {
+	case *esp.Program:
+		b.stmts(f.Body)
+	case *esp.Generator:
+		b.stmts(f.Statements)
+	case *esp.FunctionDeclaration:
+		b.params(f.Params)
+		b.stmts(f.Body.Items)
+	case *esp.FunctionExpression:
+		b.params(f.Params)
+		b.stmts(f.Body.Items)
+	case *esp.MethodDefinition:
+		b.params(f.Value.Params)
+		b.stmts(f.Value.Body.Items)
+	case *esp.ArrowFunctionExpression:
+		b.params(f.Params)
+		if f.Expression != nil {
+			b.expr(f.Expression)
+			b.add(f.Expression)
+			b.edge(b.cur, exit, EdgeReturn)
+			b.cur = nil
+		} else {
+			b.stmts(f.Body.Items)
+		}
+	default:
+		panic(fmt.Sprintf("cfg.New: unexpected node type %T", fn))
+	}
+	b.edge(b.cur, exit, EdgeNormal)
+	return b.g
+}
+
+// All builds the graphs of root and of every function nested in it, in
+// depth-first order.
+func All(root esp.JSElement) (out []*CFG) {
+	esp.Inspect(root, func(n esp.JSElement) bool {
+		switch n.(type) {
+		case *esp.Program, *esp.Generator, *esp.FunctionDeclaration,
+			*esp.FunctionExpression, *esp.ArrowFunctionExpression:
+			out = append(out, New(n))
+		}
+		return true
+	})
+	return
+}
+
+type builder struct {
+	g *CFG
+	// cur is the block being built, nil after a jump until the next
+	// statement, which then starts an unreachable block.
+	cur     *Block
+	targets *targetFrame
+	tries   *tryFrame
+	labels  []string
+	seq     int
+}
+
+// targetFrame holds the targets of break and continue statements.
+type targetFrame struct {
+	tail   *targetFrame
+	seq    int
+	labels []string
+	brk    *Block
+	// cont is nil for switch and labeled statements.
+	cont *Block
+	// loose is set for statements targeted by unlabeled breaks.
+	loose bool
+}
+
+// tryFrame is a try statement being built.
+type tryFrame struct {
+	tail    *tryFrame
+	seq     int
+	handler *Block
+	finally *Block
+	// pending are the jumps through finally, resumed after it.
+	pending []pending
+}
+
+type pending struct {
+	kind   EdgeKind
+	target *targetFrame
+}
+
+func (b *builder) newBlock(kind string, stmt esp.JSElement) *Block {
+	blk := &Block{Index: len(b.g.Blocks), Kind: kind, Stmt: stmt}
+	b.g.Blocks = append(b.g.Blocks, blk)
+	return blk
+}
+
+func (b *builder) edge(from, to *Block, kind EdgeKind) {
+	if from == nil {
+		return
+	}
+	e := &Edge{Kind: kind, From: from, To: to}
+	from.Succs = append(from.Succs, e)
+	to.Preds = append(to.Preds, e)
+	if from.Live {
+		markLive(to)
+	}
+}
+
+func markLive(b *Block) {
+	if b.Live {
+		return
+	}
+	b.Live = true
+	for _, e := range b.Succs {
+		markLive(e.To)
+	}
+}
+
+// add appends a node to the current block.
+func (b *builder) add(n esp.JSElement) {
+	if b.cur == nil {
+		b.cur = b.newBlock("unreachable", n)
+	}
+	b.cur.Nodes = append(b.cur.Nodes, n)
+}
+
+// start continues building in blk, falling into it from the current block.
+func (b *builder) start(blk *Block) {
+	b.edge(b.cur, blk, EdgeNormal)
+	b.cur = blk
+}
+
+func (b *builder) params(params []esp.FunctionParameter) {
+	for _, p := range params {
+		b.add(p)
+	}
+}
+
+func (b *builder) stmts(list interface{}) {
+	switch l := list.(type) {
+	case []esp.StatementListItem:
+		for _, s := range l {
+			b.stmt(s)
+		}
+	case []esp.Statement:
+		for _, s := range l {
+			b.stmt(s)
+		}
+	}
+}
+
+func (b *builder) stmt(s esp.JSElement) {
+	labels := b.labels
+	b.labels = nil
+
+	switch s := s.(type) {
+	case *esp.BlockStatement:
+		b.stmts(s.Items)
+
+	case *esp.ExpressionStatement:
+		b.expr(s.Expression)
+		b.add(s)
+
+	case *esp.VariableDeclaration:
+		for _, d := range s.Declarations {
+			b.expr(d.Init)
+		}
+		b.add(s)
+
+	case *esp.ExportNamedDeclaration:
+		if d, ok := s.Declaration.(*esp.VariableDeclaration); ok {
+			for _, d := range d.Declarations {
+				b.expr(d.Init)
+			}
+		}
+		b.add(s)
+
+	case *esp.ExportDefaultDeclaration:
+		if e, ok := s.Declaration.(esp.Expression); ok {
+			b.expr(e)
+		}
+		b.add(s)
+
+	case *esp.ReturnStatement:
+		b.expr(s.Argument)
+		b.add(s)
+		b.jump(EdgeReturn, nil)
+
+	case *esp.ThrowStatement:
+		b.expr(s.Argument)
+		b.add(s)
+		b.throw()
+
+	case *esp.BreakStatement:
+		b.add(s)
+		if t := b.findTarget(s.Label, false); t != nil {
+			b.jump(EdgeBreak, t)
+		}
+
+	case *esp.ContinueStatement:
+		b.add(s)
+		if t := b.findTarget(s.Label, true); t != nil {
+			b.jump(EdgeContinue, t)
+		}
+
+	case *esp.LabeledStatement:
+		switch s.Body.(type) {
+		case *esp.ForStatement, *esp.ForInStatement, *esp.ForOfStatement,
+			*esp.WhileStatement, *esp.DoWhileStatement:
+			b.labels = append(labels, s.Label.Name)
+			b.stmt(s.Body)
+		default:
+			done := b.newBlock("label.done", s)
+			b.push(&targetFrame{labels: append(labels, s.Label.Name), brk: done})
+			b.stmt(s.Body)
+			b.pop()
+			b.start(done)
+		}
+
+	case *esp.IfStatement:
+		then := b.newBlock("if.then", s)
+		done := b.newBlock("if.done", s)
+		els := done
+		if s.Alternate != nil {
+			els = b.newBlock("if.else", s)
+		}
+		b.cond(s.Test, then, els)
+		b.cur = then
+		b.stmt(s.Consequent)
+		b.edge(b.cur, done, EdgeNormal)
+		if s.Alternate != nil {
+			b.cur = els
+			b.stmt(s.Alternate)
+			b.edge(b.cur, done, EdgeNormal)
+		}
+		b.cur = done
+
+	case *esp.WhileStatement:
+		loop := b.newBlock("while.loop", s)
+		body := b.newBlock("while.body", s)
+		done := b.newBlock("while.done", s)
+		b.start(loop)
+		b.loopCond(s.Test, body, done)
+		b.loop(s, labels, body, done, loop, func() { b.stmt(s.Body) })
+		b.cur = done
+
+	case *esp.DoWhileStatement:
+		body := b.newBlock("do.body", s)
+		test := b.newBlock("do.test", s)
+		done := b.newBlock("do.done", s)
+		b.start(body)
+		b.loop(s, labels, body, done, test, func() { b.stmt(&s.Body) })
+		b.cur = test
+		b.loopCond(s.Test, body, done)
+		b.cur = done
+
+	case *esp.ForStatement:
+		switch init := s.Init.(type) {
+		case nil:
+		case *esp.VariableDeclaration:
+			for _, d := range init.Declarations {
+				b.expr(d.Init)
+			}
+			b.add(init)
+		case esp.Expression:
+			b.expr(init)
+			b.add(init)
+		}
+		loop := b.newBlock("for.loop", s)
+		body := b.newBlock("for.body", s)
+		post := b.newBlock("for.post", s)
+		done := b.newBlock("for.done", s)
+		b.start(loop)
+		if s.Test != nil {
+			b.loopCond(s.Test, body, done)
+		} else {
+			b.edge(b.cur, body, EdgeNormal)
+		}
+		b.loop(s, labels, body, done, post, func() { b.stmt(&s.Body) })
+		b.cur = post
+		if s.Update != nil {
+			b.expr(s.Update)
+			b.add(s.Update)
+		}
+		b.edge(b.cur, loop, EdgeNormal)
+		b.cur = done
+
+	case *esp.ForInStatement:
+		b.forIn(s, s.Left, s.Right, &s.Body, labels)
+
+	case *esp.ForOfStatement:
+		b.forIn(s, s.Left, s.Right, &s.Body, labels)
+
+	case *esp.SwitchStatement:
+		b.switchStmt(s, labels)
+
+	case *esp.TryStatement:
+		b.tryStmt(s)
+
+	case *esp.WithStatement:
+		b.expr(s.Object)
+		b.add(s.Object)
+		b.stmt(s.Body)
+
+	default:
+		// declarations, imports and other straight-line statements
+		b.add(s)
+	}
+}
+
+// loop builds the body of a loop with the given break and continue
+// targets.
+func (b *builder) loop(s esp.JSElement, labels []string, body, brk, cont *Block, build func()) {
+	b.push(&targetFrame{labels: labels, brk: brk, cont: cont, loose: true})
+	b.cur = body
+	build()
+	b.edge(b.cur, cont, EdgeNormal)
+	b.pop()
+}
+
+func (b *builder) forIn(s esp.JSElement, left esp.ForInit, right esp.Expression, body *esp.BlockStatement, labels []string) {
+	b.expr(right)
+	b.add(right)
+	loop := b.newBlock("forin.loop", s)
+	blk := b.newBlock("forin.body", s)
+	done := b.newBlock("forin.done", s)
+	b.start(loop)
+	b.edge(loop, done, EdgeFalse)
+	b.cur = blk
+	b.edge(loop, blk, EdgeTrue)
+	// the next value is assigned at the start of the body
+	b.add(left)
+	b.loop(s, labels, blk, done, loop, func() { b.stmt(body) })
+	b.cur = done
+}
+
+func (b *builder) switchStmt(s *esp.SwitchStatement, labels []string) {
+	b.expr(s.Discriminant)
+	b.add(s.Discriminant)
+	done := b.newBlock("switch.done", s)
+	bodies := make([]*Block, len(s.Cases))
+	for i := range s.Cases {
+		bodies[i] = b.newBlock("switch.body", &s.Cases[i])
+	}
+
+	// tests are evaluated in order, default is taken when none match
+	prev, kind := b.cur, EdgeNormal
+	def := done
+	for i := range s.Cases {
+		c := &s.Cases[i]
+		if c.Test == nil {
+			def = bodies[i]
+			continue
+		}
+		test := b.newBlock("switch.case", c)
+		b.edge(prev, test, kind)
+		b.cur = test
+		b.expr(c.Test)
+		b.add(c.Test)
+		b.edge(b.cur, bodies[i], EdgeTrue)
+		prev, kind = b.cur, EdgeFalse
+	}
+	b.edge(prev, def, kind)
+
+	b.push(&targetFrame{labels: labels, brk: done, loose: true})
+	b.cur = nil
+	for i := range s.Cases {
+		if b.cur != nil {
+			b.edge(b.cur, bodies[i], EdgeFallthrough)
+		}
+		b.cur = bodies[i]
+		b.stmts(s.Cases[i].Consequent.Items)
+	}
+	b.pop()
+	b.edge(b.cur, done, EdgeNormal)
+	b.cur = done
+}
+
+func (b *builder) tryStmt(s *esp.TryStatement) {
+	b.seq++
+	f := &tryFrame{tail: b.tries, seq: b.seq}
+	if s.HasHandler() {
+		f.handler = b.newBlock("try.catch", &s.Handler)
+	}
+	if s.Finalizer != nil {
+		f.finally = b.newBlock("try.finally", s.Finalizer)
+	}
+	done := b.newBlock("try.done", s)
+	var exits []*Block
+
+	// any block of the try body may throw
+	body := b.newBlock("try.body", s)
+	b.start(body)
+	b.tries = f
+	first := len(b.g.Blocks) - 1
+	b.stmts(s.Block.Items)
+	b.exceptions(f, first, f.handler)
+	exits = append(exits, b.cur)
+
+	if f.handler != nil {
+		handler := f.handler
+		f.handler = nil
+		b.cur = handler
+		b.add(&s.Handler)
+		first = len(b.g.Blocks)
+		b.stmts(s.Handler.Body.Items)
+		b.exceptions(f, first, nil)
+		exits = append(exits, b.cur)
+	}
+	b.tries = f.tail
+
+	if f.finally == nil {
+		for _, exit := range exits {
+			b.edge(exit, done, EdgeNormal)
+		}
+		b.cur = done
+		return
+	}
+	var normal bool
+	for _, exit := range exits {
+		if exit != nil && exit.Live {
+			b.edge(exit, f.finally, EdgeNormal)
+			normal = true
+		}
+	}
+	b.cur = f.finally
+	b.stmts(s.Finalizer.Items)
+	end := b.cur
+	if normal {
+		b.edge(end, done, EdgeNormal)
+	}
+	for _, p := range f.pending {
+		b.cur = end
+		switch p.kind {
+		case EdgeThrow:
+			b.throw()
+		default:
+			b.jump(p.kind, p.target)
+		}
+	}
+	b.cur = done
+}
+
+// exceptions adds exception edges from the blocks created since first to
+// handler, or to the finalizer of f if handler is nil.
+func (b *builder) exceptions(f *tryFrame, first int, handler *Block) {
+	to := handler
+	if to == nil {
+		to = f.finally
+	}
+	if to == nil {
+		return
+	}
+	var live bool
+	for _, blk := range b.g.Blocks[first:] {
+		if blk == f.handler || blk == f.finally || blk == to {
+			continue
+		}
+		b.edge(blk, to, EdgeException)
+		live = live || blk.Live
+	}
+	if handler == nil && live {
+		f.pending = appendPending(f.pending, pending{kind: EdgeThrow})
+	}
+}
+
+func appendPending(list []pending, p pending) []pending {
+	for _, q := range list {
+		if q == p {
+			return list
+		}
+	}
+	return append(list, p)
+}
+
+// jump ends the current block with a break or continue to t, or a return
+// if t is nil. Jumps leaving a try statement with a finalizer run it
+// first.
+func (b *builder) jump(kind EdgeKind, t *targetFrame) {
+	from := b.cur
+	b.cur = nil
+	for f := b.tries; f != nil; f = f.tail {
+		if t != nil && t.seq > f.seq {
+			// the target is inside the try statement
+			break
+		}
+		if f.finally != nil {
+			b.edge(from, f.finally, kind)
+			if from != nil && from.Live {
+				f.pending = appendPending(f.pending, pending{kind, t})
+			}
+			return
+		}
+	}
+	switch {
+	case t == nil:
+		b.edge(from, b.g.Exit(), kind)
+	case kind == EdgeContinue:
+		b.edge(from, t.cont, kind)
+	default:
+		b.edge(from, t.brk, kind)
+	}
+}
+
+// throw ends the current block with a throw to the closest handler.
+func (b *builder) throw() {
+	from := b.cur
+	b.cur = nil
+	for f := b.tries; f != nil; f = f.tail {
+		if f.handler != nil {
+			b.edge(from, f.handler, EdgeThrow)
+			return
+		}
+		if f.finally != nil {
+			b.edge(from, f.finally, EdgeThrow)
+			if from != nil && from.Live {
+				f.pending = appendPending(f.pending, pending{kind: EdgeThrow})
+			}
+			return
+		}
+	}
+	b.edge(from, b.g.Exit(), EdgeThrow)
+}
+
+func (b *builder) push(t *targetFrame) {
+	b.seq++
+	t.seq = b.seq
+	t.tail = b.targets
+	b.targets = t
+}
+
+func (b *builder) pop() {
+	b.targets = b.targets.tail
+}
+
+// findTarget returns the statement targeted by a break or continue.
+func (b *builder) findTarget(label *esp.Identifier, cont bool) *targetFrame {
+	for t := b.targets; t != nil; t = t.tail {
+		if cont && t.cont == nil {
+			continue
+		}
+		if label == nil {
+			if t.loose {
+				return t
+			}
+			continue
+		}
+		for _, l := range t.labels {
+			if l == label.Name {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// cond evaluates e as a condition, branching to t or f.
+func (b *builder) cond(e esp.Expression, t, f *Block) {
+	switch e := e.(type) {
+	case *esp.LogicalExpression:
+		switch e.Operator {
+		case esp.LogicalOperatorAnd:
+			rhs := b.newBlock("cond.and", e)
+			b.cond(e.Left, rhs, f)
+			b.cur = rhs
+			b.cond(e.Right, t, f)
+			return
+		case esp.LogicalOperatorOr:
+			rhs := b.newBlock("cond.or", e)
+			b.cond(e.Left, t, rhs)
+			b.cur = rhs
+			b.cond(e.Right, t, f)
+			return
+		}
+	case *esp.ConditionalExpression:
+		then := b.newBlock("cond.true", e)
+		els := b.newBlock("cond.false", e)
+		b.cond(e.Test, then, els)
+		b.cur = then
+		b.cond(e.Consequent, t, f)
+		b.cur = els
+		b.cond(e.Alternate, t, f)
+		return
+	case *esp.UnaryExpression:
+		if e.Operator == esp.UnaryOperatorTypeNot {
+			b.cond(e.Argument, f, t)
+			return
+		}
+	}
+	b.expr(e)
+	b.add(e)
+	b.edge(b.cur, t, EdgeTrue)
+	b.edge(b.cur, f, EdgeFalse)
+	b.cur = nil
+}
+
+// loopCond is cond for the test of a loop. A constantly truthy test, as in
+// while (true), has no false edge: the loop is only left by jumps.
+func (b *builder) loopCond(e esp.Expression, body, done *Block) {
+	if l, ok := e.(esp.Literal); !ok || !truthy(l) {
+		b.cond(e, body, done)
+		return
+	}
+	b.add(e)
+	b.edge(b.cur, body, EdgeTrue)
+	b.cur = nil
+}
+
+// expr splits the short-circuit operators of a value into blocks. The
+// operands evaluated conditionally are added as nodes; the expression
+// itself is added by the caller.
+func (b *builder) expr(e esp.Expression) {
+	if e == nil || !hasBranch(e) {
+		return
+	}
+	switch e := e.(type) {
+	case *esp.LogicalExpression:
+		b.expr(e.Left)
+		b.add(e.Left)
+		rhs := b.newBlock("logical.rhs", e)
+		done := b.newBlock("logical.done", e)
+		switch e.Operator {
+		case esp.LogicalOperatorOr:
+			b.edge(b.cur, done, EdgeTrue)
+			b.edge(b.cur, rhs, EdgeFalse)
+		default:
+			b.edge(b.cur, rhs, EdgeTrue)
+			b.edge(b.cur, done, EdgeFalse)
+		}
+		b.cur = rhs
+		b.expr(e.Right)
+		b.add(e.Right)
+		b.start(done)
+	case *esp.ConditionalExpression:
+		then := b.newBlock("cond.true", e)
+		els := b.newBlock("cond.false", e)
+		done := b.newBlock("cond.done", e)
+		b.cond(e.Test, then, els)
+		b.cur = then
+		b.expr(e.Consequent)
+		b.add(e.Consequent)
+		b.edge(b.cur, done, EdgeNormal)
+		b.cur = els
+		b.expr(e.Alternate)
+		b.add(e.Alternate)
+		b.start(done)
+	default:
+		for _, c := range children(e) {
+			if c, ok := c.(esp.Expression); ok {
+				b.expr(c)
+			}
+		}
+	}
+}
+
+// hasBranch tells whether e contains short-circuit operators outside of
+// nested functions.
+func hasBranch(e esp.Expression) (found bool) {
+	esp.Inspect(e, func(n esp.JSElement) bool {
+		switch n.(type) {
+		case *esp.LogicalExpression, *esp.ConditionalExpression:
+			found = true
+		case *esp.FunctionExpression, *esp.ArrowFunctionExpression, *esp.ClassExpression:
+			return false
+		}
+		return !found
+	})
+	return
+}
+
+// children returns the direct children of n in evaluation order.
+func children(n esp.JSElement) (out []esp.JSElement) {
+	esp.Inspect(n, func(c esp.JSElement) bool {
+		if c == n {
+			return true
+		}
+		if c != nil {
+			out = append(out, c)
+		}
+		return false
+	})
+	return
+}
@@ -0,0 +1,210 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	esp "github.com/MichaelCombs28/goesprima"
+	"github.com/stretchr/testify/assert"
+)
+
+// dump returns one line per block: index, kind, liveness, nodes and
+// successors.
+func dump(g *CFG) string {
+	var lines []string
+	for _, b := range g.Blocks {
+		var nodes, succs []string
+		for _, n := range b.Nodes {
+			nodes = append(nodes, nodeLabel(n))
+		}
+		for _, e := range b.Succs {
+			succs = append(succs, fmt.Sprintf("%s:%d", e.Kind, e.To.Index))
+		}
+		live := ""
+		if !b.Live {
+			live = " dead"
+		}
+		lines = append(lines, fmt.Sprintf("%d %s%s [%s] -> %s", b.Index, b.Kind, live, strings.Join(nodes, " | "), strings.Join(succs, " ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func parseFunc(t *testing.T, src string) esp.JSElement {
+	prog, err := esp.Parse("test.js", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prog.Body[0]
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Src    string
+		Expect string
+	}{
+		{
+			"short-circuit",
+			`function f(a) {
+  if (a && b) {
+    return 1;
+  }
+  x = c ? d : e;
+}`,
+			`0 entry [a | a] -> true:4 false:3
+1 exit [] -> 
+2 if.then [return 1.000000;] -> return:1
+3 if.done [c] -> true:5 false:6
+4 cond.and [b] -> true:2 false:3
+5 cond.true [d] -> normal:7
+6 cond.false [e] -> normal:7
+7 cond.done [x = c? d: e;] -> normal:1`,
+		},
+		{
+			"labeled loops",
+			`function f() {
+  outer: for (let i = 0; i < n; i++) {
+    while (x) {
+      if (y) continue outer;
+      break;
+    }
+  }
+}`,
+			`0 entry [let i = 0.000000] -> normal:2
+1 exit [] -> 
+2 for.loop [i < n] -> true:3 false:5
+3 for.body [] -> normal:6
+4 for.post [i++] -> normal:2
+5 for.done [] -> normal:1
+6 while.loop [x] -> true:7 false:8
+7 while.body [y] -> true:9 false:10
+8 while.done [] -> normal:4
+9 if.then [continue outer;] -> continue:4
+10 if.done [break;] -> break:8`,
+		},
+		{
+			"try",
+			`function f() {
+  try {
+    a();
+    return 1;
+  } catch (e) {
+    throw e;
+  } finally {
+    c();
+  }
+  dead();
+}`,
+			`0 entry [] -> normal:5
+1 exit [] -> 
+2 try.catch [catch (e) { ... | throw e;] -> throw:3
+3 try.finally [c();] -> return:1 throw:1
+4 try.done dead [dead();] -> normal:1
+5 try.body [a(); | return 1.000000;] -> return:3 exception:2`,
+		},
+		{
+			"switch",
+			`function f(x) {
+  switch (x) {
+    case 1:
+      a();
+    case 2:
+      b();
+      break;
+    default:
+      c();
+  }
+}`,
+			`0 entry [x | x] -> normal:6
+1 exit [] -> 
+2 switch.done [] -> normal:1
+3 switch.body [a();] -> fallthrough:4
+4 switch.body [b(); | break;] -> break:2
+5 switch.body [c();] -> normal:2
+6 switch.case [1.000000] -> true:3 false:7
+7 switch.case [2.000000] -> true:4 false:5`,
+		},
+		{
+			"do while and logical values",
+			`function f() {
+  do {
+    v = a || b;
+  } while (!done);
+}`,
+			`0 entry [] -> normal:2
+1 exit [] -> 
+2 do.body [a] -> true:6 false:5
+3 do.test [done] -> true:4 false:2
+4 do.done [] -> normal:1
+5 logical.rhs [b] -> normal:6
+6 logical.done [v = a || b;] -> normal:3`,
+		},
+		{
+			"unreachable",
+			`function f() {
+  return;
+  a();
+}`,
+			`0 entry [return;] -> return:1
+1 exit [] -> 
+2 unreachable dead [a();] -> normal:1`,
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.Expect, dump(New(parseFunc(t, test.Src))), test.Name)
+	}
+}
+
+func TestNewFinallyJumps(t *testing.T) {
+	// break through a finalizer resumes at the loop exit, exceptions rethrow
+	g := New(parseFunc(t, `function f() {
+  while (a) {
+    try {
+      break;
+    } finally {
+      b();
+    }
+  }
+}`))
+	assert.Equal(t, `0 entry [] -> normal:2
+1 exit [] -> 
+2 while.loop [a] -> true:3 false:4
+3 while.body [] -> normal:7
+4 while.done [] -> normal:1
+5 try.finally [b();] -> break:4 throw:1
+6 try.done dead [] -> normal:2
+7 try.body [break;] -> break:5 exception:5`, dump(g))
+}
+
+func TestAll(t *testing.T) {
+	prog, err := esp.Parse("test.js", `const f = (x) => x * 2;
+function g() {
+  return function () {};
+}`)
+	assert.NoError(t, err)
+	graphs := All(prog)
+	if assert.Len(t, graphs, 4) {
+		assert.Equal(t, `0 entry [x | x * 2.000000] -> return:1
+1 exit [] -> `, dump(graphs[1]))
+		assert.Same(t, prog.Body[1], graphs[2].Func)
+	}
+}
+
+func TestDot(t *testing.T) {
+	g := New(parseFunc(t, `function f(a) {
+  if (a) return "x";
+}`))
+	assert.Equal(t, `digraph cfg {
+	node [shape=box];
+	b0 [label="0: entry\la\la\l"];
+	b1 [label="1: exit\l"];
+	b2 [label="2: if.then\lreturn \"x\";\l"];
+	b3 [label="3: if.done\l"];
+	b0 -> b2 [label="true"];
+	b0 -> b3 [label="false"];
+	b2 -> b1 [label="return"];
+	b3 -> b1 [label="normal"];
+}
+`, g.Dot())
+}
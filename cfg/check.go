@@ -0,0 +1,242 @@
+package cfg
+
+import (
+	"fmt"
+
+	esp "github.com/MichaelCombs28/goesprima"
+)
+
+// Rules reported by Check.
+const (
+	RuleUnreachable   = "unreachable"
+	RuleMissingReturn = "missing-return"
+	RuleFallthrough   = "switch-fallthrough"
+	RuleUnsafeFinally = "unsafe-finally"
+)
+
+// Check reports unreachable statements, functions returning a value on
+// some paths but falling off the end on others, non-empty switch cases
+// falling through to the next case, and return or throw statements in
+// finally blocks, which override the completion of the try statement.
+//
+// root is a program, generator or function; the functions nested in it
+// are checked as well.
+func Check(root esp.JSElement) (out []esp.Diagnostic) {
+	for _, g := range All(root) {
+		out = append(out, g.unreachable()...)
+		out = append(out, g.fallthroughs()...)
+		out = append(out, g.unsafeFinally()...)
+		out = append(out, g.missingReturn()...)
+	}
+	return
+}
+
+func diagnostic(rule, msg string, n esp.JSElement) esp.Diagnostic {
+	return esp.Diagnostic{Rule: rule, Message: msg, Node: n, Location: esp.LocationOf(n)}
+}
+
+// unreachable reports the first statement of every unreachable region.
+// Function declarations and var declarations without initializers are
+// hoisted and not reported.
+func (g *CFG) unreachable() (out []esp.Diagnostic) {
+	dead := g.deadStatements()
+	for _, b := range g.Blocks {
+		if len(dead[b]) == 0 || b.Kind == "exit" {
+			continue
+		}
+		var follows bool
+		for _, e := range b.Preds {
+			follows = follows || len(dead[e.From]) > 0
+		}
+		if follows {
+			continue
+		}
+		for _, n := range dead[b] {
+			if !hoisted(n) {
+				out = append(out, diagnostic(RuleUnreachable, "unreachable code", n))
+				break
+			}
+		}
+	}
+	return
+}
+
+// deadStatements returns the statements of every unreachable block.
+// Conditions and operands stand for the statement they are part of, unless
+// that statement is reachable, as the test of do { return } while (x).
+func (g *CFG) deadStatements() map[*Block][]esp.JSElement {
+	live := make(map[esp.JSElement]bool)
+	for _, b := range g.Blocks {
+		if b.Live && b.Stmt != nil {
+			live[b.Stmt] = true
+		}
+	}
+	var root *esp.NodePath
+	out := make(map[*Block][]esp.JSElement)
+	for _, b := range g.Blocks {
+		if b.Live {
+			continue
+		}
+		for _, n := range b.Nodes {
+			if _, ok := n.(esp.StatementListItem); !ok {
+				if root == nil {
+					root = esp.NewPath(g.Func)
+				}
+				if p := root.Lookup(n); p != nil {
+					if s := p.FindParent(isStatement); s != nil {
+						n = s.Node
+					}
+				}
+			}
+			if !live[n] {
+				out[b] = append(out[b], n)
+			}
+		}
+	}
+	return out
+}
+
+func isStatement(p *esp.NodePath) bool {
+	_, ok := p.Node.(esp.StatementListItem)
+	return ok
+}
+
+func hoisted(n esp.JSElement) bool {
+	switch n := n.(type) {
+	case *esp.FunctionDeclaration:
+		return true
+	case *esp.VariableDeclaration:
+		if n.Kind != esp.VariableDeclarationTypeVar {
+			return false
+		}
+		for _, d := range n.Declarations {
+			if d.Init != nil {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// fallthroughs reports the last statement of non-empty cases that fall
+// through to the next case.
+func (g *CFG) fallthroughs() (out []esp.Diagnostic) {
+	bodies := make(map[*esp.SwitchCase]*Block)
+	for _, b := range g.Blocks {
+		if c, ok := b.Stmt.(*esp.SwitchCase); ok && b.Kind == "switch.body" {
+			bodies[c] = b
+		}
+	}
+	g.inspect(func(n esp.JSElement) {
+		s, ok := n.(*esp.SwitchStatement)
+		if !ok {
+			return
+		}
+		for i := 0; i+1 < len(s.Cases); i++ {
+			items := s.Cases[i].Consequent.Items
+			if len(items) == 0 {
+				continue
+			}
+			for _, e := range bodies[&s.Cases[i+1]].Preds {
+				if e.Kind == EdgeFallthrough && e.From.Live {
+					out = append(out, diagnostic(RuleFallthrough, "case falls through to the next case", items[len(items)-1]))
+					break
+				}
+			}
+		}
+	})
+	return
+}
+
+// unsafeFinally reports return and throw statements in finally blocks.
+// Throws caught by a try statement nested in the finally block don't leave
+// it and are not reported.
+func (g *CFG) unsafeFinally() (out []esp.Diagnostic) {
+	g.inspect(func(n esp.JSElement) {
+		t, ok := n.(*esp.TryStatement)
+		if !ok || t.Finalizer == nil {
+			return
+		}
+		caught := make(map[esp.JSElement]bool)
+		inspectBody(t.Finalizer, func(n esp.JSElement) {
+			if t, ok := n.(*esp.TryStatement); ok && t.HasHandler() {
+				inspectBody(&t.Block, func(n esp.JSElement) {
+					if _, ok := n.(*esp.ThrowStatement); ok {
+						caught[n] = true
+					}
+				})
+			}
+		})
+		inspectBody(t.Finalizer, func(n esp.JSElement) {
+			switch n.(type) {
+			case *esp.ReturnStatement:
+				out = append(out, diagnostic(RuleUnsafeFinally, "return in finally overrides the completion of the try statement", n))
+			case *esp.ThrowStatement:
+				if !caught[n] {
+					out = append(out, diagnostic(RuleUnsafeFinally, "throw in finally overrides the completion of the try statement", n))
+				}
+			}
+		})
+	})
+	return
+}
+
+// missingReturn reports functions that return a value on some paths and
+// fall off the end on others.
+func (g *CFG) missingReturn() []esp.Diagnostic {
+	var name string
+	switch f := g.Func.(type) {
+	case *esp.FunctionDeclaration:
+		name = "function"
+		if f.ID != nil {
+			name += " " + f.ID.Name
+		}
+	case *esp.FunctionExpression:
+		name = "function"
+		if f.ID != nil {
+			name += " " + f.ID.Name
+		}
+	case *esp.ArrowFunctionExpression:
+		name = "arrow function"
+	default:
+		return nil
+	}
+	var value bool
+	g.inspect(func(n esp.JSElement) {
+		if r, ok := n.(*esp.ReturnStatement); ok && r.Argument != nil {
+			value = true
+		}
+	})
+	if !value {
+		return nil
+	}
+	for _, e := range g.Exit().Preds {
+		if e.Kind == EdgeNormal && e.From.Live {
+			return []esp.Diagnostic{diagnostic(RuleMissingReturn, fmt.Sprintf("not all code paths of %s return a value", name), g.Func)}
+		}
+	}
+	return nil
+}
+
+// inspect calls f for every node of the function of g, excluding nested
+// functions.
+func (g *CFG) inspect(f func(esp.JSElement)) {
+	inspectBody(g.Func, f)
+}
+
+func inspectBody(root esp.JSElement, f func(esp.JSElement)) {
+	esp.Inspect(root, func(n esp.JSElement) bool {
+		if n == nil {
+			return false
+		}
+		if n != root {
+			switch n.(type) {
+			case *esp.FunctionDeclaration, *esp.FunctionExpression, *esp.ArrowFunctionExpression:
+				return false
+			}
+		}
+		f(n)
+		return true
+	})
+}
@@ -0,0 +1,154 @@
+package cfg
+
+import (
+	"testing"
+
+	esp "github.com/MichaelCombs28/goesprima"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	prog, err := esp.Parse("test.js", `function f(x) {
+  if (x) {
+    return 1;
+  }
+  switch (x) {
+    case 1:
+      g();
+    case 2:
+    case 3:
+      break;
+  }
+}
+function g() {
+  try {
+    return 1;
+  } finally {
+    return 2;
+  }
+  h();
+  function h() {}
+}
+function k() {
+  while (true) {
+    break;
+    done();
+  }
+  throw new Error();
+  const x = 1;
+}
+const ok = (x) => {
+  if (x) return 1;
+  throw x;
+};`)
+	assert.NoError(t, err)
+	var out []string
+	for _, d := range Check(prog) {
+		out = append(out, d.String())
+	}
+	assert.Equal(t, []string{
+		"test.js:7:6: case falls through to the next case (switch-fallthrough)",
+		"test.js:1:0: not all code paths of function f return a value (missing-return)",
+		"test.js:19:2: unreachable code (unreachable)",
+		"test.js:17:4: return in finally overrides the completion of the try statement (unsafe-finally)",
+		"test.js:25:4: unreachable code (unreachable)",
+		"test.js:28:2: unreachable code (unreachable)",
+	}, out)
+}
+
+func TestCheckGenerator(t *testing.T) {
+	g := esp.NewGenerator().AddStatements(
+		esp.MustStmt(`function handler(event) {
+  if (event.ok) {
+    return event.body;
+  }
+  console.log(event);
+}`, nil),
+	)
+	diags := Check(g)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, RuleMissingReturn, diags[0].Rule)
+		assert.Nil(t, diags[0].Location)
+		assert.Equal(t, "not all code paths of function handler return a value (missing-return)", diags[0].String())
+	}
+}
+
+func TestCheckAnonymousDeclaration(t *testing.T) {
+	prog, err := esp.Parse("test.js", "export default function (x) { if (x) return 1; }")
+	assert.NoError(t, err)
+	diags := Check(prog)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "test.js:1:15: not all code paths of function return a value (missing-return)", diags[0].String())
+	}
+}
+
+func TestCheckInfiniteLoops(t *testing.T) {
+	prog, err := esp.Parse("test.js", `function f(x) {
+  while (true) {
+    if (x) return 1;
+  }
+}
+function g(x) {
+  for (;;) {
+    if (x) return 1;
+  }
+}
+function h(x) {
+  do {
+    if (x) return 1;
+  } while (1);
+  x();
+}`)
+	assert.NoError(t, err)
+	var out []string
+	for _, d := range Check(prog) {
+		out = append(out, d.String())
+	}
+	assert.Equal(t, []string{"test.js:15:2: unreachable code (unreachable)"}, out)
+}
+
+func TestCheckUnreachableStatements(t *testing.T) {
+	prog, err := esp.Parse("test.js", `function f(x) {
+  do {
+    return;
+  } while (x);
+}
+function g(x) {
+  for (let i = 0; i < x; i++) {
+    return;
+  }
+}
+function h(x) {
+  do {
+    return;
+  } while (x);
+  if (x) y();
+}`)
+	assert.NoError(t, err)
+	var out []string
+	for _, d := range Check(prog) {
+		out = append(out, d.String())
+	}
+	// the loop tests and updates are part of reachable statements
+	assert.Equal(t, []string{"test.js:15:2: unreachable code (unreachable)"}, out)
+}
+
+func TestCheckUnsafeFinally(t *testing.T) {
+	prog, err := esp.Parse("test.js", `function f() {
+  try {
+    return 1;
+  } finally {
+    try {
+      throw 1;
+    } catch (e) {
+      throw e;
+    }
+  }
+}`)
+	assert.NoError(t, err)
+	var out []string
+	for _, d := range Check(prog) {
+		out = append(out, d.String())
+	}
+	assert.Equal(t, []string{"test.js:8:6: throw in finally overrides the completion of the try statement (unsafe-finally)"}, out)
+}
@@ -0,0 +1,432 @@
+package cfg
+
+import (
+	esp "github.com/MichaelCombs28/goesprima"
+)
+
+// Direction is the direction facts flow in a dataflow problem.
+type Direction int
+
+const (
+	Forward Direction = iota
+	Backward
+)
+
+// A Problem is a dataflow problem over facts of type F. Transfer and Join
+// must not modify their arguments.
+type Problem[F any] struct {
+	Direction Direction
+	// Boundary is the fact at the entry of forward problems and at the
+	// exit of backward ones.
+	Boundary F
+	// Init is the initial fact of every other block.
+	Init     F
+	Join     func(a, b F) F
+	Transfer func(b *Block, f F) F
+	Equal    func(a, b F) bool
+}
+
+// Result holds the facts at the start and end of every block, indexed by
+// Block.Index, in program order for both directions.
+type Result[F any] struct {
+	In, Out []F
+}
+
+// Solve computes the fixed point of p over g with a worklist.
+func Solve[F any](g *CFG, p Problem[F]) Result[F] {
+	n := len(g.Blocks)
+	r := Result[F]{In: make([]F, n), Out: make([]F, n)}
+	for i := range g.Blocks {
+		r.In[i], r.Out[i] = p.Init, p.Init
+	}
+
+	// before and after are the facts flowing into and out of blocks
+	before, after := r.In, r.Out
+	boundary := g.Entry()
+	if p.Direction == Backward {
+		before, after = r.Out, r.In
+		boundary = g.Exit()
+	}
+	sources := func(b *Block) (out []*Block) {
+		edges := b.Preds
+		if p.Direction == Backward {
+			edges = b.Succs
+		}
+		for _, e := range edges {
+			if p.Direction == Backward {
+				out = append(out, e.To)
+			} else {
+				out = append(out, e.From)
+			}
+		}
+		return
+	}
+	targets := func(b *Block) (out []*Block) {
+		edges := b.Succs
+		if p.Direction == Backward {
+			edges = b.Preds
+		}
+		for _, e := range edges {
+			if p.Direction == Backward {
+				out = append(out, e.From)
+			} else {
+				out = append(out, e.To)
+			}
+		}
+		return
+	}
+
+	work := make([]*Block, n)
+	queued, visited := make([]bool, n), make([]bool, n)
+	for i, b := range g.Blocks {
+		if p.Direction == Backward {
+			b = g.Blocks[n-1-i]
+		}
+		work[i], queued[b.Index] = b, true
+	}
+	for len(work) > 0 {
+		b := work[0]
+		work = work[1:]
+		queued[b.Index] = false
+
+		fact := p.Init
+		if b == boundary {
+			fact = p.Boundary
+		}
+		for _, s := range sources(b) {
+			fact = p.Join(fact, after[s.Index])
+		}
+		before[b.Index] = fact
+		out := p.Transfer(b, fact)
+		if visited[b.Index] && p.Equal(out, after[b.Index]) {
+			continue
+		}
+		visited[b.Index] = true
+		after[b.Index] = out
+		for _, t := range targets(b) {
+			if !queued[t.Index] {
+				work = append(work, t)
+				queued[t.Index] = true
+			}
+		}
+	}
+	return r
+}
+
+// A Def is a write of a variable.
+type Def struct {
+	Name string
+	// Variable is nil for variables declared outside of the function.
+	Variable *esp.Variable
+	Ident    *esp.Identifier
+	// Node is the *AssignmentExpression, *VariableDeclarator, update
+	// expression or parameter writing the variable.
+	Node esp.JSElement
+	// Value is the value written by plain assignments and initializers of
+	// identifiers, nil otherwise.
+	Value esp.Expression
+
+	index int
+	v     int
+}
+
+// A Use is a read of a variable.
+type Use struct {
+	Name     string
+	Variable *esp.Variable
+	Ident    *esp.Identifier
+
+	v int
+}
+
+// event is a def or use, in evaluation order.
+type event struct {
+	def *Def
+	use *Use
+}
+
+// flow holds the defs and uses of the blocks of a graph. Nested functions
+// are opaque: their reads and writes of the variables of g are ignored.
+type flow struct {
+	g      *CFG
+	scopes *esp.ScopeManager
+	defs   []*Def
+	uses   []*Use
+	events [][]event
+	// vars indexes variables by *esp.Variable, or by name for free ones.
+	vars  map[interface{}]int
+	names []string
+	nodes map[esp.JSElement]bool
+}
+
+func newFlow(g *CFG) *flow {
+	f := &flow{
+		g:      g,
+		scopes: esp.Analyze(g.Func, esp.ScopeOptions{}),
+		events: make([][]event, len(g.Blocks)),
+		vars:   make(map[interface{}]int),
+		nodes:  make(map[esp.JSElement]bool),
+	}
+	for _, b := range g.Blocks {
+		for _, n := range b.Nodes {
+			f.nodes[n] = true
+		}
+	}
+	for _, b := range g.Blocks {
+		for _, n := range b.Nodes {
+			f.scan(b, n, n)
+		}
+	}
+	return f
+}
+
+func (f *flow) variable(id *esp.Identifier) (*esp.Variable, int) {
+	v := f.scopes.VariableOf(id)
+	var key interface{} = id.Name
+	if v != nil {
+		key = v
+	}
+	i, ok := f.vars[key]
+	if !ok {
+		i = len(f.names)
+		f.vars[key] = i
+		f.names = append(f.names, id.Name)
+	}
+	return v, i
+}
+
+func (f *flow) def(b *Block, id *esp.Identifier, node esp.JSElement, value esp.Expression) {
+	v, i := f.variable(id)
+	d := &Def{Name: id.Name, Variable: v, Ident: id, Node: node, Value: value, index: len(f.defs), v: i}
+	f.defs = append(f.defs, d)
+	f.events[b.Index] = append(f.events[b.Index], event{def: d})
+}
+
+func (f *flow) use(b *Block, id *esp.Identifier) {
+	v, i := f.variable(id)
+	u := &Use{Name: id.Name, Variable: v, Ident: id, v: i}
+	f.uses = append(f.uses, u)
+	f.events[b.Index] = append(f.events[b.Index], event{use: u})
+}
+
+// scan records the defs and uses of n in evaluation order. Parts of n that
+// are nodes of their own blocks are skipped.
+func (f *flow) scan(b *Block, root, n esp.JSElement) {
+	if n != root && f.nodes[n] {
+		return
+	}
+	switch n := n.(type) {
+	case nil:
+	case *esp.Identifier:
+		r := f.scopes.ReferenceOf(n)
+		switch {
+		case r != nil:
+			if r.IsRead() {
+				f.use(b, n)
+			}
+			if r.IsWrite() {
+				f.def(b, n, n, r.WriteExpr)
+			}
+		case isParam(f.scopes.VariableOf(n)):
+			f.def(b, n, n, nil)
+		}
+		return
+
+	case *esp.CatchClause:
+		// the body is made of nodes of the handler block
+		if n.BindingIdentifierOrPattern != nil {
+			for _, id := range bindingIdentifiers(n.BindingIdentifierOrPattern) {
+				f.def(b, id, n, nil)
+			}
+		}
+		return
+
+	case *esp.AssignmentExpression:
+		f.scan(b, root, n.Right)
+		if id, ok := n.Left.(*esp.Identifier); ok {
+			var value esp.Expression
+			if n.Operator == esp.AssignmentOperatorEq {
+				value = n.Right
+			} else {
+				f.use(b, id)
+			}
+			f.def(b, id, n, value)
+		} else {
+			f.scan(b, root, n.Left)
+		}
+		return
+
+	case *esp.VariableDeclarator:
+		f.scan(b, root, n.Init)
+		f.pattern(b, root, n.ID, n)
+		return
+
+	case *esp.AssignmentPattern:
+		f.scan(b, root, n.Right)
+		f.scan(b, root, n.Left)
+		return
+
+	case *esp.UnaryExpression:
+		if id, ok := n.Argument.(*esp.Identifier); ok && isUpdate(n.Operator) {
+			f.use(b, id)
+			f.def(b, id, n, nil)
+			return
+		}
+
+	case *esp.UpdateExpression:
+		if id, ok := n.Argument.(*esp.Identifier); ok {
+			f.use(b, id)
+			f.def(b, id, n, nil)
+			return
+		}
+
+	case *esp.FunctionDeclaration, *esp.FunctionExpression, *esp.ArrowFunctionExpression,
+		*esp.ClassDeclaration, *esp.ClassExpression:
+		return
+	}
+	for _, c := range children(n) {
+		f.scan(b, root, c)
+	}
+}
+
+// pattern records the defs of a declarator. Declarators without an
+// initializer define let and const bindings as undefined, and var
+// bindings not at all, unless they are written by a for-in or for-of loop.
+func (f *flow) pattern(b *Block, root esp.JSElement, target esp.JSElement, d *esp.VariableDeclarator) {
+	switch p := target.(type) {
+	case *esp.Identifier:
+		r := f.scopes.ReferenceOf(p)
+		v := f.scopes.VariableOf(p)
+		switch {
+		case r != nil && r.IsWrite():
+			var value esp.Expression
+			if d.ID == target {
+				value = d.Init
+			}
+			f.def(b, p, d, value)
+		case v != nil && len(v.Defs) > 0 && v.Defs[0].Type != esp.DefinitionVar:
+			f.def(b, p, d, esp.LiteralValueUndefined)
+		}
+	case *esp.AssignmentPattern:
+		f.scan(b, root, p.Right)
+		f.pattern(b, root, p.Left, d)
+	case *esp.ArrayPattern:
+		for _, e := range p.Elements {
+			if e != nil {
+				f.pattern(b, root, e, d)
+			}
+		}
+	case *esp.ObjectPattern:
+		for _, prop := range p.Properties {
+			f.pattern(b, root, prop, d)
+		}
+	case *esp.PropertyPattern:
+		if p.Computed {
+			f.scan(b, root, p.Key)
+		}
+		if p.Value != nil {
+			f.pattern(b, root, p.Value, d)
+		} else {
+			f.pattern(b, root, p.Key, d)
+		}
+	case *esp.RestElement:
+		f.pattern(b, root, p.Argument, d)
+	}
+}
+
+// bindingIdentifiers returns the identifiers bound by a pattern.
+func bindingIdentifiers(target esp.JSElement) (out []*esp.Identifier) {
+	switch p := target.(type) {
+	case *esp.Identifier:
+		out = append(out, p)
+	case *esp.AssignmentPattern:
+		out = bindingIdentifiers(p.Left)
+	case *esp.ArrayPattern:
+		for _, e := range p.Elements {
+			if e != nil {
+				out = append(out, bindingIdentifiers(e)...)
+			}
+		}
+	case *esp.ObjectPattern:
+		for _, prop := range p.Properties {
+			out = append(out, bindingIdentifiers(prop)...)
+		}
+	case *esp.PropertyPattern:
+		if p.Value != nil {
+			out = bindingIdentifiers(p.Value)
+		} else {
+			out = bindingIdentifiers(p.Key)
+		}
+	case *esp.RestElement:
+		out = bindingIdentifiers(p.Argument)
+	}
+	return
+}
+
+func isParam(v *esp.Variable) bool {
+	return v != nil && len(v.Defs) > 0 && v.Defs[0].Type == esp.DefinitionParameter
+}
+
+func isUpdate(op esp.UnaryOperatorType) bool {
+	switch op {
+	case esp.UnaryOperatorTypeIncrementPrefix, esp.UnaryOperatorTypeIncrementPostfix,
+		esp.UnaryOperatorTypeDecrementPrefix, esp.UnaryOperatorTypeDecrementPostfix:
+		return true
+	}
+	return false
+}
+
+// bits is a set of small integers.
+type bits []uint64
+
+func (s bits) has(i int) bool {
+	return i/64 < len(s) && s[i/64]&(1<<(i%64)) != 0
+}
+
+func (s bits) with(i int) bits {
+	out := make(bits, len(s))
+	copy(out, s)
+	for len(out) <= i/64 {
+		out = append(out, 0)
+	}
+	out[i/64] |= 1 << (i % 64)
+	return out
+}
+
+func (s bits) without(i int) bits {
+	if !s.has(i) {
+		return s
+	}
+	out := make(bits, len(s))
+	copy(out, s)
+	out[i/64] &^= 1 << (i % 64)
+	return out
+}
+
+func (s bits) union(t bits) bits {
+	if len(s) < len(t) {
+		s, t = t, s
+	}
+	out := make(bits, len(s))
+	copy(out, s)
+	for i, w := range t {
+		out[i] |= w
+	}
+	return out
+}
+
+func (s bits) equal(t bits) bool {
+	if len(s) < len(t) {
+		s, t = t, s
+	}
+	for i, w := range s {
+		var o uint64
+		if i < len(t) {
+			o = t[i]
+		}
+		if w != o {
+			return false
+		}
+	}
+	return true
+}
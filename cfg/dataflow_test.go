@@ -0,0 +1,100 @@
+package cfg
+
+import (
+	"testing"
+
+	esp "github.com/MichaelCombs28/goesprima"
+	"github.com/stretchr/testify/assert"
+)
+
+const dataflowSrc = `function f(c, unused) {
+  let x = 1;
+  let y = 2;
+  if (c) {
+    x = 3;
+  }
+  y = x + y;
+  const k = 2 * 3;
+  let s = "a" + "b";
+  if (c) {
+    s = "ab";
+  }
+  let dead = 4;
+  dead = 5;
+  return k + y + s;
+}`
+
+// uses returns the identifiers named name in order.
+func uses(root esp.JSElement, name string) (out []*esp.Identifier) {
+	esp.Inspect(root, func(n esp.JSElement) bool {
+		if id, ok := n.(*esp.Identifier); ok && id.Name == name {
+			out = append(out, id)
+		}
+		return n != nil
+	})
+	return
+}
+
+func TestReachingDefinitions(t *testing.T) {
+	fn := parseFunc(t, dataflowSrc)
+	r := ReachingDefinitions(New(fn))
+
+	// x in y = x + y
+	x := uses(fn, "x")[2]
+	var got []string
+	for _, d := range r.Reaching(x) {
+		got = append(got, d.Node.String())
+	}
+	assert.Equal(t, []string{"x = 1.000000", "x = 3.000000"}, got)
+
+	// y in return
+	defs := r.Reaching(uses(fn, "y")[3])
+	if assert.Len(t, defs, 1) {
+		assert.Equal(t, "y = x + y", defs[0].Node.String())
+	}
+	assert.Nil(t, r.Reaching(uses(fn, "unused")[0]))
+}
+
+func TestLiveVariables(t *testing.T) {
+	fn := parseFunc(t, dataflowSrc)
+	g := New(fn)
+	l := LiveVariables(g)
+	assert.Equal(t, []string{"c", "x", "y"}, l.LiveOut(g.Entry()))
+	assert.Empty(t, l.LiveIn(g.Entry()))
+
+	var dead []string
+	for _, d := range l.DeadStores() {
+		dead = append(dead, d.Node.String())
+	}
+	assert.Equal(t, []string{"unused", "dead = 4.000000", "dead = 5.000000"}, dead)
+}
+
+func TestConstantPropagation(t *testing.T) {
+	fn := parseFunc(t, dataflowSrc)
+	c := ConstantPropagation(New(fn))
+
+	v, ok := c.Value(uses(fn, "k")[1])
+	assert.True(t, ok)
+	assert.Equal(t, esp.NumberLiteral(6), v)
+	v, ok = c.Value(uses(fn, "s")[2])
+	assert.True(t, ok)
+	assert.Equal(t, esp.StringLiteral("ab"), v)
+	_, ok = c.Value(uses(fn, "x")[2])
+	assert.False(t, ok)
+	_, ok = c.Value(uses(fn, "y")[3])
+	assert.False(t, ok)
+}
+
+func TestConstantPropagationClosure(t *testing.T) {
+	fn := parseFunc(t, `function f() {
+  let n = 1;
+  inc();
+  return n;
+  function inc() {
+    n++;
+  }
+}`)
+	c := ConstantPropagation(New(fn))
+	_, ok := c.Value(uses(fn, "n")[1])
+	assert.False(t, ok)
+}
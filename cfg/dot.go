@@ -0,0 +1,63 @@
+package cfg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxLabel is the length nodes are truncated to in DOT labels.
+const maxLabel = 40
+
+// Dot returns the graph in the Graphviz DOT language. Unreachable blocks
+// are dashed.
+func (g *CFG) Dot() string {
+	var sb strings.Builder
+	g.WriteDot(&sb)
+	return sb.String()
+}
+
+// WriteDot writes the graph in the Graphviz DOT language to w.
+func (g *CFG) WriteDot(w io.Writer) error {
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	printf("digraph cfg {\n\tnode [shape=box];\n")
+	for _, b := range g.Blocks {
+		label := fmt.Sprintf("%d: %s\\l", b.Index, b.Kind)
+		for _, n := range b.Nodes {
+			label += dotEscape(nodeLabel(n)) + "\\l"
+		}
+		style := ""
+		if !b.Live {
+			style = ", style=dashed"
+		}
+		printf("\tb%d [label=\"%s\"%s];\n", b.Index, label, style)
+	}
+	for _, b := range g.Blocks {
+		for _, e := range b.Succs {
+			printf("\tb%d -> b%d [label=%q];\n", e.From.Index, e.To.Index, string(e.Kind))
+		}
+	}
+	printf("}\n")
+	return err
+}
+
+// nodeLabel returns the first line of a node, truncated.
+func nodeLabel(n fmt.Stringer) string {
+	s := n.String()
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " ..."
+	}
+	if r := []rune(s); len(r) > maxLabel {
+		s = string(r[:maxLabel]) + "..."
+	}
+	return s
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,189 @@
+package goesprima
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rules reported by CheckBindings.
+const (
+	RuleUnusedVariable  = "unused-variable"
+	RuleUnusedImport    = "unused-import"
+	RuleUnusedParameter = "unused-parameter"
+	RuleUnusedFunction  = "unused-function"
+	RuleUndeclared      = "undeclared"
+)
+
+// A Diagnostic is a problem found in a tree.
+type Diagnostic struct {
+	Rule    string
+	Message string
+	Node    JSElement
+	// Location of Node, nil for trees built without positions.
+	Location *SourceLocation
+}
+
+func (d Diagnostic) String() string {
+	msg := fmt.Sprintf("%s (%s)", d.Message, d.Rule)
+	if d.Location == nil {
+		return msg
+	}
+	pos := fmt.Sprintf("%d:%d", d.Location.Start.Line, d.Location.Start.Column)
+	if d.Location.Source != "" {
+		pos = d.Location.Source + ":" + pos
+	}
+	return pos + ": " + msg
+}
+
+// BindingOptions controls the behaviour of CheckBindings.
+type BindingOptions struct {
+	// Globals are the names provided by the environment, eg.
+	// MergeGlobals(ES2022Globals, BrowserGlobals).
+	Globals Globals
+	// Script analyzes programs as classic scripts. Top-level declarations
+	// of scripts are globals and never reported as unused.
+	Script bool
+	// IgnorePrefix exempts variables whose names start with it from the
+	// unused rules, eg. "_".
+	IgnorePrefix string
+}
+
+// CheckBindings reports unused variables, imports, parameters and
+// functions, and references to globals that are neither declared nor in
+// opts.Globals. Diagnostics are ordered by scope and declaration.
+//
+// Exported declarations are used. Parameters are only reported after the
+// last used parameter, and references in typeof are not undeclared.
+func CheckBindings(root JSElement, opts BindingOptions) []Diagnostic {
+	m := Analyze(root, ScopeOptions{Script: opts.Script})
+	exported := exportedDeclarations(root)
+
+	var out []Diagnostic
+	for _, s := range m.Scopes {
+		if s.Type == ScopeGlobal && opts.Script {
+			continue
+		}
+		params := unusedParams(s)
+		for _, v := range s.Variables {
+			if len(v.Defs) == 0 || isUsed(v) || (opts.IgnorePrefix != "" && strings.HasPrefix(v.Name, opts.IgnorePrefix)) {
+				continue
+			}
+			def := v.Defs[0]
+			if def.Type != DefinitionParameter && (exported[def.Node] || exported[def.Parent]) {
+				continue
+			}
+			rule, what := RuleUnusedVariable, "declared"
+			switch def.Type {
+			case DefinitionImport:
+				rule, what = RuleUnusedImport, "imported"
+			case DefinitionParameter:
+				if !params[v] {
+					continue
+				}
+				rule = RuleUnusedParameter
+			case DefinitionFunction:
+				if def.Node == s.Block {
+					// the name of a function expression
+					continue
+				}
+				rule = RuleUnusedFunction
+			case DefinitionClass:
+				if _, ok := def.Node.(*ClassDeclaration); ok && def.Node == s.Block {
+					// the inner binding of a class declaration
+					continue
+				}
+			case DefinitionCatchClause:
+				continue
+			case DefinitionVar, DefinitionLet, DefinitionConst:
+				if len(v.References) > 0 {
+					what = "assigned a value"
+				}
+			}
+			out = append(out, Diagnostic{
+				Rule:     rule,
+				Message:  fmt.Sprintf("'%s' is %s but never used", v.Name, what),
+				Node:     def.Name,
+				Location: LocationOf(def.Name),
+			})
+		}
+	}
+
+	typeofs := make(map[*Identifier]bool)
+	Inspect(root, func(n JSElement) bool {
+		if u, ok := n.(*UnaryExpression); ok && u.Operator == UnaryOperatorTypeTypeof {
+			if id, ok := u.Argument.(*Identifier); ok {
+				typeofs[id] = true
+			}
+		}
+		return true
+	})
+	for _, r := range m.GlobalScope().Through {
+		id := r.Identifier
+		if opts.Globals[id.Name] || typeofs[id] {
+			continue
+		}
+		out = append(out, Diagnostic{
+			Rule:     RuleUndeclared,
+			Message:  fmt.Sprintf("'%s' is not defined", id.Name),
+			Node:     id,
+			Location: LocationOf(id),
+		})
+	}
+	return out
+}
+
+// isUsed tells whether v is read outside of its own updates, such as x++.
+func isUsed(v *Variable) bool {
+	for _, r := range v.References {
+		if r.IsRead() && !(r.IsReadWrite() && r.WriteExpr == nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// unusedParams returns the unused parameters of a function scope that come
+// after its last used parameter.
+func unusedParams(s *Scope) map[*Variable]bool {
+	out := make(map[*Variable]bool)
+	if s.Type != ScopeFunction {
+		return out
+	}
+	var params []*Variable
+	for _, v := range s.Variables {
+		if len(v.Defs) > 0 && v.Defs[0].Type == DefinitionParameter {
+			params = append(params, v)
+		}
+	}
+	for i := len(params) - 1; i >= 0; i-- {
+		if isUsed(params[i]) {
+			break
+		}
+		out[params[i]] = true
+	}
+	return out
+}
+
+// exportedDeclarations returns the declarations exported by the top level
+// of a module.
+func exportedDeclarations(root JSElement) map[JSElement]bool {
+	var body []StatementListItem
+	switch r := root.(type) {
+	case *Program:
+		body = r.Body
+	case *Generator:
+		body = r.Statements
+	}
+	out := make(map[JSElement]bool)
+	for _, item := range body {
+		switch e := item.(type) {
+		case *ExportNamedDeclaration:
+			if e.Declaration != nil {
+				out[e.Declaration] = true
+			}
+		case *ExportDefaultDeclaration:
+			out[e.Declaration] = true
+		}
+	}
+	return out
+}
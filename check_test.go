@@ -0,0 +1,71 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBindings(t *testing.T) {
+	prog, err := Parse("test.js", `import { a, b } from "m";
+import * as ns from "n";
+export const used = a;
+let counter = 0;
+counter++;
+function helper(x, y, z) {
+  return y;
+}
+export function run(_opts) {
+  const f = function self() {};
+  return typeof missing + undeclared + document.title;
+}
+class Node {
+  static root = new Node();
+}`)
+	assert.NoError(t, err)
+	diags := CheckBindings(prog, BindingOptions{
+		Globals:      MergeGlobals(ES2022Globals, BrowserGlobals),
+		IgnorePrefix: "_",
+	})
+	var out []string
+	for _, d := range diags {
+		out = append(out, d.String())
+	}
+	assert.Equal(t, []string{
+		"test.js:1:12: 'b' is imported but never used (unused-import)",
+		"test.js:2:12: 'ns' is imported but never used (unused-import)",
+		"test.js:4:4: 'counter' is assigned a value but never used (unused-variable)",
+		"test.js:6:9: 'helper' is declared but never used (unused-function)",
+		"test.js:13:6: 'Node' is declared but never used (unused-variable)",
+		"test.js:6:22: 'z' is declared but never used (unused-parameter)",
+		"test.js:10:8: 'f' is assigned a value but never used (unused-variable)",
+		"test.js:11:26: 'undeclared' is not defined (undeclared)",
+	}, out)
+
+	// without browser globals document is undeclared, and _opts unused
+	diags = CheckBindings(prog, BindingOptions{Globals: ES2022Globals})
+	out = out[:0]
+	for _, d := range diags[len(diags)-4:] {
+		out = append(out, d.Message)
+	}
+	assert.Equal(t, []string{
+		"'_opts' is declared but never used",
+		"'f' is assigned a value but never used",
+		"'undeclared' is not defined",
+		"'document' is not defined",
+	}, out)
+}
+
+func TestCheckBindingsGenerator(t *testing.T) {
+	g := NewGenerator().AddStatements(
+		MustStmt(`import Amplify from "@aws-amplify/core";`, nil),
+		MustStmt(`import { Auth } from "@aws-amplify/auth";`, nil),
+		MustStmt(`export const config = { region: REGION, storage: localStorage };`, nil),
+		MustStmt(`Amplify.configure(config);`, nil),
+	)
+	diags := CheckBindings(g, BindingOptions{Globals: MergeGlobals(BrowserGlobals, NewGlobals("REGION"))})
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, RuleUnusedImport, diags[0].Rule)
+		assert.Equal(t, "'Auth' is imported but never used (unused-import)", diags[0].String())
+	}
+}
@@ -0,0 +1,57 @@
+package goesprima
+
+import (
+	"math/big"
+	"reflect"
+)
+
+// Clone returns a deep copy of node, including interface-typed children,
+// value-typed fields such as ArrowFunctionExpression.Body, literals and
+// position data. The result has the same dynamic type as node and shares no
+// pointers with it, so it can safely be added to a tree a second time.
+//
+// LiteralValueNull and LiteralValueUndefined are singletons and are returned
+// as is.
+func Clone(node JSElement) JSElement {
+	if node == nil {
+		return nil
+	}
+	return cloneValue(reflect.ValueOf(node)).Interface().(JSElement)
+}
+
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		return cloneValue(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() || v.Type().Elem().Size() == 0 {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(cloneValue(v.Elem()))
+		return out
+	case reflect.Struct:
+		if v.Type() == bigFloatType {
+			f := new(big.Float).Copy(bigFloatValue(v))
+			return reflect.ValueOf(LiteralValueBigFloat(*f))
+		}
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			out.Field(i).Set(cloneValue(v.Field(i)))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,45 @@
+package goesprima
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	arrow := &ArrowFunctionExpression{
+		Async:  true,
+		Params: []FunctionParameter{&Identifier{Name: "event"}},
+		Body: BlockStatement{
+			Items: []Statement{
+				&ReturnStatement{
+					Argument: &BinaryExpression{
+						Left:     NumberLiteral(big.NewFloat(1.5)),
+						Operator: BinaryOperatorADD,
+						Right:    &Identifier{Name: "offset", Node: &Node{Range: &Range{Start: 10, End: 16}}},
+					},
+				},
+			},
+		},
+	}
+
+	clone := Clone(arrow).(*ArrowFunctionExpression)
+	assert.True(t, Equal(arrow, clone, EqualOptions{}))
+	assert.Equal(t, arrow.String(), clone.String())
+
+	ret := clone.Body.Items[0].(*ReturnStatement)
+	bin := ret.Argument.(*BinaryExpression)
+	assert.NotSame(t, arrow.Body.Items[0], ret)
+	assert.NotSame(t, arrow.Params[0], clone.Params[0])
+
+	bin.Right.(*Identifier).Node.Range.Start = 0
+	assert.Equal(t, 10, arrow.Body.Items[0].(*ReturnStatement).Argument.(*BinaryExpression).Right.(*Identifier).Node.Range.Start)
+	assert.False(t, Equal(arrow, clone, EqualOptions{}))
+	assert.True(t, Equal(arrow, clone, EqualOptions{IgnoreLocations: true}))
+
+	(*big.Float)(bin.Left.(*LiteralValueBigFloat)).SetInt64(2)
+	assert.False(t, Equal(arrow, clone, EqualOptions{IgnoreLocations: true}))
+
+	assert.Same(t, LiteralValueNull, Clone(LiteralValueNull))
+}
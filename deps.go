@@ -0,0 +1,217 @@
+package goesprima
+
+// DependencyKind tells how a module is loaded.
+type DependencyKind string
+
+const (
+	DependencyImport        DependencyKind = "import"
+	DependencyReExport      DependencyKind = "re-export"
+	DependencyDynamicImport DependencyKind = "dynamic-import"
+	DependencyRequire       DependencyKind = "require"
+	DependencyWorker        DependencyKind = "worker"
+)
+
+// A Dependency is a module loaded by a program.
+type Dependency struct {
+	Kind DependencyKind
+	// Source is the module specifier, empty if it isn't a literal.
+	Source string
+	// NonLiteral marks specifiers computed at runtime, eg. import(name).
+	NonLiteral bool
+	// Specifier is the specifier expression of calls.
+	Specifier Expression
+	// Names imported from the module, if known.
+	Names []ImportedName
+	// Node is the declaration, call or new expression loading the module.
+	Node     JSElement
+	Location *SourceLocation
+}
+
+// An ImportedName is a name imported from a module. Imported is "default"
+// for default imports and "*" for the whole module. For re-exports, Local
+// is the name exported by the program.
+type ImportedName struct {
+	Imported string
+	Local    string
+}
+
+// Dependencies returns the modules loaded by program in source order:
+// static imports, re-exports, import() calls, require() calls of the
+// global require, and workers created with new Worker(new URL(...)).
+//
+// Names of calls are known when their result initializes a declaration,
+// as in const { a } = require("m") or const ns = await import("m").
+func Dependencies(program *Program) (out []Dependency) {
+	m := Analyze(program, ScopeOptions{})
+	global := func(e Expression, name string) bool {
+		id, ok := e.(*Identifier)
+		if !ok || id.Name != name {
+			return false
+		}
+		r := m.ReferenceOf(id)
+		return r != nil && r.Resolved == nil
+	}
+
+	names := make(map[*CallExpression][]ImportedName)
+	Inspect(program, func(n JSElement) bool {
+		if d, ok := n.(*VariableDeclarator); ok && d.Init != nil {
+			init := d.Init
+			if a, ok := init.(*AwaitExpression); ok {
+				init = a.Arguement
+			}
+			imported := "*"
+			if s, ok := init.(*StaticMemberExpression); ok {
+				if p, ok := s.Property.(*Identifier); ok {
+					init, imported = s.Object, p.Name
+				}
+			}
+			if call, ok := init.(*CallExpression); ok {
+				names[call] = bindingNames(d.ID, imported)
+			}
+		}
+		return true
+	})
+
+	Inspect(program, func(n JSElement) bool {
+		switch n := n.(type) {
+		case *ImportDeclaration:
+			dep := Dependency{Kind: DependencyImport, Source: n.Source, Node: n, Location: LocationOf(n)}
+			for _, spec := range n.Specifiers {
+				switch s := spec.(type) {
+				case *ImportDefaultSpecifier:
+					dep.Names = append(dep.Names, ImportedName{"default", s.Local.Name})
+				case *ImportNamespaceSpecifier:
+					dep.Names = append(dep.Names, ImportedName{"*", s.Local.Name})
+				case *ImportSpecifier:
+					for _, named := range s.NamedImports {
+						local := named.Imported
+						if named.Local != nil {
+							local = named.Local
+						}
+						dep.Names = append(dep.Names, ImportedName{named.Imported.Name, local.Name})
+					}
+				}
+			}
+			out = append(out, dep)
+			return false
+
+		case *ExportNamedDeclaration:
+			if n.Source == nil {
+				return true
+			}
+			dep := Dependency{Kind: DependencyReExport, Node: n, Location: LocationOf(n)}
+			dep.Source, _ = specifierValue(n.Source)
+			for _, spec := range n.Specifiers {
+				local := spec.Exported
+				if spec.Local != nil {
+					local = spec.Local
+				}
+				dep.Names = append(dep.Names, ImportedName{local.Name, spec.Exported.Name})
+			}
+			out = append(out, dep)
+			return false
+
+		case *ExportAllDeclaration:
+			dep := Dependency{Kind: DependencyReExport, Node: n, Location: LocationOf(n)}
+			dep.Source, _ = specifierValue(n.Source)
+			dep.Names = []ImportedName{{Imported: "*"}}
+			if n.Exported != nil {
+				dep.Names[0].Local = n.Exported.Name
+			}
+			out = append(out, dep)
+			return false
+
+		case *CallExpression:
+			var kind DependencyKind
+			switch {
+			case len(n.Arguments) == 0:
+				return true
+			case isImport(n.Callee):
+				kind = DependencyDynamicImport
+			case global(n.Callee, "require"):
+				kind = DependencyRequire
+			default:
+				return true
+			}
+			out = append(out, callDependency(kind, n, n.Arguments[0], names[n]))
+
+		case *NewExpression:
+			if len(n.Arguments) == 0 || !global(n.Callee, "Worker") && !global(n.Callee, "SharedWorker") {
+				return true
+			}
+			arg := n.Arguments[0]
+			if url, ok := arg.(*NewExpression); ok && global(url.Callee, "URL") && len(url.Arguments) > 0 {
+				arg = url.Arguments[0]
+			}
+			out = append(out, callDependency(DependencyWorker, n, arg, nil))
+		}
+		return true
+	})
+	return
+}
+
+func isImport(e Expression) bool {
+	_, ok := e.(*Import)
+	return ok
+}
+
+func callDependency(kind DependencyKind, node Expression, arg ArgumentListElement, names []ImportedName) Dependency {
+	dep := Dependency{Kind: kind, Names: names, Node: node, Location: LocationOf(node)}
+	if e, ok := arg.(Expression); ok {
+		dep.Specifier = e
+		var literal bool
+		dep.Source, literal = specifierValue(e)
+		dep.NonLiteral = !literal
+	} else {
+		dep.NonLiteral = true
+	}
+	return dep
+}
+
+// specifierValue returns the value of a string literal or a template
+// literal without substitutions.
+func specifierValue(e JSElement) (string, bool) {
+	switch v := e.(type) {
+	case *LiteralValueString:
+		return string(*v), true
+	case *TemplateLiteral:
+		if len(v.Expressions) == 0 && len(v.Quasis) == 1 {
+			return v.Quasis[0].Cooked, true
+		}
+	}
+	return "", false
+}
+
+// bindingNames returns the names bound by a pattern initialized with the
+// module value imported.
+func bindingNames(target BindingIdentifierOrPattern, imported string) (out []ImportedName) {
+	switch p := target.(type) {
+	case *Identifier:
+		return []ImportedName{{imported, p.Name}}
+	case *ObjectPattern:
+		if imported != "*" {
+			return nil
+		}
+		for _, prop := range p.Properties {
+			pp, ok := prop.(*PropertyPattern)
+			if !ok || pp.Computed {
+				continue
+			}
+			var key string
+			switch k := pp.Key.(type) {
+			case *Identifier:
+				key = k.Name
+			case *LiteralValueString:
+				key = string(*k)
+			default:
+				continue
+			}
+			local := key
+			if ids := patternIdentifiers(pp); len(ids) == 1 {
+				local = ids[0].Name
+			}
+			out = append(out, ImportedName{key, local})
+		}
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencies(t *testing.T) {
+	prog, err := Parse("app.js", `import Amplify, { Auth as A } from "@aws-amplify/core";
+import "./polyfill.js";
+export { a as b, c } from "./re.js";
+export * from "./all.js";
+const fs = require("fs");
+const { join, resolve: res } = require("path");
+const ns = await import("./lazy.js");
+import(`+"`./pages/${page}.js`"+`);
+const worker = new Worker(new URL("./worker.js", import.meta.url));
+function local(require) {
+  return require("not-a-dependency");
+}`)
+	assert.NoError(t, err)
+	deps := Dependencies(prog)
+
+	type dep struct {
+		Kind       DependencyKind
+		Source     string
+		NonLiteral bool
+		Names      []ImportedName
+	}
+	var got []dep
+	for _, d := range deps {
+		got = append(got, dep{d.Kind, d.Source, d.NonLiteral, d.Names})
+	}
+	assert.Equal(t, []dep{
+		{DependencyImport, "@aws-amplify/core", false, []ImportedName{{"default", "Amplify"}, {"Auth", "A"}}},
+		{DependencyImport, "./polyfill.js", false, nil},
+		{DependencyReExport, "./re.js", false, []ImportedName{{"a", "b"}, {"c", "c"}}},
+		{DependencyReExport, "./all.js", false, []ImportedName{{"*", ""}}},
+		{DependencyRequire, "fs", false, []ImportedName{{"*", "fs"}}},
+		{DependencyRequire, "path", false, []ImportedName{{"join", "join"}, {"resolve", "res"}}},
+		{DependencyDynamicImport, "./lazy.js", false, []ImportedName{{"*", "ns"}}},
+		{DependencyDynamicImport, "", true, nil},
+		{DependencyWorker, "./worker.js", false, nil},
+	}, got)
+
+	assert.Equal(t, &SourceLocation{Start: Position{8, 0}, End: Position{8, 28}, Source: "app.js"}, deps[7].Location)
+	assert.Equal(t, "`./pages/${page}.js`", deps[7].Specifier.String())
+	assert.Equal(t, `export { a as b, c } from "./re.js";`, prog.Body[2].String())
+	assert.Equal(t, `import("./lazy.js")`, deps[6].Node.String())
+}
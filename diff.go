@@ -0,0 +1,248 @@
+package goesprima
+
+import (
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// Change describes a single structural difference between two trees.
+// Path locates the changed subtree from the root, eg.
+// Body[3].Declarations[0].Init.Properties[2]. Old is nil for insertions and
+// New is nil for deletions. Deletions are indexed by their position in the
+// old tree, everything else by its position in the new tree.
+type Change struct {
+	Path string
+	Old  JSElement
+	New  JSElement
+}
+
+// Diff compares two trees structurally, ignoring position data, and reports
+// the smallest subtrees that differ between them.
+func Diff(a, b JSElement) []Change {
+	d := new(differ)
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return d.changes
+}
+
+var diffOptions = EqualOptions{IgnoreLocations: true}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) emit(path string, a, b reflect.Value) {
+	d.changes = append(d.changes, Change{
+		Path: path,
+		Old:  valueToElement(a),
+		New:  valueToElement(b),
+	})
+}
+
+func (d *differ) diff(path string, a, b reflect.Value) {
+	a, b = unwrapInterface(a), unwrapInterface(b)
+	if isNilValue(a) && isNilValue(b) {
+		return
+	}
+	if isNilValue(a) || isNilValue(b) || a.Type() != b.Type() {
+		d.emit(path, a, b)
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.Pointer() == b.Pointer() {
+			return
+		}
+		if isLeafType(a.Type().Elem()) {
+			if !equalValue(a, b, diffOptions) {
+				d.emit(path, a, b)
+			}
+			return
+		}
+		d.diffStruct(path, a, b, a.Elem(), b.Elem())
+	case reflect.Struct:
+		d.diffStruct(path, a, b, a, b)
+	case reflect.Slice:
+		d.diffSlice(path, a, b)
+	default:
+		if !equalValue(a, b, diffOptions) {
+			d.emit(path, a, b)
+		}
+	}
+}
+
+// diffStruct reports the whole node when its own scalar fields differ,
+// otherwise it descends into the children.
+func (d *differ) diffStruct(path string, a, b, sa, sb reflect.Value) {
+	if sa.Kind() != reflect.Struct || isLeafType(sa.Type()) {
+		if !equalValue(sa, sb, diffOptions) {
+			d.emit(path, a, b)
+		}
+		return
+	}
+
+	t := sa.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isPositionField(f) || isChildType(f.Type) {
+			continue
+		}
+		if !equalValue(sa.Field(i), sb.Field(i), diffOptions) {
+			d.emit(path, a, b)
+			return
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isPositionField(f) || !isChildType(f.Type) {
+			continue
+		}
+		d.diff(joinPath(path, f.Name), sa.Field(i), sb.Field(i))
+	}
+}
+
+// diffSlice aligns both slices on their longest common subsequence so that a
+// single insertion does not show up as a change to every following element.
+func (d *differ) diffSlice(path string, a, b reflect.Value) {
+	n, m := a.Len(), b.Len()
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case equalValue(a.Index(i), b.Index(j), diffOptions):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var removed, added []int
+	flush := func() {
+		for len(removed) > 0 && len(added) > 0 {
+			d.diff(indexPath(path, added[0]), a.Index(removed[0]), b.Index(added[0]))
+			removed, added = removed[1:], added[1:]
+		}
+		for _, i := range removed {
+			d.emit(indexPath(path, i), a.Index(i), reflect.Value{})
+		}
+		for _, j := range added {
+			d.emit(indexPath(path, j), reflect.Value{}, b.Index(j))
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && equalValue(a.Index(i), b.Index(j), diffOptions):
+			flush()
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			added = append(added, j)
+			j++
+		default:
+			removed = append(removed, i)
+			i++
+		}
+	}
+	flush()
+}
+
+// Reflection helpers
+
+var (
+	jsElementType = reflect.TypeOf((*JSElement)(nil)).Elem()
+	nodeType      = reflect.TypeOf((*Node)(nil))
+	rangeType     = reflect.TypeOf((*Range)(nil))
+	bigFloatType  = reflect.TypeOf(LiteralValueBigFloat{})
+)
+
+// isPositionField reports whether a struct field only carries location data.
+func isPositionField(f reflect.StructField) bool {
+	return f.Type == nodeType || f.Type == rangeType
+}
+
+// isLeafType reports whether values of t are compared as a whole rather than
+// field by field, eg. literal values.
+func isLeafType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return t == bigFloatType || t.NumField() == 0
+	case reflect.Interface, reflect.Slice, reflect.Ptr:
+		return false
+	}
+	return true
+}
+
+// isChildType reports whether a field of type t holds subtrees rather than
+// scalar attributes of the node.
+func isChildType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Slice:
+		return true
+	case reflect.Ptr:
+		return !isLeafType(t.Elem())
+	case reflect.Struct:
+		return !isLeafType(t)
+	}
+	return false
+}
+
+func bigFloatValue(v reflect.Value) *big.Float {
+	f := big.Float(v.Interface().(LiteralValueBigFloat))
+	return &f
+}
+
+func unwrapInterface(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v
+}
+
+func isNilValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// valueToElement returns v as a JSElement, taking the address of value-typed
+// nodes such as BlockStatement where needed.
+func valueToElement(v reflect.Value) JSElement {
+	v = unwrapInterface(v)
+	if isNilValue(v) || !v.CanInterface() {
+		return nil
+	}
+	if v.Type().Implements(jsElementType) {
+		return v.Interface().(JSElement)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(jsElementType) {
+		return v.Addr().Interface().(JSElement)
+	}
+	return nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
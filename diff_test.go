@@ -0,0 +1,67 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diffTestProgram(keys ...string) *Program {
+	props := make([]ObjectExpressionProperty, len(keys))
+	for i, k := range keys {
+		props[i] = &Property{
+			Key:   &Identifier{Name: k},
+			Value: StringLiteral(k),
+		}
+	}
+	return &Program{
+		Body: []StatementListItem{
+			&VariableDeclaration{
+				Kind: VariableDeclarationTypeConst,
+				Declarations: []VariableDeclarator{
+					{
+						ID:   &Identifier{Name: "config"},
+						Init: &ObjectExpression{Properties: props},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := diffTestProgram("region", "userPoolId", "storage")
+	b := diffTestProgram("region", "userPoolId", "storage")
+	b.Body[0].(*VariableDeclaration).Declarations[0].ID.(*Identifier).Node = &Node{Range: &Range{Start: 6, End: 12}}
+	assert.Empty(t, Diff(a, b))
+
+	b = diffTestProgram("region", "endpoint", "storage", "API")
+	changes := Diff(a, b)
+	assert.Len(t, changes, 3)
+
+	assert.Equal(t, "Body[0].Declarations[0].Init.Properties[1].Key", changes[0].Path)
+	assert.Equal(t, "userPoolId", changes[0].Old.String())
+	assert.Equal(t, "endpoint", changes[0].New.String())
+
+	assert.Equal(t, "Body[0].Declarations[0].Init.Properties[1].Value", changes[1].Path)
+	assert.Equal(t, `"userPoolId"`, changes[1].Old.String())
+	assert.Equal(t, `"endpoint"`, changes[1].New.String())
+
+	assert.Equal(t, "Body[0].Declarations[0].Init.Properties[3]", changes[2].Path)
+	assert.Nil(t, changes[2].Old)
+	assert.Equal(t, `API: "API"`, changes[2].New.String())
+}
+
+func TestDiffScalarField(t *testing.T) {
+	a := &ArrowFunctionExpression{Body: BlockStatement{Items: []Statement{&ReturnStatement{}}}}
+	b := &ArrowFunctionExpression{Body: BlockStatement{Items: []Statement{&ReturnStatement{}}}, Async: true}
+	changes := Diff(a, b)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "", changes[0].Path)
+
+	b = &ArrowFunctionExpression{Body: BlockStatement{}}
+	changes = Diff(a, b)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "Body.Items[0]", changes[0].Path)
+	assert.Nil(t, changes[0].New)
+}
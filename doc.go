@@ -0,0 +1,341 @@
+package goesprima
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// The width-aware printer builds a document of the output, after Wadler's
+// "A prettier printer" and the doc builders of prettier, and lays it out in
+// PrintWidth columns. Groups are printed on one line if they fit and with
+// all their lines broken otherwise.
+type doc interface {
+	// breaks reports whether the document contains a forced line break,
+	// which breaks the enclosing groups.
+	breaks() bool
+}
+
+// docText is printed verbatim.
+type docText string
+
+type docConcat struct {
+	parts []doc
+	hard  bool
+}
+
+// docLine is a space, or nothing if soft, when its group is flat, and a
+// line break otherwise. Hard lines always break.
+type docLine struct {
+	soft, hard bool
+}
+
+// docGroup is printed flat if it fits. A group with states is printed as
+// the first of them that fits, the last one broken if none does.
+type docGroup struct {
+	contents doc
+	states   []doc
+	brk      bool
+}
+
+// docIndent indents the lines broken in its contents.
+type docIndent struct {
+	contents doc
+}
+
+// docIfBreak is broken if the enclosing group is broken and flat
+// otherwise.
+type docIfBreak struct {
+	broken, flat doc
+}
+
+// docIndentIfBreak indents its contents if group was broken.
+type docIndentIfBreak struct {
+	group    *docGroup
+	contents doc
+}
+
+// docBreakParent breaks the enclosing groups.
+type docBreakParent struct{}
+
+// docMark records the source location of node for the source map.
+type docMark struct {
+	node JSElement
+}
+
+// docASI marks the end of a statement without a semicolon.
+type docASI struct{}
+
+var (
+	line        = docLine{}
+	softline    = docLine{soft: true}
+	hardline    = docLine{hard: true}
+	breakParent = docBreakParent{}
+)
+
+func (docText) breaks() bool             { return false }
+func (d *docConcat) breaks() bool        { return d.hard }
+func (d docLine) breaks() bool           { return d.hard }
+func (d *docGroup) breaks() bool         { return d.brk || d.contents.breaks() }
+func (d *docIndent) breaks() bool        { return d.contents.breaks() }
+func (d *docIndentIfBreak) breaks() bool { return d.contents.breaks() }
+func (docBreakParent) breaks() bool      { return true }
+func (docMark) breaks() bool             { return false }
+func (docASI) breaks() bool              { return false }
+func (d *docIfBreak) breaks() bool {
+	return d.broken != nil && d.broken.breaks() || d.flat != nil && d.flat.breaks()
+}
+
+var (
+	_ doc = docText("")
+	_ doc = &docConcat{}
+	_ doc = docLine{}
+	_ doc = &docGroup{}
+	_ doc = &docIndent{}
+	_ doc = &docIfBreak{}
+	_ doc = &docIndentIfBreak{}
+	_ doc = docBreakParent{}
+	_ doc = docMark{}
+	_ doc = docASI{}
+)
+
+func cat(parts ...doc) *docConcat {
+	d := &docConcat{parts: parts}
+	for _, p := range parts {
+		if p.breaks() {
+			d.hard = true
+			break
+		}
+	}
+	return d
+}
+
+// group returns a group of contents, broken if brk is set or contents
+// contains a forced break.
+func group(contents doc, brk bool) *docGroup {
+	return &docGroup{contents: contents, brk: brk || contents.breaks()}
+}
+
+// conditionalGroup returns a group printed as the first of states that
+// fits. Forced breaks in the states don't break it, and only those of the
+// first state break the enclosing groups.
+func conditionalGroup(states ...doc) *docGroup {
+	return &docGroup{contents: states[0], states: states}
+}
+
+// ifBreak returns a document printing broken in a broken group and flat
+// otherwise. Either can be nil.
+func ifBreak(broken, flat doc) *docIfBreak {
+	return &docIfBreak{broken: broken, flat: flat}
+}
+
+// indentIfBreak returns contents indented if g, printed before it, is
+// broken.
+func indentIfBreak(g *docGroup, contents doc) *docIndentIfBreak {
+	return &docIndentIfBreak{group: g, contents: contents}
+}
+
+// isEmptyDoc reports whether d prints nothing.
+func isEmptyDoc(d doc) bool {
+	switch d := d.(type) {
+	case docText:
+		return d == ""
+	case *docConcat:
+		for _, p := range d.parts {
+			if !isEmptyDoc(p) {
+				return false
+			}
+		}
+		return true
+	case *docGroup:
+		return isEmptyDoc(d.contents)
+	case *docIndent:
+		return isEmptyDoc(d.contents)
+	case *docIndentIfBreak:
+		return isEmptyDoc(d.contents)
+	case docLine, *docIfBreak:
+		return false
+	}
+	return true
+}
+
+// docCmd is a document to lay out at an indentation level, flat or broken.
+type docCmd struct {
+	ind  int
+	flat bool
+	doc  doc
+}
+
+// render lays out d, writing it with emit and breakLine.
+func (p *printer) render(d doc) {
+	unit := textWidth(strings.ReplaceAll(p.unit, "\t", "  "))
+	pos := 0
+	// remeasure is set after a hard line break in a flat group, whose
+	// following groups are measured again.
+	remeasure := false
+	// broken records how the groups were printed, for indentIfBreak.
+	broken := make(map[*docGroup]bool)
+	cmds := []docCmd{{doc: d}}
+	push := func(ind int, flat bool, d doc) {
+		if d != nil {
+			cmds = append(cmds, docCmd{ind, flat, d})
+		}
+	}
+	for len(cmds) > 0 && p.err == nil {
+		c := cmds[len(cmds)-1]
+		cmds = cmds[:len(cmds)-1]
+		switch d := c.doc.(type) {
+		case docText:
+			if d == "" {
+				continue
+			}
+			p.emit(string(d))
+			if i := strings.LastIndexByte(string(d), '\n'); i >= 0 {
+				pos = textWidth(string(d[i+1:]))
+			} else {
+				pos += textWidth(string(d))
+			}
+
+		case *docConcat:
+			for i := len(d.parts) - 1; i >= 0; i-- {
+				push(c.ind, c.flat, d.parts[i])
+			}
+
+		case *docIndent:
+			push(c.ind+1, c.flat, d.contents)
+
+		case *docIndentIfBreak:
+			if broken[d.group] {
+				push(c.ind+1, c.flat, d.contents)
+			} else {
+				push(c.ind, c.flat, d.contents)
+			}
+
+		case *docIfBreak:
+			if c.flat {
+				push(c.ind, c.flat, d.flat)
+			} else {
+				push(c.ind, c.flat, d.broken)
+			}
+
+		case docLine:
+			if c.flat && !d.hard {
+				if !d.soft {
+					p.emit(" ")
+					pos++
+				}
+				continue
+			}
+			if c.flat {
+				remeasure = true
+			}
+			p.depth = c.ind
+			p.breakLine()
+			pos = c.ind * unit
+
+		case *docGroup:
+			if c.flat && !remeasure {
+				broken[d] = d.brk
+				push(c.ind, !d.brk, d.contents)
+				continue
+			}
+			remeasure = false
+			broken[d] = false
+			if !d.brk && p.fits(docCmd{c.ind, true, d.contents}, cmds, p.PrintWidth-pos) {
+				push(c.ind, true, d.contents)
+				continue
+			}
+			if d.states == nil {
+				broken[d] = true
+				push(c.ind, false, d.contents)
+				continue
+			}
+			last := d.states[len(d.states)-1]
+			if !d.brk {
+				found := false
+				for _, s := range d.states[1:] {
+					if p.fits(docCmd{c.ind, true, s}, cmds, p.PrintWidth-pos) {
+						push(c.ind, true, s)
+						found = true
+						break
+					}
+				}
+				if found {
+					continue
+				}
+			}
+			broken[d] = true
+			push(c.ind, false, last)
+
+		case docMark:
+			if p.sm != nil {
+				p.sm.enter(d.node)
+			}
+
+		case docASI:
+			p.asi = true
+		}
+	}
+}
+
+// fits reports whether next, followed by the rest of the commands up to
+// the first line break, fits in width columns.
+func (p *printer) fits(next docCmd, rest []docCmd, width int) bool {
+	cmds := append(p.fitsCmds[:0], next)
+	defer func() { p.fitsCmds = cmds[:0] }()
+	r := len(rest)
+	for width >= 0 {
+		if len(cmds) == 0 {
+			if r == 0 {
+				return true
+			}
+			r--
+			cmds = append(cmds, rest[r])
+			continue
+		}
+		c := cmds[len(cmds)-1]
+		cmds = cmds[:len(cmds)-1]
+		switch d := c.doc.(type) {
+		case docText:
+			if i := strings.IndexByte(string(d), '\n'); i >= 0 {
+				return width-textWidth(string(d[:i])) >= 0
+			}
+			width -= textWidth(string(d))
+		case *docConcat:
+			for i := len(d.parts) - 1; i >= 0; i-- {
+				cmds = append(cmds, docCmd{c.ind, c.flat, d.parts[i]})
+			}
+		case *docIndent:
+			cmds = append(cmds, docCmd{c.ind, c.flat, d.contents})
+		case *docIndentIfBreak:
+			cmds = append(cmds, docCmd{c.ind, c.flat, d.contents})
+		case *docIfBreak:
+			b := d.broken
+			if c.flat {
+				b = d.flat
+			}
+			if b != nil {
+				cmds = append(cmds, docCmd{c.ind, c.flat, b})
+			}
+		case docLine:
+			if !c.flat || d.hard {
+				return true
+			}
+			if !d.soft {
+				width--
+			}
+		case *docGroup:
+			flat := c.flat && !d.brk
+			contents := d.contents
+			if !flat && d.states != nil {
+				contents = d.states[len(d.states)-1]
+			}
+			cmds = append(cmds, docCmd{c.ind, flat, contents})
+		}
+	}
+	return false
+}
+
+// textWidth returns the number of columns of s.
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,35 @@
+package goesprima
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	render := func(width int, d doc) string {
+		var sb strings.Builder
+		newPrinter(&sb, &PrinterConfig{PrintWidth: width}).render(d)
+		return sb.String()
+	}
+	list := group(cat(docText("["), &docIndent{cat(softline, docText("a,"), line, docText("b"))}, softline, docText("]")), false)
+	assert.Equal(t, "[a, b]", render(6, list))
+	assert.Equal(t, "[\n  a,\n  b\n]", render(5, list))
+	// the rest of the line counts
+	assert.Equal(t, "[\n  a,\n  b\n]x", render(6, cat(list, docText("x"))))
+	assert.Equal(t, "[a, b]\nx", render(6, cat(list, hardline, docText("x"))))
+
+	// forced breaks break the enclosing groups
+	brk := group(cat(docText("["), &docIndent{cat(softline, docText("a"), breakParent)}, softline, docText("]")), false)
+	assert.Equal(t, "[\n  a\n]", render(80, brk))
+
+	states := conditionalGroup(docText("long"), docText("ok"), docText("last"))
+	assert.Equal(t, "long", render(4, states))
+	assert.Equal(t, "ok", render(3, states))
+	assert.Equal(t, "last", render(1, states))
+
+	g := group(&docIndent{line}, false)
+	assert.Equal(t, "x = y", render(5, cat(docText("x ="), g, indentIfBreak(g, docText("y")))))
+	assert.Equal(t, "x =\n  y", render(4, cat(docText("x ="), g, indentIfBreak(g, docText("y")))))
+}
@@ -0,0 +1,203 @@
+package goesprima
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DumpOptions controls the output of Fprint.
+type DumpOptions struct {
+	// Positions prints the source location and range of every node that has
+	// them.
+	Positions bool
+	// OmitEmpty skips nil children, empty lists and zero valued attributes.
+	OmitEmpty bool
+}
+
+// Fprint writes an indented tree of node types, field names and literal
+// values of node to w, similar to go/ast.Fprint. Unlike String it shows the
+// actual structure, eg. whether a key is a Property or a PropertyPattern or
+// whether an Optional flag is set.
+func Fprint(w io.Writer, node JSElement, opts DumpOptions) error {
+	d := &dumper{
+		w:       w,
+		opts:    opts,
+		visited: make(map[uintptr]bool),
+	}
+	d.value(reflect.ValueOf(node))
+	d.printf("\n")
+	return d.err
+}
+
+type dumper struct {
+	w       io.Writer
+	opts    DumpOptions
+	depth   int
+	err     error
+	visited map[uintptr]bool
+}
+
+func (d *dumper) printf(format string, args ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, format, args...)
+}
+
+func (d *dumper) newline() {
+	d.printf("\n%s", strings.Repeat("  ", d.depth))
+}
+
+func (d *dumper) value(v reflect.Value) {
+	v = unwrapInterface(v)
+	if isNilValue(v) {
+		d.printf("nil")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if leaf, ok := dumpLeaf(v.Elem()); ok {
+			if leaf == "" {
+				// LiteralValueNull and LiteralValueUndefined
+				d.printf("%s", strings.ToUpper(v.Type().Elem().Name()[:1])+v.Type().Elem().Name()[1:])
+				return
+			}
+			d.printf("*%s %s", v.Type().Elem().Name(), leaf)
+			return
+		}
+		if d.visited[v.Pointer()] {
+			d.printf("*%s (cycle)", v.Type().Elem().Name())
+			return
+		}
+		d.visited[v.Pointer()] = true
+		d.printf("*")
+		d.structure(v.Elem())
+		delete(d.visited, v.Pointer())
+	case reflect.Struct:
+		if leaf, ok := dumpLeaf(v); ok {
+			d.printf("%s %s", v.Type().Name(), leaf)
+			return
+		}
+		d.structure(v)
+	case reflect.Slice:
+		d.printf("%s (len = %d) {", dumpTypeName(v.Type()), v.Len())
+		if v.Len() > 0 {
+			d.depth++
+			for i := 0; i < v.Len(); i++ {
+				d.newline()
+				d.printf("%d: ", i)
+				d.value(v.Index(i))
+			}
+			d.depth--
+			d.newline()
+		}
+		d.printf("}")
+	case reflect.String:
+		d.printf("%s", strconv.Quote(v.String()))
+	default:
+		d.printf("%v", v.Interface())
+	}
+}
+
+func (d *dumper) structure(v reflect.Value) {
+	t := v.Type()
+	d.printf("%s {", t.Name())
+	d.depth++
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isPositionField(f) {
+			continue
+		}
+		fv := v.Field(i)
+		if d.opts.OmitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		d.newline()
+		d.printf("%s: ", f.Name)
+		d.value(fv)
+	}
+	if d.opts.Positions {
+		if pos := dumpPosition(v); pos != "" {
+			d.newline()
+			d.printf("Pos: %s", pos)
+		}
+	}
+	d.depth--
+	d.newline()
+	d.printf("}")
+}
+
+// dumpLeaf formats literal values that are printed on a single line.
+func dumpLeaf(v reflect.Value) (string, bool) {
+	switch {
+	case v.Type() == bigFloatType:
+		return bigFloatValue(v).String(), true
+	case v.Kind() == reflect.Struct && v.NumField() == 0:
+		return "", true
+	case v.Kind() == reflect.String:
+		return strconv.Quote(v.String()), true
+	case v.Kind() == reflect.Bool, v.Kind() == reflect.Float64:
+		return fmt.Sprint(v.Interface()), true
+	}
+	return "", false
+}
+
+func dumpTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + dumpTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + dumpTypeName(t.Elem())
+	}
+	return t.Name()
+}
+
+func dumpPosition(v reflect.Value) (s string) {
+	var n *Node
+	var r *Range
+	for i := 0; i < v.NumField(); i++ {
+		switch f := v.Field(i).Interface().(type) {
+		case *Node:
+			n = f
+		case *Range:
+			r = f
+		}
+	}
+	if n != nil {
+		if n.Location != nil {
+			l := n.Location
+			if l.Source != "" {
+				s = l.Source + ":"
+			}
+			s += fmt.Sprintf("%d:%d-%d:%d", l.Start.Line, l.Start.Column, l.End.Line, l.End.Column)
+		}
+		r = n.Range
+	}
+	if r != nil {
+		if s != "" {
+			s += " "
+		}
+		s += fmt.Sprintf("[%d, %d)", r.Start, r.End)
+	}
+	return
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package goesprima
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFprint(t *testing.T) {
+	node := &ExpressionStatement{
+		Expression: &CallExpression{
+			Callee: &Identifier{
+				Name: "configure",
+				Node: &Node{
+					Location: &SourceLocation{
+						Start: Position{Line: 1, Column: 0},
+						End:   Position{Line: 1, Column: 9},
+					},
+					Range: &Range{Start: 0, End: 9},
+				},
+			},
+			Arguments: []ArgumentListElement{
+				StringLiteral("config"),
+				LiteralValueNull,
+			},
+			Optional: true,
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Fprint(&buf, node, DumpOptions{Positions: true}))
+	assert.Equal(t, `*ExpressionStatement {
+  Expression: *CallExpression {
+    Callee: *Identifier {
+      Name: "configure"
+      Pos: 1:0-1:9 [0, 9)
+    }
+    Arguments: []ArgumentListElement (len = 2) {
+      0: *LiteralValueString "config"
+      1: LiteralValueNull
+    }
+    Optional: true
+  }
+}
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, Fprint(&buf, &ReturnStatement{}, DumpOptions{OmitEmpty: true}))
+	assert.Equal(t, "*ReturnStatement {\n}\n", buf.String())
+}
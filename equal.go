@@ -0,0 +1,70 @@
+package goesprima
+
+import "reflect"
+
+// EqualOptions controls how Equal compares two trees.
+type EqualOptions struct {
+	// IgnoreLocations skips the Node and Range position data of every node.
+	IgnoreLocations bool
+}
+
+// Equal reports whether a and b are structurally equal. Interface-typed
+// children are compared by their dynamic type and contents, never by
+// pointer identity.
+func Equal(a, b JSElement, opts EqualOptions) bool {
+	return equalValue(reflect.ValueOf(a), reflect.ValueOf(b), opts)
+}
+
+func equalValue(a, b reflect.Value, opts EqualOptions) bool {
+	a, b = unwrapInterface(a), unwrapInterface(b)
+	if isNilValue(a) || isNilValue(b) {
+		return isNilValue(a) && isNilValue(b)
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.Pointer() == b.Pointer() {
+			return true
+		}
+		return equalValue(a.Elem(), b.Elem(), opts)
+	case reflect.Struct:
+		if a.Type() == bigFloatType {
+			x, y := bigFloatValue(a), bigFloatValue(b)
+			return x.Cmp(y) == 0
+		}
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if opts.IgnoreLocations && isPositionField(t.Field(i)) {
+				continue
+			}
+			if !equalValue(a.Field(i), b.Field(i), opts) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalValue(a.Index(i), b.Index(i), opts) {
+				return false
+			}
+		}
+		return true
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() == b.Uint()
+	}
+	return false
+}
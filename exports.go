@@ -0,0 +1,200 @@
+package goesprima
+
+// ExportKind tells how a name is exported.
+type ExportKind string
+
+const (
+	// Declarations, eg. export const a = 1.
+	ExportVar      ExportKind = "var"
+	ExportLet      ExportKind = "let"
+	ExportConst    ExportKind = "const"
+	ExportFunction ExportKind = "function"
+	ExportClass    ExportKind = "class"
+	// ExportLocal exports a local binding by specifier, eg. export { a }.
+	ExportLocal ExportKind = "local"
+	// ExportExpression is the value of export default expr.
+	ExportExpression ExportKind = "expression"
+	// ExportReExport re-exports a name of another module, eg.
+	// export { a } from "./a.js".
+	ExportReExport ExportKind = "re-export"
+	// ExportNamespace re-exports another module as a namespace, eg.
+	// export * as ns from "./ns.js".
+	ExportNamespace ExportKind = "namespace"
+	// ExportStar re-exports every name of another module, eg.
+	// export * from "./all.js".
+	ExportStar ExportKind = "star"
+	// ExportCommonJS is a module.exports or exports.x assignment.
+	ExportCommonJS ExportKind = "commonjs"
+)
+
+// An Export is a name exported by a module.
+type Export struct {
+	Kind ExportKind
+	// Name is the exported name, "default" for the default export and empty
+	// for export * from.
+	Name string
+	// Local is the name of the exported binding, if any.
+	Local string
+	// Source and Imported are the module and name re-exported. Imported is
+	// "*" for namespaces and star exports.
+	Source   string
+	Imported string
+	// Node is the export declaration or CommonJS assignment.
+	Node     JSElement
+	Location *SourceLocation
+}
+
+// ModuleInterface is the export surface of a module.
+type ModuleInterface struct {
+	// Named exports in source order.
+	Named []Export
+	// Default is the default export, or nil. A CommonJS module.exports
+	// assignment is a default export.
+	Default *Export
+	// Star lists the export * from declarations.
+	Star []Export
+	// CommonJS is set when the module assigns module.exports or exports.
+	CommonJS bool
+}
+
+// Names returns the exported names, including "default".
+func (m ModuleInterface) Names() (names []string) {
+	for _, e := range m.Named {
+		names = append(names, e.Name)
+	}
+	if m.Default != nil {
+		names = append(names, m.Default.Name)
+	}
+	return
+}
+
+// Exports returns the export surface of program. Export specifiers named
+// default, as in export { a as default }, are the default export.
+//
+// Assigning an object literal to module.exports exports its
+// non-computed keys as named exports as well as the default.
+func Exports(program *Program) (mi ModuleInterface) {
+	add := func(e Export) {
+		if e.Name == "default" {
+			e := e
+			mi.Default = &e
+			return
+		}
+		mi.Named = append(mi.Named, e)
+	}
+
+	for _, item := range program.Body {
+		switch n := item.(type) {
+		case *ExportNamedDeclaration:
+			loc := LocationOf(n)
+			switch d := n.Declaration.(type) {
+			case *VariableDeclaration:
+				for _, decl := range d.Declarations {
+					for _, id := range patternIdentifiers(decl.ID) {
+						add(Export{Kind: ExportKind(d.Kind), Name: id.Name, Local: id.Name, Node: n, Location: loc})
+					}
+				}
+			case *FunctionDeclaration:
+				add(Export{Kind: ExportFunction, Name: d.ID.Name, Local: d.ID.Name, Node: n, Location: loc})
+			case *ClassDeclaration:
+				add(Export{Kind: ExportClass, Name: d.ID.Name, Local: d.ID.Name, Node: n, Location: loc})
+			}
+			source, _ := specifierValue(n.Source)
+			for _, spec := range n.Specifiers {
+				local := spec.Exported
+				if spec.Local != nil {
+					local = spec.Local
+				}
+				e := Export{Kind: ExportLocal, Name: spec.Exported.Name, Local: local.Name, Node: n, Location: LocationOf(spec)}
+				if n.Source != nil {
+					e.Kind, e.Local, e.Source, e.Imported = ExportReExport, "", source, local.Name
+				}
+				add(e)
+			}
+
+		case *ExportDefaultDeclaration:
+			e := Export{Kind: ExportExpression, Name: "default", Node: n, Location: LocationOf(n)}
+			switch d := n.Declaration.(type) {
+			case *FunctionDeclaration:
+				e.Kind = ExportFunction
+				if d.ID != nil {
+					e.Local = d.ID.Name
+				}
+			case *ClassDeclaration:
+				e.Kind = ExportClass
+				if d.ID != nil {
+					e.Local = d.ID.Name
+				}
+			case *Identifier:
+				e.Local = d.Name
+			}
+			add(e)
+
+		case *ExportAllDeclaration:
+			source, _ := specifierValue(n.Source)
+			e := Export{Kind: ExportStar, Source: source, Imported: "*", Node: n, Location: LocationOf(n)}
+			if n.Exported != nil {
+				e.Kind, e.Name = ExportNamespace, n.Exported.Name
+				add(e)
+			} else {
+				mi.Star = append(mi.Star, e)
+			}
+		}
+	}
+
+	m := Analyze(program, ScopeOptions{})
+	global := func(id *Identifier) bool {
+		r := m.ReferenceOf(id)
+		return r != nil && r.Resolved == nil
+	}
+	Inspect(program, func(n JSElement) bool {
+		a, ok := n.(*AssignmentExpression)
+		if !ok || a.Operator != AssignmentOperatorEq {
+			return true
+		}
+		root, path := memberChain(a.Left, make(map[Expression]bool))
+		if root == nil || !global(root) {
+			return true
+		}
+		var name string
+		switch {
+		case len(path) == 2 && path[0] == "module" && path[1] == "exports":
+			name = "default"
+		case len(path) == 3 && path[0] == "module" && path[1] == "exports":
+			name = path[2]
+		case len(path) == 2 && path[0] == "exports":
+			name = path[1]
+		default:
+			return true
+		}
+		mi.CommonJS = true
+		e := Export{Kind: ExportCommonJS, Name: name, Node: a, Location: LocationOf(a)}
+		if id, ok := a.Right.(*Identifier); ok {
+			e.Local = id.Name
+		}
+		add(e)
+		if obj, ok := a.Right.(*ObjectExpression); ok && name == "default" {
+			for _, prop := range obj.Properties {
+				p, ok := prop.(*Property)
+				if !ok || p.Computed {
+					continue
+				}
+				e := Export{Kind: ExportCommonJS, Node: a, Location: LocationOf(p)}
+				switch k := p.Key.(type) {
+				case *Identifier:
+					e.Name = k.Name
+				case *LiteralValueString:
+					e.Name = string(*k)
+				default:
+					continue
+				}
+				if id, ok := p.Value.(*Identifier); ok {
+					e.Local = id.Name
+				}
+				add(e)
+			}
+		}
+		return true
+	})
+	return
+}
@@ -0,0 +1,64 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExports(t *testing.T) {
+	prog, err := Parse("api.js", `const a = 1, hidden = 2;
+export const { b, c: d } = obj;
+export function run() {}
+export class Client {}
+export { a, a as alias };
+export { x as y } from "./x.js";
+export * as ns from "./ns.js";
+export * from "./all.js";
+export default run;`)
+	assert.NoError(t, err)
+	mi := Exports(prog)
+
+	type export struct {
+		Kind                          ExportKind
+		Name, Local, Source, Imported string
+	}
+	var named []export
+	for _, e := range mi.Named {
+		named = append(named, export{e.Kind, e.Name, e.Local, e.Source, e.Imported})
+	}
+	assert.Equal(t, []export{
+		{ExportConst, "b", "b", "", ""},
+		{ExportConst, "d", "d", "", ""},
+		{ExportFunction, "run", "run", "", ""},
+		{ExportClass, "Client", "Client", "", ""},
+		{ExportLocal, "a", "a", "", ""},
+		{ExportLocal, "alias", "a", "", ""},
+		{ExportReExport, "y", "", "./x.js", "x"},
+		{ExportNamespace, "ns", "", "./ns.js", "*"},
+	}, named)
+	assert.Equal(t, "./all.js", mi.Star[0].Source)
+	assert.Equal(t, ExportExpression, mi.Default.Kind)
+	assert.Equal(t, "run", mi.Default.Local)
+	assert.False(t, mi.CommonJS)
+	assert.Equal(t, []string{"b", "d", "run", "Client", "a", "alias", "y", "ns", "default"}, mi.Names())
+	assert.Equal(t, `export * as ns from "./ns.js";`, prog.Body[6].String())
+	assert.Equal(t, Position{5, 12}, mi.Named[5].Location.Start)
+}
+
+func TestExportsCommonJS(t *testing.T) {
+	prog, err := Parse("lib.js", `function parse() {}
+module.exports = { parse, version: "1.0" };
+exports.helper = function () {};
+module.exports.util = util;
+function local(exports) {
+  exports.notExported = 1;
+}`)
+	assert.NoError(t, err)
+	mi := Exports(prog)
+	assert.True(t, mi.CommonJS)
+	assert.Equal(t, ExportCommonJS, mi.Default.Kind)
+	assert.Equal(t, []string{"parse", "version", "helper", "util", "default"}, mi.Names())
+	assert.Equal(t, "parse", mi.Named[0].Local)
+	assert.Equal(t, "util", mi.Named[3].Local)
+}
@@ -0,0 +1,101 @@
+package goesprima
+
+import "strings"
+
+// FreeVariables returns the references made in node that aren't resolved
+// to a binding declared in it, in the order they appear. node is typically
+// a function or a program; references to the name of a function
+// declaration from its own body are free, as the name is bound outside it.
+func FreeVariables(node JSElement) []Reference {
+	m := Analyze(node, ScopeOptions{})
+	through := m.GlobalScope().Through
+	out := make([]Reference, len(through))
+	for i, r := range through {
+		out[i] = *r
+	}
+	return out
+}
+
+// A GlobalAccess is a member chain rooted at a free variable, such as
+// process.env.NODE_ENV.
+type GlobalAccess struct {
+	Global *Identifier
+	// Path holds the name of the global followed by the property names.
+	Path []string
+	// Node is the outermost member expression of the chain.
+	Node     Expression
+	Location *SourceLocation
+}
+
+func (g GlobalAccess) String() string {
+	return strings.Join(g.Path, ".")
+}
+
+// GlobalAccesses returns the longest static member chains rooted at the
+// free variables of node, eg. process.env.REACT_APP_REGION,
+// window.localStorage or document.cookie. Computed members with string keys
+// are part of a chain; other computed members end it.
+func GlobalAccesses(node JSElement) (out []GlobalAccess) {
+	free := make(map[*Identifier]bool)
+	for _, r := range FreeVariables(node) {
+		free[r.Identifier] = true
+	}
+	inner := make(map[Expression]bool)
+	Inspect(node, func(n JSElement) bool {
+		e, ok := n.(Expression)
+		if !ok || inner[e] {
+			return true
+		}
+		switch e.(type) {
+		case *StaticMemberExpression, *ComputedMemberExpression:
+		default:
+			return true
+		}
+		root, path := memberChain(e, inner)
+		if root != nil && free[root] {
+			out = append(out, GlobalAccess{Global: root, Path: path, Node: e, Location: LocationOf(e)})
+		}
+		return true
+	})
+	return
+}
+
+// memberChain returns the root identifier and names of a static member
+// chain, marking the member expressions it contains in inner.
+func memberChain(e Expression, inner map[Expression]bool) (*Identifier, []string) {
+	var name string
+	var object Expression
+	switch m := e.(type) {
+	case *Identifier:
+		return m, []string{m.Name}
+	case *StaticMemberExpression:
+		switch p := m.Property.(type) {
+		case *Identifier:
+			name = p.Name
+		case *CallExpression:
+			// legacy trees put calls on the property side
+			id, ok := p.Callee.(*Identifier)
+			if !ok {
+				return nil, nil
+			}
+			name = id.Name
+		default:
+			return nil, nil
+		}
+		object = m.Object
+	case *ComputedMemberExpression:
+		s, ok := m.Property.(*LiteralValueString)
+		if !ok {
+			return nil, nil
+		}
+		name, object = string(*s), m.Object
+	default:
+		return nil, nil
+	}
+	root, path := memberChain(object, inner)
+	if root == nil {
+		return nil, nil
+	}
+	inner[object] = true
+	return root, append(path, name)
+}
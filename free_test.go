@@ -0,0 +1,79 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeVariables(t *testing.T) {
+	prog, err := Parse("test.js", `import { Auth } from "@aws-amplify/auth";
+const key = "token";
+function load(id) {
+  const store = window.localStorage;
+  return fetch(url + id, { headers: { key, cookie: document.cookie } });
+}`)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, r := range FreeVariables(prog) {
+		names = append(names, r.Identifier.Name)
+	}
+	assert.Equal(t, []string{"window", "fetch", "url", "document"}, names)
+
+	names = names[:0]
+	for _, r := range FreeVariables(prog.Body[2]) {
+		names = append(names, r.Identifier.Name)
+	}
+	assert.Equal(t, []string{"window", "fetch", "url", "key", "document"}, names)
+}
+
+func TestGlobalAccesses(t *testing.T) {
+	prog, err := Parse("env.js", `export const id = process.env.REACT_APP_USER_POOL_CLIENT_ID;
+const region = process.env["REACT_APP_REGION"];
+const local = { env: 1 };
+local.env.x;
+process.env[name].length;
+window.localStorage.getItem("k");`)
+	assert.NoError(t, err)
+
+	var out []string
+	for _, a := range GlobalAccesses(prog) {
+		out = append(out, a.String())
+	}
+	assert.Equal(t, []string{
+		"process.env.REACT_APP_USER_POOL_CLIENT_ID",
+		"process.env.REACT_APP_REGION",
+		"process.env",
+		"window.localStorage.getItem",
+	}, out)
+
+	first := GlobalAccesses(prog)[0]
+	assert.Equal(t, "process", first.Global.Name)
+	assert.Equal(t, &SourceLocation{Start: Position{1, 18}, End: Position{1, 59}, Source: "env.js"}, first.Location)
+}
+
+func TestGlobalAccessesGenerator(t *testing.T) {
+	g := NewGenerator().AddStatements(
+		MustStmt("export const %[name]s = process.env.%[env]s;", TemplateArgs{
+			"name": "USER_POOL_CLIENT_ID_TODOUSERS",
+			"env":  "REACT_APP_USER_POOL_CLIENT_ID_TODOUSERS",
+		}),
+		&ExpressionStatement{
+			Expression: &StaticMemberExpression{
+				Object: &Identifier{Name: "Auth"},
+				Property: &CallExpression{
+					Callee: &Identifier{Name: "currentSession"},
+				},
+			},
+		},
+	)
+	var out []string
+	for _, a := range GlobalAccesses(g) {
+		out = append(out, a.String())
+	}
+	assert.Equal(t, []string{
+		"process.env.REACT_APP_USER_POOL_CLIENT_ID_TODOUSERS",
+		"Auth.currentSession",
+	}, out)
+}
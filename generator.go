@@ -12,6 +12,9 @@ func NewGenerator() *Generator {
 type Generator struct {
 	ModuleName string
 	Statements []StatementListItem
+	// Config controls the output of String. The default configuration is
+	// used if it is nil.
+	Config *PrinterConfig
 }
 
 func (g *Generator) AddStatements(ss ...StatementListItem) *Generator {
@@ -25,11 +28,7 @@ func (g *Generator) AddStatement(s StatementListItem) *Generator {
 }
 
 func (g *Generator) String() string {
-	s := make([]string, len(g.Statements))
-	for i, st := range g.Statements {
-		s[i] = st.String()
-	}
-	return strings.Join(s, "\n")
+	return g.Config.Sprint(g)
 }
 
 // Helper Functions
@@ -69,14 +68,6 @@ func NumberLiteral(n interface{}) Literal {
 	}
 }
 
-func jsElementsToString[T JSElement](values []T) []string {
-	out := make([]string, len(values))
-	for i, v := range values {
-		out[i] = v.String()
-	}
-	return out
-}
-
 // Indentation
 
 type Indentor interface {
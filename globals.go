@@ -0,0 +1,67 @@
+package goesprima
+
+// Globals is a set of names provided by the environment.
+type Globals map[string]bool
+
+// NewGlobals returns a set of custom globals.
+func NewGlobals(names ...string) Globals {
+	g := make(Globals, len(names))
+	for _, n := range names {
+		g[n] = true
+	}
+	return g
+}
+
+// MergeGlobals returns the union of sets.
+func MergeGlobals(sets ...Globals) Globals {
+	g := make(Globals)
+	for _, s := range sets {
+		for n, ok := range s {
+			if ok {
+				g[n] = true
+			}
+		}
+	}
+	return g
+}
+
+// ES2022Globals are the builtins of ECMAScript 2022.
+var ES2022Globals = NewGlobals(
+	"AggregateError", "Array", "ArrayBuffer", "Atomics", "BigInt", "BigInt64Array",
+	"BigUint64Array", "Boolean", "DataView", "Date", "decodeURI", "decodeURIComponent",
+	"encodeURI", "encodeURIComponent", "Error", "escape", "eval", "EvalError",
+	"FinalizationRegistry", "Float32Array", "Float64Array", "Function", "globalThis",
+	"Infinity", "Int16Array", "Int32Array", "Int8Array", "Intl", "isFinite", "isNaN",
+	"JSON", "Map", "Math", "NaN", "Number", "Object", "parseFloat", "parseInt",
+	"Promise", "Proxy", "RangeError", "ReferenceError", "Reflect", "RegExp", "Set",
+	"SharedArrayBuffer", "String", "Symbol", "SyntaxError", "TypeError", "Uint16Array",
+	"Uint32Array", "Uint8Array", "Uint8ClampedArray", "undefined", "unescape",
+	"URIError", "WeakMap", "WeakRef", "WeakSet",
+)
+
+// BrowserGlobals are the common globals of web browsers.
+var BrowserGlobals = NewGlobals(
+	"AbortController", "AbortSignal", "addEventListener", "alert", "atob", "Blob",
+	"btoa", "BroadcastChannel", "caches", "cancelAnimationFrame", "clearInterval",
+	"clearTimeout", "confirm", "console", "crypto", "CustomEvent", "document",
+	"DOMParser", "Element", "Event", "EventSource", "EventTarget", "fetch", "File",
+	"FileReader", "FormData", "getComputedStyle", "Headers", "history", "HTMLElement",
+	"Image", "indexedDB", "IntersectionObserver", "KeyboardEvent", "localStorage",
+	"location", "matchMedia", "MessageChannel", "MouseEvent", "MutationObserver",
+	"navigator", "Node", "Notification", "performance", "postMessage", "prompt",
+	"queueMicrotask", "removeEventListener", "Request", "requestAnimationFrame",
+	"requestIdleCallback", "ResizeObserver", "Response", "screen", "self",
+	"sessionStorage", "setInterval", "setTimeout", "structuredClone", "TextDecoder",
+	"TextEncoder", "URL", "URLSearchParams", "WebSocket", "window", "Worker",
+	"XMLHttpRequest",
+)
+
+// NodeGlobals are the globals of Node.js, including those of CommonJS
+// modules.
+var NodeGlobals = NewGlobals(
+	"__dirname", "__filename", "AbortController", "AbortSignal", "Buffer",
+	"clearImmediate", "clearInterval", "clearTimeout", "console", "crypto", "exports",
+	"fetch", "global", "module", "performance", "process", "queueMicrotask", "require",
+	"setImmediate", "setInterval", "setTimeout", "structuredClone", "TextDecoder",
+	"TextEncoder", "URL", "URLSearchParams",
+)
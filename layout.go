@@ -0,0 +1,964 @@
+package goesprima
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Layouts of the width-aware printer, which follow those of prettier.
+
+// layout prints node in the document of the width-aware printer if its
+// layout depends on the width, reporting whether it did.
+func (p *printer) layout(node JSElement) bool {
+	switch n := node.(type) {
+	case *ObjectExpression:
+		if len(n.Properties) == 0 {
+			p.print("{}")
+			return true
+		}
+		// objects broken after { in the source stay broken
+		brk := false
+		if start, first := LocationOf(n), LocationOf(n.Properties[0]); start != nil && first != nil {
+			brk = first.Start.Line > start.Start.Line
+		}
+		elements(p, "{", "}", n.Properties, !p.OmitBracketSpacing, p.TrailingCommas != TrailingCommaNone, brk)
+
+	case *ObjectPattern:
+		if len(n.Properties) == 0 {
+			p.print("{}")
+			return true
+		}
+		elements(p, "{", "}", n.Properties, !p.OmitBracketSpacing, p.TrailingCommas != TrailingCommaNone, false)
+
+	case *ArrayExpression:
+		if len(n.Elements) == 0 {
+			p.print("[]")
+			return true
+		}
+		elements(p, "[", "]", n.Elements, false, p.TrailingCommas != TrailingCommaNone, isMatrix(n.Elements))
+
+	case *ArrayPattern:
+		if len(n.Elements) == 0 {
+			p.print("[]")
+			return true
+		}
+		elements(p, "[", "]", n.Elements, false, p.TrailingCommas == TrailingCommaAll, false)
+
+	case *BinaryExpression, *LogicalExpression:
+		p.binary(n.(Expression), binaryIndent)
+
+	case *ConditionalExpression:
+		p.group(func() {
+			p.operand(n.Test, precConditional+1)
+			p.indent(func() {
+				p.add(line)
+				p.print("? ")
+				p.operand(n.Consequent, precAssign)
+				p.add(line)
+				p.print(": ")
+				p.operand(n.Alternate, precAssign)
+			})
+		})
+
+	case *CallExpression:
+		return p.memberChain(n)
+
+	case *ArrowFunctionExpression:
+		if n.Expression == nil {
+			return false
+		}
+		p.arrow(n)
+
+	case *AssignmentExpression:
+		p.assignment(func() { p.node(n.Left) }, " "+string(n.Operator), n.Right, false)
+
+	case *VariableDeclarator:
+		if n.Init == nil {
+			return false
+		}
+		p.assignment(func() { p.node(n.ID) }, " =", n.Init, false)
+
+	case *Property:
+		if _, ok := n.Value.(*FunctionExpression); ok && (n.Method || n.Kind == "get" || n.Kind == "set") {
+			return false
+		}
+		if n.Value == nil || n.ShortHand && isShorthand(n) {
+			return false
+		}
+		p.assignment(func() { p.key(n.Key, n.Computed) }, ":", n.Value, p.isShortKey(n.Key, n.Computed))
+
+	case *PropertyDefinition:
+		if n.Value == nil {
+			return false
+		}
+		if n.Static {
+			p.print("static ")
+		}
+		p.assignment(func() { p.key(n.Key, n.Computed) }, " =", n.Value, false)
+		p.semicolon()
+
+	case *ReturnStatement:
+		if n.Argument == nil {
+			return false
+		}
+		p.print("return")
+		p.argument(n.Argument)
+		p.semicolon()
+
+	case *ThrowStatement:
+		p.print("throw")
+		p.argument(n.Argument)
+		p.semicolon()
+
+	default:
+		return false
+	}
+	return true
+}
+
+// elements prints a bracketed list on one line if it fits and with an
+// element per line otherwise, or always if brk is set. Nil elements are
+// holes.
+func elements[T JSElement](p *printer, open, close string, list []T, spaced, comma, brk bool) {
+	inner := softline
+	if spaced {
+		inner = line
+	}
+	contents := p.capture(func() {
+		p.print(open)
+		p.indent(func() {
+			p.add(inner)
+			join(p, list)
+			switch last := JSElement(list[len(list)-1]); {
+			case last == nil:
+				// a trailing hole needs its comma
+				p.print(",")
+			case comma && !isRest(last):
+				p.add(ifBreak(docText(","), nil))
+			}
+		})
+		p.add(inner)
+		p.print(close)
+	})
+	p.add(group(contents, brk))
+}
+
+// join prints the elements of list separated by commas and lines.
+func join[T JSElement](p *printer, list []T) {
+	for i, n := range list {
+		if i > 0 {
+			p.print(",")
+			p.add(line)
+		}
+		p.element(n)
+	}
+}
+
+// isMatrix reports whether elements are objects or arrays of more than one
+// element each, which prettier prints one per line.
+func isMatrix(elements []ArrayExpressionElement) bool {
+	if len(elements) < 2 {
+		return false
+	}
+	for _, e := range elements {
+		switch v := e.(type) {
+		case *ObjectExpression:
+			if len(v.Properties) < 2 {
+				return false
+			}
+		case *ArrayExpression:
+			if len(v.Elements) < 2 {
+				return false
+			}
+		default:
+			return false
+		}
+		if reflect.TypeOf(e) != reflect.TypeOf(elements[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// specifiers prints import or export specifiers between braces.
+func specifiers[T JSElement](p *printer, specs []T) {
+	if p.parts == nil {
+		p.braces(func() { list(p, specs, ", ") })
+		return
+	}
+	elements(p, "{", "}", specs, !p.OmitBracketSpacing, p.TrailingCommas != TrailingCommaNone, false)
+}
+
+// arguments prints the arguments of a call. The width-aware printer hugs a
+// last argument that can expand, such as a function or an object, or a
+// first function argument, if the others fit on the line.
+func (p *printer) arguments(args []ArgumentListElement) {
+	if p.parts == nil || len(args) == 0 {
+		p.print("(")
+		list(p, args, ", ")
+		p.print(")")
+		return
+	}
+	docs := make([]doc, len(args))
+	hard := false
+	for i, a := range args {
+		docs[i] = p.capture(func() { p.element(a) })
+		hard = hard || docs[i].breaks()
+	}
+	broken := p.argumentList(docs)
+	n := len(args)
+	switch {
+	case isHookWithDeps(args):
+		p.add(flatArguments(docs))
+	case couldGroupLast(args) && !anyBreaks(docs[:n-1]):
+		hug := append(append([]doc{}, docs[:n-1]...), group(docs[n-1], true))
+		p.add(cat(p.hardIf(hard), conditionalGroup(flatArguments(docs), flatArguments(hug), group(broken, true))))
+	case couldGroupFirst(args) && !anyBreaks(docs[1:]):
+		hug := append([]doc{group(docs[0], true)}, docs[1:]...)
+		p.add(cat(p.hardIf(hard), conditionalGroup(flatArguments(hug), group(broken, true))))
+	default:
+		p.add(group(broken, false))
+	}
+}
+
+// hardIf returns a document breaking the enclosing groups if brk is set.
+func (p *printer) hardIf(brk bool) doc {
+	if brk {
+		return breakParent
+	}
+	return docText("")
+}
+
+// argumentList returns the arguments with an argument per line if broken.
+func (p *printer) argumentList(docs []doc) doc {
+	parts := []doc{docText("("), nil, softline, docText(")")}
+	var inner []doc
+	for i, d := range docs {
+		if i > 0 {
+			inner = append(inner, docText(","), line)
+		}
+		inner = append(inner, d)
+	}
+	if p.TrailingCommas == TrailingCommaAll {
+		inner = append(inner, ifBreak(docText(","), nil))
+	}
+	parts[1] = &docIndent{cat(append([]doc{softline}, inner...)...)}
+	return cat(parts...)
+}
+
+// flatArguments returns the arguments on one line.
+func flatArguments(docs []doc) doc {
+	parts := []doc{docText("(")}
+	for i, d := range docs {
+		if i > 0 {
+			parts = append(parts, docText(", "))
+		}
+		parts = append(parts, d)
+	}
+	return cat(append(parts, docText(")"))...)
+}
+
+func anyBreaks(docs []doc) bool {
+	for _, d := range docs {
+		if d.breaks() {
+			return true
+		}
+	}
+	return false
+}
+
+// isHookWithDeps reports whether args are those of a React hook such as
+// useEffect(() => {...}, [a, b]), which are never broken.
+func isHookWithDeps(args []ArgumentListElement) bool {
+	if len(args) != 2 {
+		return false
+	}
+	f, ok := args[0].(*ArrowFunctionExpression)
+	if !ok || len(f.Params) > 0 || f.Expression != nil {
+		return false
+	}
+	_, ok = args[1].(*ArrayExpression)
+	return ok
+}
+
+// couldGroupLast reports whether the last argument can be hugged.
+func couldGroupLast(args []ArgumentListElement) bool {
+	last := args[len(args)-1]
+	if len(args) > 1 && reflect.TypeOf(args[len(args)-2]) == reflect.TypeOf(last) {
+		return false
+	}
+	return couldExpand(last)
+}
+
+// couldGroupFirst reports whether the first of two arguments is a function
+// that can be hugged, as in setTimeout(() => {...}, 10).
+func couldGroupFirst(args []ArgumentListElement) bool {
+	if len(args) != 2 {
+		return false
+	}
+	switch f := args[0].(type) {
+	case *FunctionExpression:
+	case *ArrowFunctionExpression:
+		if f.Expression != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	switch args[1].(type) {
+	case *FunctionExpression, *ArrowFunctionExpression, *ConditionalExpression:
+		return false
+	}
+	return !couldExpand(args[1])
+}
+
+// couldExpand reports whether an argument can be broken while the call
+// stays on its line.
+func couldExpand(e JSElement) bool {
+	switch v := e.(type) {
+	case *ObjectExpression:
+		return len(v.Properties) > 0
+	case *ArrayExpression:
+		return len(v.Elements) > 0
+	case *FunctionExpression:
+		return true
+	case *ArrowFunctionExpression:
+		switch v.Expression.(type) {
+		case nil, *ObjectExpression, *ArrayExpression, *ArrowFunctionExpression:
+			return true
+		}
+	}
+	return false
+}
+
+// params prints the parameters of a function. The width-aware printer hugs
+// a single destructuring parameter.
+func (p *printer) params(params []FunctionParameter) {
+	if p.parts == nil || len(params) == 0 || len(params) == 1 && hugsParam(params[0]) {
+		p.print("(")
+		list(p, params, ", ")
+		p.print(")")
+		return
+	}
+	p.group(func() {
+		p.print("(")
+		p.indent(func() {
+			p.add(softline)
+			join(p, params)
+			if p.TrailingCommas == TrailingCommaAll && !isRest(params[len(params)-1]) {
+				p.add(ifBreak(docText(","), nil))
+			}
+		})
+		p.add(softline)
+		p.print(")")
+	})
+}
+
+func hugsParam(param FunctionParameter) bool {
+	switch v := param.(type) {
+	case *ObjectPattern:
+		return true
+	case *AssignmentPattern:
+		_, ok := v.Left.(*ObjectPattern)
+		return ok
+	}
+	return false
+}
+
+// condition prints the parenthesized test of a statement.
+func (p *printer) condition(e Expression) {
+	p.print("(")
+	if p.parts == nil {
+		p.node(e)
+	} else {
+		p.group(func() {
+			p.indent(func() {
+				p.add(softline)
+				p.expression(e, binaryInline)
+			})
+			p.add(softline)
+		})
+	}
+	p.print(")")
+}
+
+// argument prints the argument of a return or throw statement, between
+// parentheses if a binary or sequence expression is broken.
+func (p *printer) argument(e Expression) {
+	p.print(" ")
+	if _, ok := e.(*SequenceExpression); !ok && !isBinaryish(e) {
+		p.node(e)
+		return
+	}
+	p.group(func() {
+		p.add(ifBreak(docText("("), nil))
+		p.indent(func() {
+			p.add(softline)
+			p.expression(e, binaryGroup)
+		})
+		p.add(softline)
+		p.add(ifBreak(docText(")"), nil))
+	})
+}
+
+// assignment prints left, the operator op and right. A right side that
+// breaks badly, such as a binary expression or a string, moves to the next
+// line first, and others move if they don't start on the line.
+func (p *printer) assignment(left func(), op string, right Expression, shortKey bool) {
+	p.group(func() {
+		left()
+		p.print(op)
+		switch {
+		case breaksAfterOperator(right, shortKey):
+			p.group(func() {
+				p.indent(func() {
+					p.add(line)
+					if isBinaryish(right) {
+						p.expression(right, binaryGroup)
+					} else {
+						p.operand(right, precAssign)
+					}
+				})
+			})
+		case shortKey || neverBreaksAfterOperator(right):
+			p.print(" ")
+			p.operand(right, precAssign)
+		default:
+			g := group(&docIndent{line}, false)
+			p.add(g)
+			p.add(indentIfBreak(g, p.capture(func() { p.operand(right, precAssign) })))
+		}
+	})
+}
+
+func breaksAfterOperator(e Expression, shortKey bool) bool {
+	if isBinaryish(e) {
+		return !inlinesRight(e)
+	}
+	switch v := e.(type) {
+	case *ConditionalExpression:
+		return isBinaryish(v.Test)
+	case *SequenceExpression:
+		return true
+	}
+	if shortKey {
+		return false
+	}
+	for {
+		switch v := e.(type) {
+		case *UnaryExpression:
+			e = v.Argument
+			continue
+		case *AwaitExpression:
+			e = v.Arguement
+			continue
+		case *YieldExpression:
+			if v.Argument != nil {
+				e = v.Argument
+				continue
+			}
+		}
+		break
+	}
+	_, ok := e.(*LiteralValueString)
+	return ok || isMemberExpressionChain(e)
+}
+
+// neverBreaksAfterOperator reports whether e stays on the line of an
+// assignment operator, as nothing is gained by moving it.
+func neverBreaksAfterOperator(e Expression) bool {
+	switch v := e.(type) {
+	case *TemplateLiteral, *TaggedTemplateExpression, *LiteralValueBool, *LiteralValueNumber, *LiteralValueBigFloat, *ClassExpression:
+		return true
+	case *CallExpression:
+		// require("module")
+		id, ok := v.Callee.(*Identifier)
+		if !ok || id.Name != "require" || len(v.Arguments) != 1 {
+			return false
+		}
+		_, ok = v.Arguments[0].(*LiteralValueString)
+		return ok
+	}
+	return false
+}
+
+// isMemberExpressionChain reports whether e is a chain of member accesses
+// on an identifier, such as process.env.HOME.
+func isMemberExpressionChain(e Expression) bool {
+	switch v := e.(type) {
+	case *StaticMemberExpression:
+		e = v.Object
+	case *ComputedMemberExpression:
+		e = v.Object
+	default:
+		return false
+	}
+	return isIdentifier(e) || isMemberExpressionChain(e)
+}
+
+// isShortKey reports whether a property key is too short for its value to
+// move to the next line.
+func (p *printer) isShortKey(key PropertyKey, computed bool) bool {
+	if computed {
+		return false
+	}
+	w := textWidth(strings.ReplaceAll(p.unit, "\t", "  ")) + 3
+	switch k := key.(type) {
+	case *Identifier:
+		return textWidth(k.Name) < w
+	case *LiteralValueString:
+		return textWidth(string(*k))+2 < w
+	}
+	return false
+}
+
+// arrow prints an arrow function with an expression body, which moves to
+// the next line if it doesn't fit.
+func (p *printer) arrow(n *ArrowFunctionExpression) {
+	p.group(func() {
+		if n.Async {
+			p.print("async ")
+		}
+		if len(n.Params) == 1 && isIdentifier(n.Params[0]) && p.ArrowParens == ArrowParensAvoid {
+			p.node(n.Params[0])
+		} else {
+			p.params(n.Params)
+		}
+		p.print(" =>")
+		body := n.Expression
+		// a body starting with { would be read as a block
+		parens := precedence(body) < precAssign || startsWith(body, isObjectExpression)
+		switch body.(type) {
+		case *ObjectExpression, *ArrayExpression, *ArrowFunctionExpression, *TemplateLiteral, *TaggedTemplateExpression:
+			p.print(" ")
+			p.wrap(parens, body)
+			return
+		}
+		p.group(func() {
+			p.indent(func() {
+				p.add(line)
+				if parens {
+					p.wrap(true, body)
+				} else {
+					p.expression(body, binaryGroup)
+				}
+			})
+		})
+	})
+}
+
+// binaryLayout is how the lines of a broken binary expression are placed.
+type binaryLayout int
+
+const (
+	// binaryIndent indents the lines after the first.
+	binaryIndent binaryLayout = iota
+	// binaryGroup aligns the lines, eg. after an assignment operator that
+	// broke.
+	binaryGroup
+	// binaryInline breaks the lines with the enclosing group, eg. of the
+	// parentheses of an if statement.
+	binaryInline
+)
+
+func isBinaryish(e Expression) bool {
+	switch e.(type) {
+	case *BinaryExpression, *LogicalExpression:
+		return true
+	}
+	return false
+}
+
+// expression prints e, laying it out as layout if it is a binary
+// expression.
+func (p *printer) expression(e Expression, layout binaryLayout) {
+	if !isBinaryish(e) || p.noIn && isIn(e) {
+		p.node(e)
+		return
+	}
+	p.enter(e)
+	p.binary(e, layout)
+}
+
+// binary prints a binary expression, with the operands of the same
+// precedence as e on a line each if broken.
+func (p *printer) binary(e Expression, layout binaryLayout) {
+	d := p.capture(func() { p.binaryParts(e) })
+	switch layout {
+	case binaryInline:
+		p.add(d)
+	case binaryGroup:
+		p.add(group(d, false))
+	default:
+		p.add(group(cat(d.parts[0], &docIndent{cat(d.parts[1:]...)}), false))
+	}
+}
+
+// binaryParts prints the operands and operators of a binary expression,
+// starting with a group of the first operand.
+func (p *printer) binaryParts(e Expression) {
+	op, left, right, lparens, rparens := binaryOperands(e)
+	lparens = lparens || clarifies(op, left, false)
+	rparens = rparens || clarifies(op, right, true)
+	if !lparens && flattens(op, left) {
+		p.binaryParts(left)
+	} else {
+		p.group(func() { p.wrap(lparens, left) })
+	}
+	p.print(" " + op)
+	if inlinesRight(e) {
+		p.print(" ")
+	} else {
+		p.add(line)
+	}
+	p.wrap(rparens, right)
+}
+
+// binaryOperands returns the operator and operands of a binary or logical
+// expression, and whether the operands must be parenthesized.
+func binaryOperands(e Expression) (op string, left, right Expression, lparens, rparens bool) {
+	switch n := e.(type) {
+	case *BinaryExpression:
+		lmin, rmin := binaryMin(n.Operator)
+		return string(n.Operator), n.Left, n.Right, precedence(n.Left) < lmin, precedence(n.Right) < rmin
+	case *LogicalExpression:
+		prec := precedence(n)
+		return string(n.Operator), n.Left, n.Right,
+			mixesNullish(n.Operator, n.Left) || precedence(n.Left) < prec,
+			mixesNullish(n.Operator, n.Right) || precedence(n.Right) < prec+1
+	}
+	panic("goesprima: not a binary expression")
+}
+
+// flattens reports whether the left operand of a binary expression with
+// operator op is printed in the same group.
+func flattens(op string, left Expression) bool {
+	if !isBinaryish(left) {
+		return false
+	}
+	inner, _, _, _, _ := binaryOperands(left)
+	return sameGroup(op, inner)
+}
+
+// sameGroup reports whether operators of the same precedence level read
+// well in a single group, which prettier doesn't find of eg. x * y % z.
+func sameGroup(op, inner string) bool {
+	multiplicative := func(op string) bool { return op == "*" || op == "/" || op == "%" }
+	switch {
+	case binaryPrec(op) != binaryPrec(inner):
+		return false
+	case op == "**":
+		return false
+	case binaryPrec(op) == precEquality:
+		return false
+	case multiplicative(op) && (op != inner || op == "%"):
+		return false
+	case binaryPrec(op) == precShift:
+		return false
+	}
+	return true
+}
+
+// clarifies reports whether a binary operand of the operator op is
+// parenthesized although its precedence doesn't require it, as in
+// (a && b) || c, (x * y) % z or a | (b & c).
+func clarifies(op string, operand Expression, right bool) bool {
+	if !isBinaryish(operand) {
+		return false
+	}
+	inner, _, _, _, _ := binaryOperands(operand)
+	prec := binaryPrec(op)
+	switch {
+	case prec == binaryPrec(inner):
+		return right || !sameGroup(op, inner)
+	case inner == "%":
+		return prec == precAdditive
+	case op == "||" && inner == "&&":
+		return true
+	}
+	return prec >= precBitOr && prec <= precBitAnd || prec == precShift
+}
+
+// binaryPrec returns the precedence level of a binary or logical operator.
+func binaryPrec(op string) int {
+	switch op {
+	case "&&":
+		return precAnd
+	case "||", "??":
+		return precOr
+	}
+	return binaryLevel(binaryOperator(op))
+}
+
+// inlinesRight reports whether the right operand of a logical expression
+// is an object or array literal, kept on the line of the operator.
+func inlinesRight(e Expression) bool {
+	l, ok := e.(*LogicalExpression)
+	if !ok {
+		return false
+	}
+	switch r := l.Right.(type) {
+	case *ObjectExpression:
+		return len(r.Properties) > 0
+	case *ArrayExpression:
+		return len(r.Elements) > 0
+	}
+	return false
+}
+
+// memberChain prints a call on a member access, eg. a.b().c(), on one line
+// if it fits and with a member access per line after the head of the chain
+// otherwise, reporting whether n is such a call.
+func (p *printer) memberChain(n *CallExpression) bool {
+	var links []Expression
+	root := Expression(n)
+walk:
+	for {
+		switch v := root.(type) {
+		case *CallExpression:
+			links, root = append(links, v), v.Callee
+		case *StaticMemberExpression:
+			if _, ok := v.Property.(*Identifier); !ok {
+				break walk
+			}
+			links, root = append(links, v), v.Object
+		case *ComputedMemberExpression:
+			links, root = append(links, v), v.Object
+		default:
+			break walk
+		}
+	}
+	if len(links) < 2 || !isMember(links[1]) || isLiteralNumber(root) {
+		return false
+	}
+	for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+		links[i], links[j] = links[j], links[i]
+	}
+
+	// the head is the root with its calls and computed accesses, eg.
+	// a()[0], and the member accesses that follow, eg. this.items
+	i := 0
+	for i < len(links) && (isCall(links[i]) || isComputedLiteral(links[i])) {
+		i++
+	}
+	for i+1 < len(links) && isMember(links[i]) && isMember(links[i+1]) {
+		i++
+	}
+	groups := [][]Expression{links[:i]}
+	var cur []Expression
+	call := false
+	for ; i < len(links); i++ {
+		if call && isMember(links[i]) {
+			if c, ok := links[i].(*ComputedMemberExpression); ok && !isLiteral(c.Property) {
+				cur = append(cur, links[i])
+				continue
+			}
+			groups, cur, call = append(groups, cur), nil, false
+		}
+		call = call || isCall(links[i])
+		cur = append(cur, links[i])
+	}
+	if len(cur) > 0 {
+		groups = append(groups, cur)
+	}
+
+	printed := make([]doc, len(groups))
+	for i, g := range groups {
+		printed[i] = p.capture(func() {
+			if i == 0 {
+				p.wrap(objectNeedsParens(root), root)
+			}
+			for _, l := range g {
+				p.link(l)
+			}
+		})
+	}
+	oneLine := cat(printed...)
+	merge := len(groups) > 1 && isFactoryHead(root, groups[0], groups[1])
+	cutoff := 2
+	if merge {
+		cutoff = 3
+	}
+	if len(groups) <= cutoff {
+		p.add(group(oneLine, false))
+		return true
+	}
+
+	head := 1
+	if merge {
+		head = 2
+	}
+	var rest []doc
+	for i, d := range printed[head:] {
+		if i > 0 {
+			rest = append(rest, hardline)
+		}
+		rest = append(rest, d)
+	}
+	expanded := cat(append(append([]doc{}, printed[:head]...), &docIndent{group(cat(append([]doc{hardline}, rest...)...), false)})...)
+
+	calls, complex := 0, false
+	for _, l := range links {
+		if c, ok := l.(*CallExpression); ok {
+			calls++
+			for _, a := range c.Arguments {
+				complex = complex || !isSimpleArgument(a, 0)
+			}
+		}
+	}
+	if calls > 2 && complex || anyBreaks(printed[:len(printed)-1]) {
+		p.add(group(expanded, false))
+		return true
+	}
+	p.add(cat(p.hardIf(oneLine.breaks()), conditionalGroup(oneLine, expanded)))
+	return true
+}
+
+// link prints a call or member access of a member chain.
+func (p *printer) link(e Expression) {
+	switch v := e.(type) {
+	case *CallExpression:
+		if v.Optional {
+			p.print("?.")
+		}
+		p.arguments(v.Arguments)
+	case *StaticMemberExpression:
+		if v.Optional {
+			p.print("?.")
+		} else {
+			p.print(".")
+		}
+		p.node(v.Property)
+	case *ComputedMemberExpression:
+		if v.Optional {
+			p.print("?.")
+		}
+		p.print("[")
+		p.node(v.Property)
+		p.print("]")
+	}
+}
+
+// isFactoryHead reports whether the first member access of a chain stays
+// with its head, as in Object.keys(o) or this.x(), because the head is
+// likely to be the subject of all the calls.
+func isFactoryHead(root Expression, head, next []Expression) bool {
+	computed := len(next) > 0 && isComputedLiteral(next[0])
+	if len(head) == 0 {
+		switch r := root.(type) {
+		case *ThisExpression:
+			return true
+		case *Identifier:
+			return isFactory(r.Name) || computed
+		}
+		return false
+	}
+	if m, ok := head[len(head)-1].(*StaticMemberExpression); ok {
+		id := m.Property.(*Identifier)
+		return isFactory(id.Name) || computed
+	}
+	return false
+}
+
+// isFactory reports whether a name is capitalized or made of _ and $.
+func isFactory(name string) bool {
+	return name != "" && (name[0] >= 'A' && name[0] <= 'Z' || strings.Trim(name, "_$") == "")
+}
+
+func isCall(e Expression) bool {
+	_, ok := e.(*CallExpression)
+	return ok
+}
+
+func isMember(e Expression) bool {
+	switch e.(type) {
+	case *StaticMemberExpression, *ComputedMemberExpression:
+		return true
+	}
+	return false
+}
+
+func isComputedLiteral(e Expression) bool {
+	c, ok := e.(*ComputedMemberExpression)
+	return ok && isLiteral(c.Property)
+}
+
+func isLiteral(e Expression) bool {
+	_, ok := e.(Literal)
+	return ok
+}
+
+func isLiteralNumber(e Expression) bool {
+	switch e.(type) {
+	case *LiteralValueNumber, *LiteralValueBigFloat:
+		return true
+	}
+	return false
+}
+
+// isSimpleArgument reports whether a call argument is simple enough for a
+// chain of calls to stay on one line.
+func isSimpleArgument(e JSElement, depth int) bool {
+	switch v := e.(type) {
+	case *Identifier, *ThisExpression, *Super, Literal:
+		return true
+	case *TemplateLiteral:
+		for _, q := range v.Quasis {
+			if strings.Contains(q.Raw, "\n") {
+				return false
+			}
+		}
+		for _, x := range v.Expressions {
+			if !isSimpleArgument(x, depth+1) {
+				return false
+			}
+		}
+		return true
+	case *ObjectExpression:
+		for _, prop := range v.Properties {
+			pr, ok := prop.(*Property)
+			if !ok || pr.Computed || !pr.ShortHand && (pr.Value == nil || !isSimpleArgument(pr.Value, depth+1)) {
+				return false
+			}
+		}
+		return true
+	case *ArrayExpression:
+		for _, x := range v.Elements {
+			if x != nil && !isSimpleArgument(x, depth+1) {
+				return false
+			}
+		}
+		return true
+	case *CallExpression:
+		if depth >= 2 || !isSimpleArgument(v.Callee, depth) {
+			return false
+		}
+		for _, a := range v.Arguments {
+			if !isSimpleArgument(a, depth+1) {
+				return false
+			}
+		}
+		return true
+	case *NewExpression:
+		if depth >= 2 || !isSimpleArgument(v.Callee, depth) {
+			return false
+		}
+		for _, a := range v.Arguments {
+			if !isSimpleArgument(a, depth+1) {
+				return false
+			}
+		}
+		return true
+	case *UnaryExpression:
+		switch v.Operator {
+		case UnaryOperatorTypeNot, UnaryOperatorTypeMinus, UnaryOperatorTypePlus, UnaryOperatorTypeBitwiseNot:
+			return isSimpleArgument(v.Argument, depth)
+		}
+	case *StaticMemberExpression:
+		_, ok := v.Property.(*Identifier)
+		return ok && isSimpleArgument(v.Object, depth)
+	case *ComputedMemberExpression:
+		return isSimpleArgument(v.Object, depth) && isSimpleArgument(v.Property, depth)
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintWidth(t *testing.T) {
+	prog, err := Parse("test.js", `import { Auth } from "@aws-amplify/auth";
+export const USER_POOL_CLIENT_ID_TODOUSERS = process.env.REACT_APP_USER_POOL_CLIENT_ID_TODOUSERS;
+export const config = {
+  API: {
+    endpoints: [
+      {
+        name: REST_NAME_TODORESTAPI,
+        custom_header: async () => {
+          return {
+            Authorization: "Bearer " + (await Auth.currentSession()).getAccessToken().getJwtToken(),
+          };
+        },
+      },
+    ],
+  },
+};
+Amplify.configure(config);`)
+	assert.NoError(t, err)
+	assert.Equal(t, `import { Auth } from "@aws-amplify/auth";
+export const USER_POOL_CLIENT_ID_TODOUSERS =
+  process.env.REACT_APP_USER_POOL_CLIENT_ID_TODOUSERS;
+export const config = {
+  API: {
+    endpoints: [
+      {
+        name: REST_NAME_TODORESTAPI,
+        custom_header: async () => {
+          return {
+            Authorization:
+              "Bearer " +
+              (await Auth.currentSession()).getAccessToken().getJwtToken(),
+          };
+        },
+      },
+    ],
+  },
+};
+Amplify.configure(config);`, (&PrinterConfig{PrintWidth: 80}).Sprint(prog))
+}
+
+func TestPrintWidthLayouts(t *testing.T) {
+	tests := []struct {
+		src, expect string
+	}{
+		// objects fit on one line unless broken after { in the source
+		{"x = {a, b: [c, d]};", "x = { a, b: [c, d] };"},
+		{"x = {\na};", "x = {\n  a,\n};"},
+		{
+			"x = {alpha: alphabet, beta: betamax, gamma: gammaray};",
+			"x = {\n  alpha: alphabet,\n  beta: betamax,\n  gamma: gammaray,\n};",
+		},
+		{"x = [[a, b], [c, d]];", "x = [\n  [a, b],\n  [c, d],\n];"},
+		{"x = [a, , ];", "x = [a, ,];"},
+		{"x = [1.5, 0.25, 100, -3];", "x = [1.5, 0.25, 100, -3];"},
+		// the last or first function argument is hugged
+		{
+			"foo(bar, function () { return baz; });",
+			"foo(bar, function () {\n  return baz;\n});",
+		},
+		{
+			"setTimeout(() => { done(); }, 500);",
+			"setTimeout(() => {\n  done();\n}, 500);",
+		},
+		{
+			"useEffect(() => { run(); }, [alpha, beta]);",
+			"useEffect(() => {\n  run();\n}, [alpha, beta]);",
+		},
+		{
+			"someFunction(argumentOne, argumentTwo, argumentThree);",
+			"someFunction(\n  argumentOne,\n  argumentTwo,\n  argumentThree\n);",
+		},
+		{
+			"function f(parameterOne, parameterTwo, parameterThree) {}",
+			"function f(\n  parameterOne,\n  parameterTwo,\n  parameterThree\n) {}",
+		},
+		{
+			"function f({ alpha, beta, gamma, delta, epsilon }) {}",
+			"function f({\n  alpha,\n  beta,\n  gamma,\n  delta,\n  epsilon,\n}) {}",
+		},
+		// member chains break before each call
+		{"a.b(c).d(e);", "a.b(c).d(e);"},
+		{
+			"items.filter(x => x.on).map(x => x.value).sort();",
+			"items\n  .filter((x) => x.on)\n  .map((x) => x.value)\n  .sort();",
+		},
+		{
+			"this.server.listen(port).on(event, handler);",
+			"this.server\n  .listen(port)\n  .on(event, handler);",
+		},
+		// binary expressions break after the operators
+		{
+			"if (alpha && beta || gamma && delta) {}",
+			"if (\n  (alpha && beta) ||\n  (gamma && delta)\n) {}",
+		},
+		{
+			"total = subtotal + shipping - discount;",
+			"total =\n  subtotal +\n  shipping -\n  discount;",
+		},
+		{
+			"function f() { return alphabetical && betamaxed; }",
+			"function f() {\n  return (\n    alphabetical && betamaxed\n  );\n}",
+		},
+		{"x = a * b % c;", "x = (a * b) % c;"},
+		{
+			"value = condition ? consequent : alternate;",
+			"value = condition\n  ? consequent\n  : alternate;",
+		},
+		{
+			"const f = (alpha, beta) => alpha + beta;",
+			"const f = (alpha, beta) =>\n  alpha + beta;",
+		},
+	}
+	cfg := &PrinterConfig{PrintWidth: 30}
+	for _, test := range tests {
+		prog, err := Parse("test.js", test.src)
+		if assert.NoError(t, err, test.src) {
+			assert.Equal(t, test.expect, cfg.Sprint(prog), test.src)
+		}
+	}
+}
+
+func TestPrintWidthOptions(t *testing.T) {
+	prog, err := Parse("test.js", "f(alphabet, betamax, { gamma });")
+	assert.NoError(t, err)
+	assert.Equal(t, "f(alphabet, betamax, {gamma});",
+		(&PrinterConfig{PrintWidth: 40, OmitBracketSpacing: true}).Sprint(prog))
+	assert.Equal(t, "f(\n\talphabet,\n\tbetamax,\n\t{ gamma },\n);",
+		(&PrinterConfig{PrintWidth: 20, Indent: &Tabs{1}, TrailingCommas: TrailingCommaAll}).Sprint(prog))
+
+	// zero keeps the fixed layout
+	prog, err = Parse("test.js", "x = {a};\nf(alphabet, betamax, gamma, delta, epsilon);")
+	assert.NoError(t, err)
+	assert.Equal(t, prog.String(), (&PrinterConfig{}).Sprint(prog))
+	assert.Equal(t, "x = {\n  a,\n};\nf(alphabet, betamax, gamma, delta, epsilon);", prog.String())
+}
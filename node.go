@@ -1,11 +1,19 @@
 package goesprima
 
+import (
+	"reflect"
+)
+
 type Program struct {
 	Name string
 	Body []StatementListItem
 	*Range
 }
 
+func (p *Program) String() string {
+	return sprint(p)
+}
+
 type Node struct {
 	Location *SourceLocation
 	*Range
@@ -22,7 +30,28 @@ type Range struct {
 	End   int
 }
 
+// Position is a position in a source. Lines start at 1 and columns at 0;
+// columns are counted in UTF-16 code units, like the indices of JavaScript
+// strings.
 type Position struct {
 	Line   int
 	Column int
 }
+
+// LocationOf returns the source location of node, or nil if it has none.
+func LocationOf(node JSElement) *SourceLocation {
+	v := reflect.ValueOf(node)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if f := v.FieldByName("Node"); f.IsValid() && f.Type() == reflect.TypeOf((*Node)(nil)) && !f.IsNil() {
+		return f.Interface().(*Node).Location
+	}
+	return nil
+}
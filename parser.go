@@ -1 +1,1743 @@
 package goesprima
+
+// Parse parses the JavaScript module src and returns its syntax tree. The
+// name is stored in Program.Name and in the SourceLocation of every node.
+// Ranges are byte offsets into src; lines start at 1 and columns at 0.
+//
+// Regular expression literals, BigInt literals, private class members and
+// destructuring assignments are not supported since the tree has no nodes
+// for them, and are reported as syntax errors.
+func Parse(name, src string) (prog *Program, err error) {
+	p := newParser(name, src)
+	defer p.recover(&err)
+	p.next()
+	prog = p.parseProgram()
+	return
+}
+
+// ParseExpr parses a single JavaScript expression.
+func ParseExpr(src string) (expr Expression, err error) {
+	p := newParser("", src)
+	defer p.recover(&err)
+	p.next()
+	expr = p.parseExpression()
+	p.expectEOF()
+	return
+}
+
+type bailout struct {
+	err *SyntaxError
+}
+
+type marker struct {
+	offset int
+	pos    Position
+}
+
+// funcContext tracks the enclosing function for await and yield.
+type funcContext struct {
+	inFunction bool
+	async      bool
+	generator  bool
+}
+
+type parser struct {
+	s          scanner
+	tok        token
+	prevEnd    int
+	prevEndPos Position
+
+	ctx funcContext
+	// noIn disables the in operator in the head of a for statement.
+	noIn bool
+	// positions attaches a Node to every parsed node.
+	positions bool
+	// tmpl holds the arguments when parsing a code template.
+	tmpl *templateArgs
+}
+
+func newParser(name, src string) *parser {
+	p := &parser{
+		s:         newScanner(name, src),
+		positions: true,
+		// modules allow top-level await
+		ctx: funcContext{async: true},
+	}
+	return p
+}
+
+func (p *parser) recover(err *error) {
+	if r := recover(); r != nil {
+		b, ok := r.(bailout)
+		if !ok {
+			panic(r)
+		}
+		*err = b.err
+	}
+}
+
+// Tokens
+
+func (p *parser) next() {
+	p.prevEnd = p.tok.end
+	p.prevEndPos = p.tok.endPos
+	p.tok = p.s.next()
+}
+
+type parserState struct {
+	s          scanner
+	tok        token
+	prevEnd    int
+	prevEndPos Position
+}
+
+func (p *parser) save() parserState {
+	return parserState{p.s, p.tok, p.prevEnd, p.prevEndPos}
+}
+
+func (p *parser) restore(st parserState) {
+	p.s, p.tok, p.prevEnd, p.prevEndPos = st.s, st.tok, st.prevEnd, st.prevEndPos
+}
+
+func (p *parser) peek() token {
+	st := p.save()
+	p.next()
+	t := p.tok
+	p.restore(st)
+	return t
+}
+
+// try runs f and reports whether it succeeded. On a syntax error the parser
+// is reset to where it was before f.
+func (p *parser) try(f func()) (ok bool) {
+	st, ctx, noIn := p.save(), p.ctx, p.noIn
+	defer func() {
+		if r := recover(); r != nil {
+			if _, isBailout := r.(bailout); !isBailout {
+				panic(r)
+			}
+			p.restore(st)
+			p.ctx, p.noIn = ctx, noIn
+			ok = false
+		}
+	}()
+	f()
+	return true
+}
+
+func (p *parser) errorf(offset int, format string, args ...interface{}) {
+	p.s.errorf(offset, format, args...)
+}
+
+func (p *parser) unexpected() {
+	switch p.tok.kind {
+	case tokenEOF:
+		p.errorf(p.tok.start, "unexpected end of input")
+	case tokenPlaceholder:
+		p.errorf(p.tok.start, "unexpected placeholder %%[%s]s", p.tok.value)
+	default:
+		p.errorf(p.tok.start, "unexpected token %s", p.tok.raw)
+	}
+}
+
+func (p *parser) is(punctuator string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == punctuator
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	return p.tok.kind == tokenIdentifier && !p.tok.escaped && p.tok.value == keyword
+}
+
+func isPunctuator(t token, punctuator string) bool {
+	return t.kind == tokenPunctuator && t.value == punctuator
+}
+
+func isKeyword(t token, keyword string) bool {
+	return t.kind == tokenIdentifier && !t.escaped && t.value == keyword
+}
+
+func (p *parser) eat(punctuator string) bool {
+	if p.is(punctuator) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(punctuator string) {
+	if !p.eat(punctuator) {
+		p.unexpected()
+	}
+}
+
+func (p *parser) expectKeyword(keyword string) {
+	if !p.isKeyword(keyword) {
+		p.unexpected()
+	}
+	p.next()
+}
+
+func (p *parser) expectEOF() {
+	if p.tok.kind != tokenEOF {
+		p.unexpected()
+	}
+}
+
+// semicolon consumes the semicolon ending a statement, applying automatic
+// semicolon insertion.
+func (p *parser) semicolon() {
+	if p.eat(";") || p.is("}") || p.tok.kind == tokenEOF || p.tok.newline {
+		return
+	}
+	p.unexpected()
+}
+
+// Positions
+
+func (p *parser) mark() marker {
+	return marker{p.tok.start, p.tok.startPos}
+}
+
+// node returns the position of the source from m to the last consumed
+// token.
+func (p *parser) node(m marker) *Node {
+	if !p.positions {
+		return nil
+	}
+	return &Node{
+		Location: &SourceLocation{Start: m.pos, End: p.prevEndPos, Source: p.s.source},
+		Range:    &Range{Start: m.offset, End: p.prevEnd},
+	}
+}
+
+func (p *parser) tokenNode(t token) *Node {
+	if !p.positions {
+		return nil
+	}
+	return &Node{
+		Location: &SourceLocation{Start: t.startPos, End: t.endPos, Source: p.s.source},
+		Range:    &Range{Start: t.start, End: t.end},
+	}
+}
+
+// Identifiers
+
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true,
+	"do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true,
+	"import": true, "in": true, "instanceof": true, "new": true, "null": true,
+	"return": true, "super": true, "switch": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "var": true, "void": true,
+	"while": true, "with": true,
+}
+
+func (p *parser) isIdentifier() bool {
+	if p.tok.kind != tokenIdentifier {
+		return false
+	}
+	if p.tok.escaped {
+		return true
+	}
+	switch p.tok.value {
+	case "await":
+		return !p.ctx.async
+	case "yield":
+		return !p.ctx.generator
+	}
+	return !reservedWords[p.tok.value]
+}
+
+// parseIdentifier parses a binding or reference identifier.
+func (p *parser) parseIdentifier() *Identifier {
+	if !p.isIdentifier() {
+		p.unexpected()
+	}
+	return p.parseIdentifierName()
+}
+
+// parseIdentifierName parses an identifier, including reserved words, as
+// used for property names.
+func (p *parser) parseIdentifierName() *Identifier {
+	if p.tok.kind != tokenIdentifier {
+		if p.is("#") {
+			p.errorf(p.tok.start, "private names are not supported")
+		}
+		p.unexpected()
+	}
+	id := &Identifier{Name: p.tok.value, Node: p.tokenNode(p.tok)}
+	p.next()
+	return id
+}
+
+// Program
+
+func (p *parser) parseProgram() *Program {
+	prog := &Program{Name: p.s.source}
+	prog.Body = p.parseStatementList(true, func() bool {
+		return p.tok.kind == tokenEOF
+	})
+	if p.positions {
+		prog.Range = &Range{Start: 0, End: len(p.s.src)}
+	}
+	return prog
+}
+
+// parseStatementList parses statements until end returns true. A directive
+// prologue such as "use strict" is recognised if directives is set.
+func (p *parser) parseStatementList(directives bool, end func() bool) (list []StatementListItem) {
+	for !end() {
+		if p.tok.kind == tokenPlaceholder {
+			if items, ok := p.parsePlaceholderStatements(); ok {
+				list = append(list, items...)
+				directives = false
+				continue
+			}
+		}
+		first := p.tok
+		item := p.parseStatementListItem()
+		if directives {
+			directives = false
+			if stmt, ok := item.(*ExpressionStatement); ok && first.kind == tokenString {
+				if _, ok := stmt.Expression.(*LiteralValueString); ok {
+					item = &Directive{
+						Expression: stmt.Expression,
+						Directive:  first.raw[1 : len(first.raw)-1],
+						Node:       stmt.Node,
+					}
+					directives = true
+				}
+			}
+		}
+		list = append(list, item)
+	}
+	return
+}
+
+func (p *parser) parseStatementListItem() StatementListItem {
+	if p.tok.kind == tokenIdentifier && !p.tok.escaped {
+		switch p.tok.value {
+		case "import":
+			if t := p.peek(); !isPunctuator(t, "(") && !isPunctuator(t, ".") {
+				return p.parseImportDeclaration()
+			}
+		case "export":
+			return p.parseExportDeclaration()
+		}
+	}
+	return p.parseStatement()
+}
+
+// isLexicalDeclaration reports whether the current token starts a let or
+// const declaration.
+func (p *parser) isLexicalDeclaration() bool {
+	if p.isKeyword("const") {
+		return true
+	}
+	if !p.isKeyword("let") {
+		return false
+	}
+	t := p.peek()
+	return t.kind == tokenIdentifier || t.kind == tokenPlaceholder || isPunctuator(t, "[") || isPunctuator(t, "{")
+}
+
+func (p *parser) isAsyncFunction() bool {
+	if !p.isKeyword("async") {
+		return false
+	}
+	t := p.peek()
+	return isKeyword(t, "function") && !t.newline
+}
+
+// Statements
+
+func (p *parser) parseStatement() Statement {
+	m := p.mark()
+	switch {
+	case p.is("{"):
+		return p.parseBlock()
+	case p.is(";"):
+		p.next()
+		return &EmptyStatement{Node: p.node(m)}
+	case p.isKeyword("var") || p.isLexicalDeclaration():
+		decl := p.parseVariableDeclaration()
+		p.semicolon()
+		decl.Node = p.node(m)
+		return decl
+	case p.isKeyword("function") || p.isAsyncFunction():
+		return p.parseFunctionDeclaration(false)
+	case p.isKeyword("class"):
+		return p.parseClassDeclaration(false)
+	case p.isKeyword("if"):
+		return p.parseIfStatement()
+	case p.isKeyword("for"):
+		return p.parseForStatement()
+	case p.isKeyword("while"):
+		p.next()
+		p.expect("(")
+		test := p.parseExpression()
+		p.expect(")")
+		body := p.parseStatement()
+		return &WhileStatement{Test: test, Body: body, Node: p.node(m)}
+	case p.isKeyword("do"):
+		p.next()
+		body := p.parseLoopBody()
+		p.expectKeyword("while")
+		p.expect("(")
+		test := p.parseExpression()
+		p.expect(")")
+		p.eat(";")
+		return &DoWhileStatement{Body: body, Test: test, Node: p.node(m)}
+	case p.isKeyword("continue"):
+		p.next()
+		label := p.parseLabel()
+		p.semicolon()
+		return &ContinueStatement{Label: label, Node: p.node(m)}
+	case p.isKeyword("break"):
+		p.next()
+		label := p.parseLabel()
+		p.semicolon()
+		return &BreakStatement{Label: label, Node: p.node(m)}
+	case p.isKeyword("return"):
+		p.next()
+		var arg Expression
+		if !p.is(";") && !p.is("}") && p.tok.kind != tokenEOF && !p.tok.newline {
+			arg = p.parseExpression()
+		}
+		p.semicolon()
+		return &ReturnStatement{Argument: arg, Node: p.node(m)}
+	case p.isKeyword("with"):
+		p.next()
+		p.expect("(")
+		obj := p.parseExpression()
+		p.expect(")")
+		body := p.parseStatement()
+		return &WithStatement{Object: obj, Body: body, Node: p.node(m)}
+	case p.isKeyword("switch"):
+		return p.parseSwitchStatement()
+	case p.isKeyword("throw"):
+		p.next()
+		if p.tok.newline {
+			p.errorf(p.tok.start, "illegal newline after throw")
+		}
+		arg := p.parseExpression()
+		p.semicolon()
+		return &ThrowStatement{Argument: arg, Node: p.node(m)}
+	case p.isKeyword("try"):
+		return p.parseTryStatement()
+	case p.isKeyword("debugger"):
+		p.next()
+		p.semicolon()
+		return &DebuggerStatement{Node: p.node(m)}
+	case p.isIdentifier() && isPunctuator(p.peek(), ":"):
+		label := p.parseIdentifier()
+		p.next()
+		body := p.parseStatement()
+		return &LabeledStatement{Label: *label, Body: body, Node: p.node(m)}
+	}
+
+	expr := p.parseExpression()
+	p.semicolon()
+	return &ExpressionStatement{Expression: expr, Node: p.node(m)}
+}
+
+func (p *parser) parseLabel() *Identifier {
+	if p.tok.newline || !p.isIdentifier() && p.tok.kind != tokenPlaceholder {
+		return nil
+	}
+	return p.parseName()
+}
+
+func (p *parser) parseBlock() *BlockStatement {
+	m := p.mark()
+	p.expect("{")
+	items := p.parseStatementList(false, func() bool {
+		return p.is("}") || p.tok.kind == tokenEOF
+	})
+	p.expect("}")
+	return &BlockStatement{Items: p.statements(m, items), Node: p.node(m)}
+}
+
+// statements converts the items of a block, which can't contain module
+// declarations.
+func (p *parser) statements(m marker, items []StatementListItem) []Statement {
+	out := make([]Statement, 0, len(items))
+	for _, item := range items {
+		stmt, ok := item.(Statement)
+		if !ok {
+			p.errorf(m.offset, "%T is only allowed at the top level", item)
+		}
+		out = append(out, stmt)
+	}
+	return out
+}
+
+// parseLoopBody parses the body of a loop, wrapping single statements into
+// a block since loop bodies are BlockStatements in the tree.
+func (p *parser) parseLoopBody() BlockStatement {
+	m := p.mark()
+	stmt := p.parseStatement()
+	if b, ok := stmt.(*BlockStatement); ok {
+		return *b
+	}
+	return BlockStatement{Items: []Statement{stmt}, Node: p.node(m)}
+}
+
+func (p *parser) parseIfStatement() *IfStatement {
+	m := p.mark()
+	p.next()
+	p.expect("(")
+	stmt := &IfStatement{Test: p.parseExpression()}
+	p.expect(")")
+	stmt.Consequent = p.parseStatement()
+	if p.isKeyword("else") {
+		p.next()
+		stmt.Alternate = p.parseStatement()
+	}
+	stmt.Node = p.node(m)
+	return stmt
+}
+
+func (p *parser) parseForStatement() Statement {
+	m := p.mark()
+	p.next()
+	var await bool
+	if p.isKeyword("await") && p.ctx.async {
+		p.next()
+		await = true
+	}
+	p.expect("(")
+
+	var init ForInit
+	if !p.is(";") {
+		p.noIn = true
+		if p.isKeyword("var") || p.isLexicalDeclaration() {
+			im := p.mark()
+			decl := p.parseVariableDeclaration()
+			decl.Node = p.node(im)
+			init = decl
+		} else {
+			init = p.parseExpression()
+		}
+		p.noIn = false
+	}
+
+	if init != nil && (p.isKeyword("of") || p.isKeyword("in")) {
+		of := p.tok.value == "of"
+		switch left := init.(type) {
+		case *VariableDeclaration:
+			if len(left.Declarations) != 1 {
+				p.errorf(m.offset, "invalid left-hand side in for-%s loop", p.tok.value)
+			}
+		case *Identifier, *StaticMemberExpression, *ComputedMemberExpression:
+		case *ObjectExpression, *ArrayExpression:
+			p.errorf(m.offset, "destructuring assignment is not supported")
+		default:
+			p.errorf(m.offset, "invalid left-hand side in for-%s loop", p.tok.value)
+		}
+		p.next()
+		if of {
+			right := p.parseAssignment()
+			p.expect(")")
+			body := p.parseLoopBody()
+			return &ForOfStatement{Await: await, Left: init, Right: right, Body: body, Node: p.node(m)}
+		}
+		right := p.parseExpression()
+		p.expect(")")
+		body := p.parseLoopBody()
+		return &ForInStatement{Left: init, Right: right, Body: body, Node: p.node(m)}
+	}
+
+	stmt := &ForStatement{Init: init}
+	p.expect(";")
+	if !p.is(";") {
+		stmt.Test = p.parseExpression()
+	}
+	p.expect(";")
+	if !p.is(")") {
+		stmt.Update = p.parseExpression()
+	}
+	p.expect(")")
+	stmt.Body = p.parseLoopBody()
+	stmt.Node = p.node(m)
+	return stmt
+}
+
+func (p *parser) parseSwitchStatement() *SwitchStatement {
+	m := p.mark()
+	p.next()
+	p.expect("(")
+	stmt := &SwitchStatement{Discriminant: p.parseExpression()}
+	p.expect(")")
+	p.expect("{")
+	for !p.eat("}") {
+		var c SwitchCase
+		cm := p.mark()
+		if p.isKeyword("default") {
+			p.next()
+		} else {
+			p.expectKeyword("case")
+			c.Test = p.parseExpression()
+		}
+		p.expect(":")
+		bm := p.mark()
+		items := p.parseStatementList(false, func() bool {
+			return p.isKeyword("case") || p.isKeyword("default") || p.is("}") || p.tok.kind == tokenEOF
+		})
+		c.Consequent = BlockStatement{Items: p.statements(cm, items)}
+		if len(items) > 0 {
+			c.Consequent.Node = p.node(bm)
+		}
+		stmt.Cases = append(stmt.Cases, c)
+	}
+	stmt.Node = p.node(m)
+	return stmt
+}
+
+func (p *parser) parseTryStatement() *TryStatement {
+	m := p.mark()
+	p.next()
+	stmt := &TryStatement{Block: *p.parseBlock()}
+	if p.isKeyword("catch") {
+		cm := p.mark()
+		p.next()
+		if p.eat("(") {
+			stmt.Handler.BindingIdentifierOrPattern = p.parseBindingTarget()
+			p.expect(")")
+		}
+		stmt.Handler.Body = *p.parseBlock()
+		stmt.Handler.Node = p.node(cm)
+	}
+	if p.isKeyword("finally") {
+		p.next()
+		stmt.Finalizer = p.parseBlock()
+	}
+	if !stmt.HasHandler() && stmt.Finalizer == nil {
+		p.errorf(m.offset, "missing catch or finally after try")
+	}
+	stmt.Node = p.node(m)
+	return stmt
+}
+
+// parseVariableDeclaration parses a var, let or const declaration without
+// the trailing semicolon.
+func (p *parser) parseVariableDeclaration() *VariableDeclaration {
+	m := p.mark()
+	decl := &VariableDeclaration{Kind: VariableDeclarationType(p.tok.value)}
+	p.next()
+	for {
+		d := VariableDeclarator{ID: p.parseBindingTarget()}
+		if p.eat("=") {
+			d.Init = p.parseAssignment()
+		}
+		decl.Declarations = append(decl.Declarations, d)
+		if !p.eat(",") {
+			break
+		}
+	}
+	decl.Node = p.node(m)
+	return decl
+}
+
+// Bindings
+
+// bindingTarget is implemented by identifiers and binding patterns.
+type bindingTarget interface {
+	BindingIdentifierOrPattern
+	bindingElement
+}
+
+// bindingElement is a binding target with an optional default value.
+type bindingElement interface {
+	FunctionParameter
+	ArrayPatternElement
+	PropertyValue
+}
+
+func (p *parser) parseBindingTarget() bindingTarget {
+	switch {
+	case p.is("["):
+		return p.parseArrayPattern()
+	case p.is("{"):
+		return p.parseObjectPattern()
+	case p.tok.kind == tokenPlaceholder:
+		return p.placeholderBinding()
+	}
+	return p.parseIdentifier()
+}
+
+func (p *parser) parseBindingElement() bindingElement {
+	m := p.mark()
+	target := p.parseBindingTarget()
+	if !p.eat("=") {
+		return target
+	}
+	right := p.parseAssignment()
+	return &AssignmentPattern{Left: target, Right: right, Node: p.node(m)}
+}
+
+func (p *parser) parseRestElement() *RestElement {
+	m := p.mark()
+	p.expect("...")
+	arg := p.parseBindingTarget()
+	return &RestElement{Argument: arg, Node: p.node(m)}
+}
+
+func (p *parser) parseArrayPattern() *ArrayPattern {
+	m := p.mark()
+	p.expect("[")
+	pattern := &ArrayPattern{}
+	for !p.eat("]") {
+		switch {
+		case p.is(","):
+			pattern.Elements = append(pattern.Elements, nil)
+			p.next()
+			continue
+		case p.is("..."):
+			pattern.Elements = append(pattern.Elements, p.parseRestElement())
+		default:
+			pattern.Elements = append(pattern.Elements, p.parseBindingElement())
+		}
+		if !p.is("]") {
+			p.expect(",")
+		}
+	}
+	pattern.Node = p.node(m)
+	return pattern
+}
+
+func (p *parser) parseObjectPattern() *ObjectPattern {
+	m := p.mark()
+	p.expect("{")
+	pattern := &ObjectPattern{}
+	for !p.eat("}") {
+		if p.is("...") {
+			pattern.Properties = append(pattern.Properties, p.parseRestElement())
+		} else {
+			pattern.Properties = append(pattern.Properties, p.parsePropertyPattern())
+		}
+		if !p.is("}") {
+			p.expect(",")
+		}
+	}
+	pattern.Node = p.node(m)
+	return pattern
+}
+
+func (p *parser) parsePropertyPattern() *PropertyPattern {
+	m := p.mark()
+	keyToken := p.tok
+	key, computed := p.parsePropertyKey()
+	prop := &PropertyPattern{Key: key, Computed: computed}
+	if p.eat(":") {
+		prop.Value = p.parseBindingElement()
+		prop.Node = p.node(m)
+		return prop
+	}
+
+	id, ok := key.(*Identifier)
+	if !ok || computed || keyToken.kind == tokenIdentifier && !keyToken.escaped && reservedWords[id.Name] {
+		p.unexpected()
+	}
+	prop.ShortHand = true
+	value := &Identifier{Name: id.Name, Node: id.Node}
+	prop.Value = value
+	if p.eat("=") {
+		right := p.parseAssignment()
+		prop.Value = &AssignmentPattern{Left: value, Right: right, Node: p.node(m)}
+	}
+	prop.Node = p.node(m)
+	return prop
+}
+
+// parsePropertyKey parses the key of an object literal property, object
+// pattern property or class member.
+func (p *parser) parsePropertyKey() (key PropertyKey, computed bool) {
+	switch p.tok.kind {
+	case tokenString:
+		key = StringLiteral(p.tok.value)
+		p.next()
+	case tokenNumber:
+		key = NumberLiteral(p.tok.number)
+		p.next()
+	case tokenPlaceholder:
+		return p.placeholderKey()
+	case tokenPunctuator:
+		if p.eat("[") {
+			noIn := p.noIn
+			p.noIn = false
+			key = p.parseAssignment()
+			p.noIn = noIn
+			p.expect("]")
+			return key, true
+		}
+		fallthrough
+	default:
+		key = p.parseIdentifierName()
+	}
+	return key, false
+}
+
+// parseName parses an identifier that is a name rather than a reference,
+// such as a label or the property of a member expression.
+func (p *parser) parseName() *Identifier {
+	if p.tok.kind == tokenPlaceholder {
+		return p.placeholderName()
+	}
+	return p.parseIdentifierName()
+}
+
+// Functions
+
+func (p *parser) functionType(async bool) FunctionType {
+	generator := p.eat("*")
+	switch {
+	case async && generator:
+		return FunctionTypeAsyncGenerator
+	case async:
+		return FunctionTypeAsync
+	case generator:
+		return FunctionTypeGenerator
+	}
+	return FunctionTypeNormal
+}
+
+func (t FunctionType) context() funcContext {
+	return funcContext{
+		inFunction: true,
+		async:      t == FunctionTypeAsync || t == FunctionTypeAsyncGenerator,
+		generator:  t == FunctionTypeGenerator || t == FunctionTypeAsyncGenerator,
+	}
+}
+
+// parseFunction parses a function after the function keyword.
+func (p *parser) parseFunction(m marker, async, optionalName bool) *FunctionExpression {
+	f := &FunctionExpression{FunctionType: p.functionType(async)}
+	if p.tok.kind == tokenPlaceholder || !p.is("(") || !optionalName {
+		if p.tok.kind == tokenPlaceholder {
+			f.ID = p.placeholderName()
+		} else {
+			f.ID = p.parseIdentifier()
+		}
+	}
+	f.Params, f.Body = p.parseFunctionRest(f.FunctionType.context())
+	f.Node = p.node(m)
+	return f
+}
+
+// parseFunctionRest parses the parameters and body of a function.
+func (p *parser) parseFunctionRest(ctx funcContext) ([]FunctionParameter, BlockStatement) {
+	saved := p.ctx
+	p.ctx = ctx
+	defer func() { p.ctx = saved }()
+	params := p.parseParams()
+	return params, p.parseFunctionBody()
+}
+
+func (p *parser) parseParams() (params []FunctionParameter) {
+	p.expect("(")
+	for !p.eat(")") {
+		switch {
+		case p.is("..."):
+			params = append(params, p.parseRestElement())
+		case p.tok.kind == tokenPlaceholder && p.isListEnd(p.peek(), ")"):
+			params = append(params, p.placeholderParams()...)
+		default:
+			params = append(params, p.parseBindingElement())
+		}
+		if !p.is(")") {
+			p.expect(",")
+		}
+	}
+	return
+}
+
+func (p *parser) parseFunctionBody() BlockStatement {
+	m := p.mark()
+	p.expect("{")
+	noIn := p.noIn
+	p.noIn = false
+	items := p.parseStatementList(true, func() bool {
+		return p.is("}") || p.tok.kind == tokenEOF
+	})
+	p.noIn = noIn
+	p.expect("}")
+	return BlockStatement{Items: p.statements(m, items), Node: p.node(m)}
+}
+
+func (p *parser) parseFunctionDeclaration(optionalName bool) *FunctionDeclaration {
+	m := p.mark()
+	async := p.isKeyword("async")
+	if async {
+		p.next()
+	}
+	p.expectKeyword("function")
+	f := p.parseFunction(m, async, optionalName)
+	return &FunctionDeclaration{
+		ID:           f.ID,
+		Params:       f.Params,
+		Body:         f.Body,
+		FunctionType: f.FunctionType,
+		Node:         f.Node,
+	}
+}
+
+// Classes
+
+func (p *parser) parseClassDeclaration(optionalName bool) *ClassDeclaration {
+	c := p.parseClass(optionalName)
+	return &ClassDeclaration{ID: c.ID, SuperClass: c.SuperClass, Body: c.Body, Node: c.Node}
+}
+
+func (p *parser) parseClass(optionalName bool) *ClassExpression {
+	m := p.mark()
+	p.expectKeyword("class")
+	c := &ClassExpression{}
+	if !p.is("{") && !p.isKeyword("extends") || !optionalName {
+		c.ID = p.parseBindingName()
+	}
+	if p.isKeyword("extends") {
+		p.next()
+		c.SuperClass = p.parseLeftHandSide(true)
+	}
+	c.Body = p.parseClassBody()
+	c.Node = p.node(m)
+	return c
+}
+
+func (p *parser) parseClassBody() *ClassBody {
+	m := p.mark()
+	p.expect("{")
+	body := &ClassBody{}
+	for !p.eat("}") {
+		if p.eat(";") {
+			continue
+		}
+		body.Properties = append(body.Properties, p.parseClassMember())
+	}
+	body.Node = p.node(m)
+	return body
+}
+
+// isModifier reports whether the current token is a modifier such as
+// static, async, get or set rather than the name of a member.
+func (p *parser) isModifier(name string) bool {
+	if !p.isKeyword(name) {
+		return false
+	}
+	t := p.peek()
+	if name == "async" && t.newline {
+		return false
+	}
+	return !isPunctuator(t, "(") && !isPunctuator(t, "=") && !isPunctuator(t, ";") &&
+		!isPunctuator(t, "}") && !isPunctuator(t, ",") && !isPunctuator(t, ":") && t.kind != tokenEOF
+}
+
+func (p *parser) parseClassMember() ClassProperty {
+	m := p.mark()
+	var static bool
+	if p.isModifier("static") {
+		p.next()
+		static = true
+		if p.is("{") {
+			p.errorf(m.offset, "static blocks are not supported")
+		}
+	}
+	kind, async, generator := p.parseMethodModifiers()
+	keyToken := p.tok
+	key, computed := p.parsePropertyKey()
+
+	if kind != "" || async || generator || p.is("(") {
+		if kind == "" && !static && !computed && isKeyName(key, keyToken, "constructor") {
+			kind = "constructor"
+		}
+		f := p.parseMethod(m, async, generator)
+		return &MethodDefinition{Static: static, Computed: computed, Key: key, Value: *f, Kind: kind, Node: p.node(m)}
+	}
+
+	def := &PropertyDefinition{Static: static, Computed: computed, Key: key}
+	if p.eat("=") {
+		saved := p.ctx
+		p.ctx = funcContext{inFunction: true}
+		def.Value = p.parseAssignment()
+		p.ctx = saved
+	}
+	p.semicolon()
+	def.Node = p.node(m)
+	return def
+}
+
+// parseMethodModifiers parses the get, set and async modifiers and the
+// generator star of methods.
+func (p *parser) parseMethodModifiers() (kind string, async, generator bool) {
+	switch {
+	case p.isModifier("get"), p.isModifier("set"):
+		kind = p.tok.value
+		p.next()
+	case p.isModifier("async"):
+		async = true
+		p.next()
+	}
+	generator = p.eat("*")
+	return
+}
+
+func methodType(async, generator bool) FunctionType {
+	switch {
+	case async && generator:
+		return FunctionTypeAsyncGenerator
+	case async:
+		return FunctionTypeAsync
+	case generator:
+		return FunctionTypeGenerator
+	}
+	return FunctionTypeNormal
+}
+
+func isKeyName(key PropertyKey, t token, name string) bool {
+	switch k := key.(type) {
+	case *Identifier:
+		return k.Name == name && t.kind == tokenIdentifier
+	case *LiteralValueString:
+		return string(*k) == name
+	}
+	return false
+}
+
+// parseMethod parses the parameters and body of a method. The generator
+// star has been consumed by parseMethodModifiers and is put back by
+// functionType.
+func (p *parser) parseMethod(m marker, async, generator bool) *FunctionExpression {
+	f := &FunctionExpression{FunctionType: methodType(async, generator)}
+	f.Params, f.Body = p.parseFunctionRest(f.FunctionType.context())
+	f.Node = p.node(m)
+	return f
+}
+
+// Expressions
+
+func (p *parser) parseExpression() Expression {
+	m := p.mark()
+	expr := p.parseAssignment()
+	if !p.is(",") {
+		return expr
+	}
+	seq := &SequenceExpression{Expressions: []Expression{expr}}
+	for p.eat(",") {
+		seq.Expressions = append(seq.Expressions, p.parseAssignment())
+	}
+	seq.Node = p.node(m)
+	return seq
+}
+
+func isAssignmentOperator(op string) bool {
+	switch op {
+	case "=", "+=", "-=", "*=", "/=", "%=", "**=", "<<=", ">>=", ">>>=",
+		"&=", "|=", "^=", "&&=", "||=", "??=":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseAssignment() Expression {
+	if arrow := p.tryArrowFunction(); arrow != nil {
+		return arrow
+	}
+	if p.isKeyword("yield") && p.ctx.generator {
+		return p.parseYield()
+	}
+
+	m := p.mark()
+	left := p.parseConditional()
+	if p.tok.kind != tokenPunctuator || !isAssignmentOperator(p.tok.value) {
+		return left
+	}
+	switch left.(type) {
+	case *Identifier, *StaticMemberExpression, *ComputedMemberExpression:
+	case *ObjectExpression, *ArrayExpression:
+		p.errorf(m.offset, "destructuring assignment is not supported")
+	default:
+		p.errorf(m.offset, "invalid assignment target")
+	}
+	op := assignmentOperator(p.tok.value)
+	p.next()
+	right := p.parseAssignment()
+	return &AssignmentExpression{Operator: op, Left: left, Right: right, Node: p.node(m)}
+}
+
+func (p *parser) parseYield() Expression {
+	m := p.mark()
+	p.next()
+	y := &YieldExpression{}
+	if !p.tok.newline {
+		y.Delegate = p.eat("*")
+		if y.Delegate || !p.is(")") && !p.is("]") && !p.is("}") && !p.is(",") && !p.is(";") &&
+			!p.is(":") && p.tok.kind != tokenEOF && !p.isKeyword("in") {
+			y.Argument = p.parseAssignment()
+		}
+	}
+	y.Node = p.node(m)
+	return y
+}
+
+// tryArrowFunction parses an arrow function if one starts at the current
+// token and returns nil otherwise.
+func (p *parser) tryArrowFunction() Expression {
+	m := p.mark()
+	var async bool
+	switch {
+	case p.isKeyword("async") && !isPunctuator(p.peek(), "=>"):
+		// async => 1 has a parameter named async
+		t := p.peek()
+		if t.newline || t.kind != tokenIdentifier && !isPunctuator(t, "(") {
+			return nil
+		}
+		async = true
+	case p.is("("):
+	case p.isIdentifier() || p.tok.kind == tokenPlaceholder:
+		if t := p.peek(); !isPunctuator(t, "=>") || t.newline {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	var params []FunctionParameter
+	ok := p.try(func() {
+		if async {
+			p.next()
+		}
+		if p.is("(") {
+			params = p.parseParams()
+		} else {
+			params = []FunctionParameter{p.parseBindingTarget()}
+		}
+		if !p.is("=>") || p.tok.newline {
+			p.unexpected()
+		}
+	})
+	if !ok {
+		return nil
+	}
+	p.next()
+
+	arrow := &ArrowFunctionExpression{Params: params, Async: async}
+	saved := p.ctx
+	p.ctx = funcContext{inFunction: true, async: async}
+	if p.is("{") {
+		arrow.Body = p.parseFunctionBody()
+	} else {
+		arrow.Expression = p.parseAssignment()
+	}
+	p.ctx = saved
+	arrow.Node = p.node(m)
+	return arrow
+}
+
+func (p *parser) parseConditional() Expression {
+	m := p.mark()
+	test := p.parseBinary(1)
+	if !p.eat("?") {
+		return test
+	}
+	noIn := p.noIn
+	p.noIn = false
+	consequent := p.parseAssignment()
+	p.noIn = noIn
+	p.expect(":")
+	alternate := p.parseAssignment()
+	return &ConditionalExpression{Test: test, Consequent: consequent, Alternate: alternate, Node: p.node(m)}
+}
+
+var binaryPrecedence = map[string]int{
+	"??": 1,
+	"||": 2,
+	"&&": 3,
+	"|":  4,
+	"^":  5,
+	"&":  6,
+	"==": 7, "!=": 7, "===": 7, "!==": 7,
+	"<": 8, ">": 8, "<=": 8, ">=": 8, "instanceof": 8, "in": 8,
+	"<<": 9, ">>": 9, ">>>": 9,
+	"+": 10, "-": 10,
+	"*": 11, "/": 11, "%": 11,
+	"**": 12,
+}
+
+func (p *parser) precedence() int {
+	switch p.tok.kind {
+	case tokenPunctuator:
+		return binaryPrecedence[p.tok.value]
+	case tokenIdentifier:
+		if p.isKeyword("instanceof") || p.isKeyword("in") && !p.noIn {
+			return binaryPrecedence[p.tok.value]
+		}
+	}
+	return 0
+}
+
+// parseBinary parses binary and logical expressions whose operators bind at
+// least as tightly as minPrec.
+func (p *parser) parseBinary(minPrec int) Expression {
+	expr, _ := p.parseBinaryOp(minPrec)
+	return expr
+}
+
+// parseBinaryOp is parseBinary, also reporting whether the expression is a
+// binary or logical expression rather than an operand, which may be
+// parenthesized.
+func (p *parser) parseBinaryOp(minPrec int) (left Expression, binary bool) {
+	m := p.mark()
+	paren := p.is("(")
+	left = p.parseUnary()
+	for {
+		prec := p.precedence()
+		if prec == 0 || prec < minPrec {
+			return left, binary
+		}
+		op := p.tok.value
+		if op == "**" && !binary && !paren && precedence(left) < precPostfix {
+			p.errorf(p.tok.start, "unary expression before ** needs parentheses")
+		}
+		start := p.tok.start
+		p.next()
+		var right Expression
+		var rightBinary bool
+		if op == "**" {
+			// right-associative
+			right, rightBinary = p.parseBinaryOp(prec)
+		} else {
+			right, rightBinary = p.parseBinaryOp(prec + 1)
+		}
+		switch op {
+		case "||", "&&", "??":
+			if binary && mixesNullish(logicalOperator(op), left) || rightBinary && mixesNullish(logicalOperator(op), right) {
+				p.errorf(start, "?? mixed with || or && needs parentheses")
+			}
+			left = &LogicalExpression{Operator: logicalOperator(op), Left: left, Right: right, Node: p.node(m)}
+		default:
+			left = &BinaryExpression{Operator: binaryOperator(op), Left: left, Right: right, Node: p.node(m)}
+		}
+		binary = true
+	}
+}
+
+var unaryOperators = map[string]UnaryOperatorType{
+	"!":      UnaryOperatorTypeNot,
+	"~":      UnaryOperatorTypeBitwiseNot,
+	"+":      UnaryOperatorTypePlus,
+	"-":      UnaryOperatorTypeMinus,
+	"typeof": UnaryOperatorTypeTypeof,
+	"void":   UnaryOperatorTypeVoid,
+	"delete": UnaryOperatorTypeDelete,
+	"++":     UnaryOperatorTypeIncrementPrefix,
+	"--":     UnaryOperatorTypeDecrementPrefix,
+}
+
+func (p *parser) parseUnary() Expression {
+	m := p.mark()
+	if op, ok := unaryOperators[p.tok.value]; ok && (p.tok.kind == tokenPunctuator || p.tok.kind == tokenIdentifier && !p.tok.escaped) {
+		p.next()
+		arg := p.parseUnary()
+		if op == UnaryOperatorTypeIncrementPrefix || op == UnaryOperatorTypeDecrementPrefix {
+			p.checkUpdateTarget(m, arg)
+		}
+		return &UnaryExpression{Operator: op, Argument: arg, Node: p.node(m)}
+	}
+	if p.isKeyword("await") && p.ctx.async {
+		p.next()
+		arg := p.parseUnary()
+		return &AwaitExpression{Arguement: arg, Node: p.node(m)}
+	}
+
+	expr := p.parseLeftHandSide(true)
+	if (p.is("++") || p.is("--")) && !p.tok.newline {
+		p.checkUpdateTarget(m, expr)
+		op := UnaryOperatorTypeIncrementPostfix
+		if p.tok.value == "--" {
+			op = UnaryOperatorTypeDecrementPostfix
+		}
+		p.next()
+		return &UnaryExpression{Operator: op, Argument: expr, Node: p.node(m)}
+	}
+	return expr
+}
+
+func (p *parser) checkUpdateTarget(m marker, expr Expression) {
+	switch expr.(type) {
+	case *Identifier, *StaticMemberExpression, *ComputedMemberExpression:
+		return
+	}
+	p.errorf(m.offset, "invalid update target")
+}
+
+// parseLeftHandSide parses member, call and new expressions. Calls are
+// not parsed if allowCall is false, as in the callee of new.
+func (p *parser) parseLeftHandSide(allowCall bool) Expression {
+	m := p.mark()
+	var expr Expression
+	switch {
+	case p.isKeyword("new"):
+		expr = p.parseNew()
+	case p.isKeyword("super"):
+		p.next()
+		expr = &Super{Node: p.node(m)}
+		if !p.is("(") && !p.is(".") && !p.is("[") {
+			p.unexpected()
+		}
+	case p.isKeyword("import"):
+		meta := p.parseIdentifierName()
+		if p.is("(") {
+			if !allowCall {
+				p.errorf(m.offset, "cannot use new with import()")
+			}
+			callee := &Import{Node: meta.Node}
+			p.next()
+			args := []ArgumentListElement{p.parseAssignment()}
+			if p.eat(",") && !p.is(")") {
+				// import attributes
+				args = append(args, p.parseAssignment())
+				p.eat(",")
+			}
+			p.expect(")")
+			expr = &CallExpression{Callee: callee, Arguments: args, Node: p.node(m)}
+			break
+		}
+		p.expect(".")
+		prop := p.parseIdentifierName()
+		if prop.Name != "meta" {
+			p.errorf(m.offset, "unexpected import.%s", prop.Name)
+		}
+		expr = &MetaProperty{Meta: *meta, Property: *prop, Node: p.node(m)}
+	default:
+		expr = p.parsePrimary()
+	}
+
+	var chain bool
+	for {
+		switch {
+		case p.is("."):
+			p.next()
+			prop := p.parseName()
+			expr = &StaticMemberExpression{Object: expr, Property: prop, Node: p.node(m)}
+		case p.is("?."):
+			if !allowCall {
+				p.errorf(p.tok.start, "optional chain in new expression")
+			}
+			chain = true
+			p.next()
+			switch {
+			case p.is("("):
+				args := p.parseArguments()
+				expr = &CallExpression{Callee: expr, Arguments: args, Optional: true, Node: p.node(m)}
+			case p.eat("["):
+				prop := p.parseExpressionAllowIn()
+				p.expect("]")
+				expr = &ComputedMemberExpression{Object: expr, Property: prop, Optional: true, Node: p.node(m)}
+			default:
+				prop := p.parseName()
+				expr = &StaticMemberExpression{Object: expr, Property: prop, Optional: true, Node: p.node(m)}
+			}
+		case p.is("["):
+			p.next()
+			prop := p.parseExpressionAllowIn()
+			p.expect("]")
+			expr = &ComputedMemberExpression{Object: expr, Property: prop, Node: p.node(m)}
+		case p.is("(") && allowCall:
+			args := p.parseArguments()
+			expr = &CallExpression{Callee: expr, Arguments: args, Node: p.node(m)}
+		case p.tok.kind == tokenTemplate:
+			if chain {
+				p.errorf(p.tok.start, "tagged template in optional chain")
+			}
+			quasi := p.parseTemplateLiteral()
+			expr = &TaggedTemplateExpression{Tag: expr, Quasi: *quasi, Node: p.node(m)}
+		default:
+			if chain {
+				expr = &ChainExpression{Expression: expr.(ChainElement), Node: p.node(m)}
+			}
+			return expr
+		}
+	}
+}
+
+func (p *parser) parseExpressionAllowIn() Expression {
+	noIn := p.noIn
+	p.noIn = false
+	defer func() { p.noIn = noIn }()
+	return p.parseExpression()
+}
+
+func (p *parser) parseNew() Expression {
+	m := p.mark()
+	meta := p.parseIdentifierName()
+	if p.eat(".") {
+		prop := p.parseIdentifierName()
+		if prop.Name != "target" || !p.ctx.inFunction {
+			p.errorf(m.offset, "unexpected new.%s", prop.Name)
+		}
+		return &MetaProperty{Meta: *meta, Property: *prop, Node: p.node(m)}
+	}
+	callee := p.parseLeftHandSide(false)
+	var args []ArgumentListElement
+	if p.is("(") {
+		args = p.parseArguments()
+	}
+	return &NewExpression{Callee: callee, Arguments: args, Node: p.node(m)}
+}
+
+func (p *parser) parseArguments() (args []ArgumentListElement) {
+	p.expect("(")
+	noIn := p.noIn
+	p.noIn = false
+	for !p.eat(")") {
+		switch {
+		case p.is("..."):
+			args = append(args, p.parseSpread())
+		case p.tok.kind == tokenPlaceholder && p.isListEnd(p.peek(), ")"):
+			args = append(args, p.placeholderArguments()...)
+		default:
+			args = append(args, p.parseAssignment())
+		}
+		if !p.is(")") {
+			p.expect(",")
+		}
+	}
+	p.noIn = noIn
+	return
+}
+
+func (p *parser) parseSpread() *SpreadElement {
+	m := p.mark()
+	p.expect("...")
+	arg := p.parseAssignment()
+	return &SpreadElement{Argument: arg, Node: p.node(m)}
+}
+
+// isListEnd reports whether t ends an element of a list closed by
+// closing.
+func (p *parser) isListEnd(t token, closing string) bool {
+	return isPunctuator(t, ",") || isPunctuator(t, closing)
+}
+
+func (p *parser) parsePrimary() Expression {
+	m := p.mark()
+	switch p.tok.kind {
+	case tokenIdentifier:
+		if !p.tok.escaped {
+			switch p.tok.value {
+			case "this":
+				p.next()
+				return &ThisExpression{Node: p.node(m)}
+			case "null":
+				p.next()
+				return LiteralValueNull
+			case "true", "false":
+				b := p.tok.value == "true"
+				p.next()
+				return BoolLiteral(b)
+			case "function":
+				p.next()
+				return p.parseFunction(m, false, true)
+			case "async":
+				if p.isAsyncFunction() {
+					p.next()
+					p.next()
+					return p.parseFunction(m, true, true)
+				}
+			case "class":
+				return p.parseClass(true)
+			}
+		}
+		return p.parseIdentifier()
+	case tokenNumber:
+		n := p.tok.number
+		p.next()
+		return NumberLiteral(n)
+	case tokenString:
+		s := p.tok.value
+		p.next()
+		return StringLiteral(s)
+	case tokenTemplate:
+		return p.parseTemplateLiteral()
+	case tokenPlaceholder:
+		return p.placeholderExpression()
+	case tokenPunctuator:
+		switch p.tok.value {
+		case "(":
+			p.next()
+			expr := p.parseExpressionAllowIn()
+			p.expect(")")
+			return expr
+		case "[":
+			return p.parseArrayExpression()
+		case "{":
+			return p.parseObjectExpression()
+		case "/", "/=":
+			p.errorf(p.tok.start, "regular expression literals are not supported")
+		case "#":
+			p.errorf(p.tok.start, "private names are not supported")
+		}
+	}
+	p.unexpected()
+	return nil
+}
+
+func (p *parser) parseArrayExpression() *ArrayExpression {
+	m := p.mark()
+	p.expect("[")
+	noIn := p.noIn
+	p.noIn = false
+	arr := &ArrayExpression{}
+	for !p.eat("]") {
+		switch {
+		case p.is(","):
+			arr.Elements = append(arr.Elements, nil)
+			p.next()
+			continue
+		case p.is("..."):
+			arr.Elements = append(arr.Elements, p.parseSpread())
+		case p.tok.kind == tokenPlaceholder && p.isListEnd(p.peek(), "]"):
+			arr.Elements = append(arr.Elements, p.placeholderElements()...)
+		default:
+			arr.Elements = append(arr.Elements, p.parseAssignment())
+		}
+		if !p.is("]") {
+			p.expect(",")
+		}
+	}
+	p.noIn = noIn
+	arr.Node = p.node(m)
+	return arr
+}
+
+func (p *parser) parseObjectExpression() *ObjectExpression {
+	m := p.mark()
+	p.expect("{")
+	noIn := p.noIn
+	p.noIn = false
+	obj := &ObjectExpression{}
+	for !p.eat("}") {
+		switch {
+		case p.is("..."):
+			obj.Properties = append(obj.Properties, p.parseSpread())
+		case p.tok.kind == tokenPlaceholder && p.isListEnd(p.peek(), "}"):
+			obj.Properties = append(obj.Properties, p.placeholderProperties()...)
+		default:
+			obj.Properties = append(obj.Properties, p.parseProperty())
+		}
+		if !p.is("}") {
+			p.expect(",")
+		}
+	}
+	p.noIn = noIn
+	obj.Node = p.node(m)
+	return obj
+}
+
+func (p *parser) parseProperty() *Property {
+	m := p.mark()
+	kind, async, generator := p.parseMethodModifiers()
+	keyToken := p.tok
+	key, computed := p.parsePropertyKey()
+	prop := &Property{Key: key, Computed: computed, Kind: kind}
+
+	switch {
+	case kind != "" || async || generator || p.is("("):
+		prop.Value = p.parseMethod(m, async, generator)
+		prop.Method = kind == ""
+	case p.eat(":"):
+		prop.Value = p.parseAssignment()
+	default:
+		id, ok := key.(*Identifier)
+		if !ok || computed || keyToken.kind != tokenIdentifier && keyToken.kind != tokenPlaceholder {
+			p.unexpected()
+		}
+		if p.is("=") {
+			p.errorf(p.tok.start, "destructuring assignment is not supported")
+		}
+		if keyToken.kind == tokenIdentifier && !keyToken.escaped && reservedWords[id.Name] {
+			p.errorf(keyToken.start, "unexpected token %s", id.Name)
+		}
+		prop.Value = &Identifier{Name: id.Name, Node: id.Node}
+		prop.ShortHand = true
+	}
+	prop.Node = p.node(m)
+	return prop
+}
+
+func (p *parser) parseTemplateLiteral() *TemplateLiteral {
+	m := p.mark()
+	lit := &TemplateLiteral{}
+	for {
+		t := p.tok
+		if t.kind != tokenTemplate {
+			p.unexpected()
+		}
+		lit.Quasis = append(lit.Quasis, TemplateElement{Raw: t.raw, Cooked: t.value, Tail: t.tail, Node: p.tokenNode(t)})
+		p.next()
+		if t.tail {
+			break
+		}
+		lit.Expressions = append(lit.Expressions, p.parseExpressionAllowIn())
+		if !p.is("}") {
+			p.unexpected()
+		}
+		// rescan the source after } as the continuation of the template
+		t = p.tok
+		t.kind = tokenTemplate
+		t.value, t.raw, t.tail = p.s.scanTemplate()
+		t.end, t.endPos = p.s.offset, p.s.pos()
+		p.tok = t
+	}
+	lit.Node = p.node(m)
+	return lit
+}
+
+// Modules
+
+func (p *parser) parseModuleSource() string {
+	switch p.tok.kind {
+	case tokenString:
+		s := p.tok.value
+		p.next()
+		return s
+	case tokenPlaceholder:
+		return p.placeholderSource()
+	}
+	p.unexpected()
+	return ""
+}
+
+func (p *parser) parseImportDeclaration() *ImportDeclaration {
+	m := p.mark()
+	p.next()
+	decl := &ImportDeclaration{}
+	if p.tok.kind == tokenString || p.tok.kind == tokenPlaceholder && !isKeyword(p.peek(), "from") && !isPunctuator(p.peek(), ",") {
+		decl.Source = p.parseModuleSource()
+		p.semicolon()
+		decl.Node = p.node(m)
+		return decl
+	}
+
+	if !p.is("{") && !p.is("*") {
+		sm := p.mark()
+		local := p.parseBindingName()
+		decl.Specifiers = append(decl.Specifiers, &ImportDefaultSpecifier{Local: local, Node: p.node(sm)})
+		if !p.eat(",") {
+			goto from
+		}
+	}
+	if p.is("*") {
+		sm := p.mark()
+		p.next()
+		p.expectKeyword("as")
+		local := p.parseBindingName()
+		decl.Specifiers = append(decl.Specifiers, &ImportNamespaceSpecifier{Local: local, Node: p.node(sm)})
+	} else {
+		sm := p.mark()
+		p.expect("{")
+		spec := &ImportSpecifier{}
+		for !p.eat("}") {
+			nm := p.mark()
+			named := NamedImport{Imported: p.parseName()}
+			if p.isKeyword("as") {
+				p.next()
+				named.Local = p.parseBindingName()
+			} else if reservedWords[named.Imported.Name] {
+				p.errorf(nm.offset, "unexpected token %s", named.Imported.Name)
+			}
+			named.Node = p.node(nm)
+			spec.NamedImports = append(spec.NamedImports, named)
+			if !p.is("}") {
+				p.expect(",")
+			}
+		}
+		spec.Node = p.node(sm)
+		decl.Specifiers = append(decl.Specifiers, spec)
+	}
+
+from:
+	p.expectKeyword("from")
+	decl.Source = p.parseModuleSource()
+	p.semicolon()
+	decl.Node = p.node(m)
+	return decl
+}
+
+// parseBindingName parses an identifier that declares a binding.
+func (p *parser) parseBindingName() *Identifier {
+	if p.tok.kind == tokenPlaceholder {
+		return p.placeholderName()
+	}
+	return p.parseIdentifier()
+}
+
+func (p *parser) parseExportDeclaration() Declaration {
+	m := p.mark()
+	p.next()
+	switch {
+	case p.isKeyword("default"):
+		p.next()
+		var decl ExportableDefaultDeclaration
+		switch {
+		case p.isKeyword("function") || p.isAsyncFunction():
+			decl = p.parseFunctionDeclaration(true)
+		case p.isKeyword("class"):
+			decl = p.parseClassDeclaration(true)
+		default:
+			decl = p.parseAssignment()
+			p.semicolon()
+		}
+		return &ExportDefaultDeclaration{Declaration: decl, Node: p.node(m)}
+	case p.is("*"):
+		p.next()
+		decl := &ExportAllDeclaration{}
+		if p.isKeyword("as") {
+			p.next()
+			decl.Exported = p.parseName()
+		}
+		p.expectKeyword("from")
+		decl.Source = StringLiteral(p.parseModuleSource())
+		p.semicolon()
+		decl.Node = p.node(m)
+		return decl
+	case p.is("{"):
+		p.next()
+		decl := &ExportNamedDeclaration{}
+		for !p.eat("}") {
+			sm := p.mark()
+			spec := ExportSpecifier{Exported: p.parseName()}
+			if p.isKeyword("as") {
+				p.next()
+				spec.Local = spec.Exported
+				spec.Exported = p.parseName()
+			}
+			spec.Node = p.node(sm)
+			decl.Specifiers = append(decl.Specifiers, spec)
+			if !p.is("}") {
+				p.expect(",")
+			}
+		}
+		if p.isKeyword("from") {
+			p.next()
+			decl.Source = StringLiteral(p.parseModuleSource())
+		}
+		p.semicolon()
+		decl.Node = p.node(m)
+		return decl
+	case p.isKeyword("var") || p.isKeyword("let") || p.isKeyword("const"):
+		decl := p.parseVariableDeclaration()
+		p.semicolon()
+		return &ExportNamedDeclaration{Declaration: decl, Node: p.node(m)}
+	case p.isKeyword("function") || p.isAsyncFunction():
+		decl := p.parseFunctionDeclaration(false)
+		return &ExportNamedDeclaration{Declaration: decl, Node: p.node(m)}
+	case p.isKeyword("class"):
+		decl := p.parseClassDeclaration(false)
+		return &ExportNamedDeclaration{Declaration: decl, Node: p.node(m)}
+	}
+	p.unexpected()
+	return nil
+}
@@ -0,0 +1,199 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []string{
+		`import Amplify from "@aws-amplify/core";
+import { Auth } from "@aws-amplify/auth";
+export const USER_POOL_CLIENT_ID_TODOUSERS = process.env.REACT_APP_USER_POOL_CLIENT_ID_TODOUSERS;
+export const config = {
+  Auth: {
+    region: USER_POOL_REGION_TODOUSERS,
+    storage: localStorage,
+  },
+  SecondaryAuths: null,
+  API: {
+    custom_header: async () => {
+      return {
+        Authorization: "Bearer " + (await Auth.currentSession()).getAccessToken().getJwtToken(),
+      };
+    },
+  },
+};
+Amplify.configure(config);`,
+		`import * as ns from "y";
+import d, { e as f, default as g } from "z";
+export { a, b as c };
+export * from "x";`,
+		`if (a) {
+  b;
+} else if (c) {
+  d;
+} else {
+  e;
+}`,
+		`switch (a) {
+  case 1.000000:
+    b();
+  default:
+    c();
+}`,
+		`try {
+  x;
+} catch (err) {
+  y;
+} finally {
+  z;
+}`,
+		`class A extends B {
+  static x = 1.000000;
+  constructor() {
+    super();
+  }
+  get y() {
+    return this.x;
+  }
+  async *gen() {
+    yield* z;
+  }
+}`,
+		`x = class extends mixin(A) {
+  static y = 1.000000;
+};`,
+		"x = tag`a${b}c`;",
+		`a?.b.c(d)?.[e];`,
+		`x = async (a, b) => ({
+  a,
+});`,
+	}
+	for _, src := range tests {
+		prog, err := Parse("test.js", src)
+		if assert.NoError(t, err, src) {
+			assert.Equal(t, src, prog.String())
+		}
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	prog, err := Parse("test.js", "const fs = require(\"fs\");\nfor (const k in o) {\n  f(k, ...a);\n}")
+	assert.NoError(t, err)
+	expect := &Program{
+		Name: "test.js",
+		Body: []StatementListItem{
+			&VariableDeclaration{
+				Kind: VariableDeclarationTypeConst,
+				Declarations: []VariableDeclarator{
+					{
+						ID: &Identifier{Name: "fs"},
+						Init: &CallExpression{
+							Callee:    &Identifier{Name: "require"},
+							Arguments: []ArgumentListElement{StringLiteral("fs")},
+						},
+					},
+				},
+			},
+			&ForInStatement{
+				Left: &VariableDeclaration{
+					Kind:         VariableDeclarationTypeConst,
+					Declarations: []VariableDeclarator{{ID: &Identifier{Name: "k"}}},
+				},
+				Right: &Identifier{Name: "o"},
+				Body: BlockStatement{
+					Items: []Statement{
+						&ExpressionStatement{
+							Expression: &CallExpression{
+								Callee: &Identifier{Name: "f"},
+								Arguments: []ArgumentListElement{
+									&Identifier{Name: "k"},
+									&SpreadElement{Argument: &Identifier{Name: "a"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Empty(t, Diff(expect, prog))
+}
+
+func TestParseNestedDeclarations(t *testing.T) {
+	prog, err := Parse("test.js", "function f() {\n  const a = 1;\n  if (a) {\n    let b = a;\n  }\n}")
+	if assert.NoError(t, err) {
+		body := prog.Body[0].(*FunctionDeclaration).Body.Items
+		assert.IsType(t, &VariableDeclaration{}, body[0])
+		assert.IsType(t, &VariableDeclaration{}, body[1].(*IfStatement).Consequent.(*BlockStatement).Items[0])
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	prog, err := Parse("test.js", "let a = 1;\nfoo(a);")
+	assert.NoError(t, err)
+	call := prog.Body[1].(*ExpressionStatement).Expression.(*CallExpression)
+	assert.Equal(t, &Node{
+		Location: &SourceLocation{Start: Position{2, 0}, End: Position{2, 6}, Source: "test.js"},
+		Range:    &Range{11, 17},
+	}, call.Node)
+	arg := call.Arguments[0].(*Identifier)
+	assert.Equal(t, Position{2, 4}, arg.Location.Start)
+	assert.Equal(t, &Range{15, 16}, arg.Range)
+	assert.Equal(t, &Range{0, 18}, prog.Range)
+}
+
+func TestParseExpr(t *testing.T) {
+	tests := []struct {
+		Src    string
+		Expect string
+	}{
+		{`a ?? (b || c)`, `a ?? (b || c)`},
+		{`(a && b) ?? c`, `(a && b) ?? c`},
+		{`(-c) ** 2`, `(-c) ** 2.000000`},
+		{`a-- ** -b`, `a-- ** -b`},
+		{`a = b ? c : d`, `a = b? c: d`},
+		{`x => x + 1`, `(x) => x + 1.000000`},
+		{`async => async`, `(async) => async`},
+		{`async x => x`, `async (x) => x`},
+		{`0x1F + 1e3 + .5 + 0b11 + 1_000`, `31.000000 + 1000.000000 + 0.500000 + 3.000000 + 1000.000000`},
+		{`'a\nbA\x41\u{1F600}'`, `"a\nbAA😀"`},
+		{`new Foo.Bar(1)`, `new Foo.Bar(1.000000)`},
+		{`typeof a === "undefined"`, `typeof a === "undefined"`},
+	}
+	for _, test := range tests {
+		expr, err := ParseExpr(test.Src)
+		if assert.NoError(t, err, test.Src) {
+			assert.Equal(t, test.Expect, expr.String(), test.Src)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		Src    string
+		Expect string
+	}{
+		{`x = /re/`, `test.js:1:4: regular expression literals are not supported`},
+		{`({a} = b)`, `test.js:1:1: destructuring assignment is not supported`},
+		{`let x = 10n`, `test.js:1:8: BigInt literals are not supported`},
+		{`a +`, `test.js:1:3: unexpected end of input`},
+		{"let s = 'abc", `test.js:1:8: unterminated string literal`},
+		{"if (a) {\n  b c\n}", `test.js:2:4: unexpected token c`},
+		{`try {}`, `test.js:1:0: missing catch or finally after try`},
+		{`a ?? b || c`, `test.js:1:2: ?? mixed with || or && needs parentheses`},
+		{`a && b ?? c`, `test.js:1:7: ?? mixed with || or && needs parentheses`},
+		{`-c ** 2`, `test.js:1:3: unary expression before ** needs parentheses`},
+		{`async () => await a ** 2`, `test.js:1:20: unary expression before ** needs parentheses`},
+		// columns are counted in UTF-16 code units
+		{"x = '\U0001F600\u00e9' c", `test.js:1:10: unexpected token c`},
+	}
+	for _, test := range tests {
+		_, err := Parse("test.js", test.Src)
+		if assert.Error(t, err, test.Src) {
+			assert.Equal(t, test.Expect, err.Error())
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package goesprima
+
+// NodePath is a node together with its location in a tree: the parent
+// node, the parent field holding it and its index in that field. Paths are
+// built once for a whole tree by NewPath, so ancestry questions such as
+// "is this await inside an async arrow function?" don't require walking
+// the tree again.
+type NodePath struct {
+	Node   JSElement
+	Parent *NodePath
+	// Field is the name of the parent field containing Node, eg. "Body".
+	Field string
+	// Index is the position of Node in Field, or -1 if Field is not a list.
+	Index int
+
+	children []*NodePath
+	index    map[JSElement]*NodePath
+}
+
+// NewPath builds the paths of every node of the tree rooted at root and
+// returns the path of root. Value-typed children such as
+// ArrowFunctionExpression.Body are keyed by a pointer to the field, like
+// in Walk.
+func NewPath(root JSElement) *NodePath {
+	index := make(map[JSElement]*NodePath)
+	var stack []*NodePath
+	var top *NodePath
+
+	Apply(root, func(c *Cursor) bool {
+		n := c.Node()
+		if n == nil {
+			return false
+		}
+		p := &NodePath{
+			Node:  n,
+			Field: c.Name(),
+			Index: c.Index(),
+			index: index,
+		}
+		if len(stack) > 0 {
+			p.Parent = stack[len(stack)-1]
+			p.Parent.children = append(p.Parent.children, p)
+		} else {
+			p.Field = ""
+			top = p
+		}
+		index[n] = p
+		stack = append(stack, p)
+		return true
+	}, func(c *Cursor) bool {
+		stack = stack[:len(stack)-1]
+		return true
+	})
+	return top
+}
+
+// Lookup returns the path of node within the tree p belongs to, or nil if
+// node is not part of it. Nodes that appear more than once in a tree, such
+// as LiteralValueNull, resolve to their last occurrence.
+func (p *NodePath) Lookup(node JSElement) *NodePath {
+	return p.index[node]
+}
+
+// Root returns the path of the root of the tree.
+func (p *NodePath) Root() *NodePath {
+	for p.Parent != nil {
+		p = p.Parent
+	}
+	return p
+}
+
+// Children returns the paths of the direct children of the node in
+// traversal order.
+func (p *NodePath) Children() []*NodePath {
+	return p.children
+}
+
+// Container returns the paths of all nodes in the list containing the node,
+// including the node itself, or nil if the node is not part of a list.
+func (p *NodePath) Container() (out []*NodePath) {
+	if p.Parent == nil || p.Index < 0 {
+		return nil
+	}
+	for _, c := range p.Parent.children {
+		if c.Field == p.Field {
+			out = append(out, c)
+		}
+	}
+	return
+}
+
+// Siblings returns the paths of the other nodes in the list containing the
+// node.
+func (p *NodePath) Siblings() (out []*NodePath) {
+	for _, c := range p.Container() {
+		if c != p {
+			out = append(out, c)
+		}
+	}
+	return
+}
+
+// FindParent returns the closest ancestor for which f returns true, or nil.
+func (p *NodePath) FindParent(f func(*NodePath) bool) *NodePath {
+	for a := p.Parent; a != nil; a = a.Parent {
+		if f(a) {
+			return a
+		}
+	}
+	return nil
+}
+
+// IsDescendantOf reports whether ancestor is a strict ancestor of the node.
+func (p *NodePath) IsDescendantOf(ancestor JSElement) bool {
+	return p.FindParent(func(a *NodePath) bool {
+		return a.Node == ancestor
+	}) != nil
+}
+
+// Function returns the path of the closest enclosing function, or nil at
+// the top level.
+func (p *NodePath) Function() *NodePath {
+	return p.FindParent(func(a *NodePath) bool {
+		return IsFunction(a.Node)
+	})
+}
+
+// InFunction reports whether the node is inside a function body or
+// parameter list.
+func (p *NodePath) InFunction() bool {
+	return p.Function() != nil
+}
+
+// InLoop reports whether the node is inside a loop statement of the same
+// function.
+func (p *NodePath) InLoop() bool {
+	a := p.FindParent(func(a *NodePath) bool {
+		return IsLoop(a.Node) || IsFunction(a.Node)
+	})
+	return a != nil && IsLoop(a.Node)
+}
+
+// String returns the field path from the root to the node in the same
+// format as Change.Path, eg. Body[3].Declarations[0].Init.
+func (p *NodePath) String() string {
+	if p.Parent == nil {
+		return ""
+	}
+	s := joinPath(p.Parent.String(), p.Field)
+	if p.Index >= 0 {
+		s = indexPath(s, p.Index)
+	}
+	return s
+}
+
+// IsFunction reports whether n is a function declaration, function
+// expression or arrow function.
+func IsFunction(n JSElement) bool {
+	switch n.(type) {
+	case *FunctionDeclaration, *FunctionExpression, *ArrowFunctionExpression:
+		return true
+	}
+	return false
+}
+
+// IsLoop reports whether n is a for, for-in, for-of, while or do-while
+// statement.
+func IsLoop(n JSElement) bool {
+	switch n.(type) {
+	case *ForStatement, *ForInStatement, *ForOfStatement, *WhileStatement, *DoWhileStatement:
+		return true
+	}
+	return false
+}
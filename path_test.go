@@ -0,0 +1,66 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodePath(t *testing.T) {
+	await := &AwaitExpression{Arguement: &Identifier{Name: "session"}}
+	arrow := &ArrowFunctionExpression{
+		Async: true,
+		Body: BlockStatement{
+			Items: []Statement{
+				&WhileStatement{
+					Test: LiteralValueUndefined,
+					Body: &ExpressionStatement{Expression: await},
+				},
+			},
+		},
+	}
+	first := &Identifier{Name: "first"}
+	program := &Program{
+		Body: []StatementListItem{
+			&ExpressionStatement{Expression: first},
+			&VariableDeclaration{
+				Kind: VariableDeclarationTypeConst,
+				Declarations: []VariableDeclarator{
+					{ID: &Identifier{Name: "f"}, Init: arrow},
+				},
+			},
+			&EmptyStatement{},
+		},
+	}
+
+	root := NewPath(program)
+	assert.Same(t, program, root.Node)
+	assert.Len(t, root.Children(), 3)
+
+	p := root.Lookup(await)
+	assert.Equal(t, "Body[1].Declarations[0].Init.Body.Items[0].Body.Expression", p.String())
+	assert.True(t, p.IsDescendantOf(arrow))
+	assert.False(t, p.IsDescendantOf(first))
+	assert.True(t, p.InFunction())
+	assert.True(t, p.InLoop())
+	assert.True(t, p.Function().Node.(*ArrowFunctionExpression).Async)
+	assert.Same(t, root, p.Root())
+
+	decl := p.FindParent(func(a *NodePath) bool {
+		_, ok := a.Node.(*VariableDeclarator)
+		return ok
+	})
+	assert.Equal(t, "Declarations", decl.Field)
+	assert.Equal(t, 0, decl.Index)
+
+	p = root.Lookup(first)
+	assert.False(t, p.InFunction())
+	assert.False(t, p.InLoop())
+	assert.Nil(t, p.Siblings())
+	siblings := p.Parent.Siblings()
+	assert.Len(t, siblings, 2)
+	assert.Equal(t, 1, siblings[0].Index)
+	assert.IsType(t, &EmptyStatement{}, siblings[1].Node)
+
+	assert.False(t, root.Lookup(arrow).InLoop())
+}
@@ -0,0 +1,233 @@
+package goesprima
+
+import "math"
+
+// Precedence levels of expressions, from the loosest to the tightest
+// binding.
+const (
+	precSequence = iota
+	precAssign   // assignment, arrow functions, yield
+	precConditional
+	precOr // || and ??
+	precAnd
+	precBitOr
+	precBitXor
+	precBitAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precExponent
+	precUnary // prefix operators and await
+	precPostfix
+	precCall // calls, members, new with arguments and tagged templates
+	precPrimary
+)
+
+// binaryLevel returns the precedence level of a binary operator, shifting
+// the parser's precedences, which start at 2 for ||.
+func binaryLevel(op binaryOperator) int {
+	return binaryPrecedence[string(op)] + precOr - 2
+}
+
+// precedence returns the precedence level of e.
+func precedence(e Expression) int {
+	switch v := e.(type) {
+	case *SequenceExpression:
+		return precSequence
+	case *AssignmentExpression, *ArrowFunctionExpression, *YieldExpression:
+		return precAssign
+	case *ConditionalExpression:
+		return precConditional
+	case *LogicalExpression:
+		if v.Operator == LogicalOperatorAnd {
+			return precAnd
+		}
+		return precOr
+	case *BinaryExpression:
+		return binaryLevel(v.Operator)
+	case *AwaitExpression:
+		return precUnary
+	case *UnaryExpression:
+		if isPostfix(v.Operator) {
+			return precPostfix
+		}
+		return precUnary
+	case *LiteralValueNumber, *LiteralValueBigFloat:
+		// negative numbers are printed with a minus sign
+		if isNegative(v) {
+			return precUnary
+		}
+	case *CallExpression, *ComputedMemberExpression, *StaticMemberExpression,
+		*NewExpression, *TaggedTemplateExpression, *ChainExpression:
+		return precCall
+	}
+	return precPrimary
+}
+
+func isPostfix(op UnaryOperatorType) bool {
+	return op == UnaryOperatorTypeIncrementPostfix || op == UnaryOperatorTypeDecrementPostfix
+}
+
+// mixesNullish reports whether a ?? operand is a || or && expression or the
+// other way around, which must be parenthesized.
+func mixesNullish(op logicalOperator, e Expression) bool {
+	l, ok := e.(*LogicalExpression)
+	return ok && (op == LogicalOperatorNullishCoelescing) != (l.Operator == LogicalOperatorNullishCoelescing)
+}
+
+// binaryMin returns the minimum precedence of the operands of a binary
+// expression. Exponentiation is right-associative and its base can't be a
+// unary expression.
+func binaryMin(op binaryOperator) (left, right int) {
+	p := binaryLevel(op)
+	if op == BinaryOperatorExponent {
+		return precPostfix, p
+	}
+	return p, p + 1
+}
+
+// objectNeedsParens reports whether the object of a member expression, the
+// callee of a call or the tag of a tagged template must be parenthesized.
+// Optional chains are, as they would otherwise extend to the member.
+func objectNeedsParens(e Expression) bool {
+	if _, ok := e.(*ChainExpression); ok {
+		return true
+	}
+	return precedence(e) < precCall
+}
+
+// newCalleeNeedsParens reports whether the callee of a new expression must
+// be parenthesized, as it can't contain a call outside of parentheses.
+func newCalleeNeedsParens(e Expression) bool {
+	for c := e; ; {
+		switch v := c.(type) {
+		case *StaticMemberExpression:
+			c = v.Object
+			continue
+		case *ComputedMemberExpression:
+			c = v.Object
+			continue
+		case *TaggedTemplateExpression:
+			c = v.Tag
+			continue
+		case *CallExpression, *ChainExpression:
+			return true
+		}
+		return objectNeedsParens(e)
+	}
+}
+
+// startsWithSign reports whether e is printed starting with sign, + or -.
+func startsWithSign(e Expression, sign byte) bool {
+	switch v := e.(type) {
+	case *UnaryExpression:
+		op := string(v.Operator)
+		return !isPostfix(v.Operator) && op[0] == sign
+	case *LiteralValueNumber, *LiteralValueBigFloat:
+		return sign == '-' && isNegative(v)
+	}
+	return false
+}
+
+func isNegative(l Expression) bool {
+	switch v := l.(type) {
+	case *LiteralValueNumber:
+		return math.Signbit(float64(*v))
+	case *LiteralValueBigFloat:
+		return bigFloat(v).Signbit()
+	}
+	return false
+}
+
+// leftOperand returns the operand printed first by e without parentheses,
+// or nil if there is none.
+func leftOperand(e Expression) Expression {
+	var left Expression
+	min := precPrimary
+	switch v := e.(type) {
+	case *SequenceExpression:
+		if len(v.Expressions) > 0 {
+			left, min = v.Expressions[0], precAssign
+		}
+	case *AssignmentExpression:
+		left, min = v.Left, precSequence
+	case *ConditionalExpression:
+		left, min = v.Test, precConditional+1
+	case *LogicalExpression:
+		if mixesNullish(v.Operator, v.Left) {
+			return nil
+		}
+		left, min = v.Left, precedence(v)
+	case *BinaryExpression:
+		left = v.Left
+		min, _ = binaryMin(v.Operator)
+	case *UnaryExpression:
+		if isPostfix(v.Operator) {
+			left, min = v.Argument, precPostfix
+		}
+	case *CallExpression:
+		left, min = v.Callee, precCall
+	case *StaticMemberExpression:
+		left, min = v.Object, precCall
+	case *ComputedMemberExpression:
+		left, min = v.Object, precCall
+	case *TaggedTemplateExpression:
+		left, min = v.Tag, precCall
+	case *ChainExpression:
+		return v.Expression.(Expression)
+	}
+	if left == nil || precedence(left) < min {
+		return nil
+	}
+	if _, ok := left.(*ChainExpression); ok && min == precCall {
+		return nil
+	}
+	return left
+}
+
+// startsWith reports whether the first token printed for e is that of an
+// expression satisfying f.
+func startsWith(e Expression, f func(Expression) bool) bool {
+	for ; e != nil; e = leftOperand(e) {
+		if f(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBraceOrDeclaration reports whether e would be read as a block or a
+// declaration at the start of a statement.
+func isBraceOrDeclaration(e Expression) bool {
+	switch v := e.(type) {
+	case *ObjectExpression, *FunctionExpression, *ClassExpression:
+		return true
+	case *ComputedMemberExpression:
+		// let [ starts a declaration
+		id, ok := v.Object.(*Identifier)
+		return ok && id.Name == "let" && !v.Optional
+	}
+	return false
+}
+
+// isIn reports whether n is an in expression, which can't appear
+// unparenthesized in the initializer of a for statement.
+func isIn(n JSElement) bool {
+	b, ok := n.(*BinaryExpression)
+	return ok && b.Operator == BinaryOperatorIn
+}
+
+func isObjectExpression(e Expression) bool {
+	_, ok := e.(*ObjectExpression)
+	return ok
+}
+
+func isFunctionOrClass(e Expression) bool {
+	switch e.(type) {
+	case *FunctionExpression, *ClassExpression:
+		return true
+	}
+	return false
+}
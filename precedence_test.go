@@ -0,0 +1,116 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrecedence(t *testing.T) {
+	expr := &BinaryExpression{
+		Operator: BinaryOperatorMultiply,
+		Left: &BinaryExpression{
+			Operator: BinaryOperatorADD,
+			Left:     &Identifier{Name: "a"},
+			Right:    &Identifier{Name: "b"},
+		},
+		Right: &Identifier{Name: "c"},
+	}
+	assert.Equal(t, "(a + b) * c", expr.String())
+
+	tests := []struct {
+		Src    string
+		Expect string
+	}{
+		{`a - (b - c)`, `a - (b - c)`},
+		{`(a - b) - c`, `a - b - c`},
+		{`(a ** b) ** c`, `(a ** b) ** c`},
+		{`a ** (b ** c)`, `a ** b ** c`},
+		{`(-a) ** b`, `(-a) ** b`},
+		{`(a ?? b) || c`, `(a ?? b) || c`},
+		{`a && (b ?? c)`, `a && (b ?? c)`},
+		{`(a || b) && c`, `(a || b) && c`},
+		{`new (a())()`, `new (a())()`},
+		{`new (a().b)()`, `new (a().b)()`},
+		{`new a.b()`, `new a.b()`},
+		{`new a().b`, `new a().b`},
+		{`f((a, b), c)`, `f((a, b), c)`},
+		{`- -a`, `-(-a)`},
+		{`-(a + b)`, `-(a + b)`},
+		{`typeof (a + b)`, `typeof (a + b)`},
+		{`a ? b : (c, d)`, `a? b: (c, d)`},
+		{`(a ? b : c) ? d : e`, `(a? b: c)? d: e`},
+		{`(a = b) + c`, `(a = b) + c`},
+		{`a = b = c`, `a = b = c`},
+		{`(a?.b).c`, `(a?.b).c`},
+		{`(a + b).c`, `(a + b).c`},
+		{`(a + b)[c]`, `(a + b)[c]`},
+		{"(a || b)`t`", "(a || b)`t`"},
+		{`(() => a)()`, `(() => a)()`},
+		{`x => (a, b)`, `(x) => (a, b)`},
+		{`a++ + ++b`, `a++ + ++b`},
+		{`[(a, b)]`, `[
+  (a, b),
+]`},
+	}
+	for _, test := range tests {
+		expr, err := ParseExpr(test.Src)
+		if assert.NoError(t, err, test.Src) {
+			assert.Equal(t, test.Expect, expr.String(), test.Src)
+		}
+	}
+}
+
+func TestPrecedenceStatementStart(t *testing.T) {
+	obj := &ObjectExpression{Properties: []ObjectExpressionProperty{
+		&Property{Key: &Identifier{Name: "a"}, Value: &Identifier{Name: "a"}, ShortHand: true},
+	}}
+	member := &StaticMemberExpression{Object: obj, Property: &Identifier{Name: "a"}}
+	fn := &FunctionExpression{Body: BlockStatement{Items: []Statement{&ReturnStatement{}}}}
+
+	assert.Equal(t, `({
+  a,
+}.a);`, (&ExpressionStatement{Expression: member}).String())
+	assert.Equal(t, `() => ({
+  a,
+}.a)`, (&ArrowFunctionExpression{Expression: member}).String())
+	assert.Equal(t, `(function () {
+  return;
+}());`, (&ExpressionStatement{Expression: &CallExpression{Callee: fn}}).String())
+	assert.Equal(t, `export default (function () {
+  return;
+}());`, (&ExportDefaultDeclaration{Declaration: &CallExpression{Callee: fn}}).String())
+	assert.Equal(t, `(class {
+  x;
+}.name);`, (&ExpressionStatement{Expression: &StaticMemberExpression{
+		Object: &ClassExpression{Body: &ClassBody{Properties: []ClassProperty{
+			&PropertyDefinition{Key: &Identifier{Name: "x"}},
+		}}},
+		Property: &Identifier{Name: "name"},
+	}}).String())
+
+	prog, err := Parse("test.js", "(let[0] = 1);")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "(let[0] = 1);", (&PrinterConfig{PrintWidth: 80}).Sprint(prog))
+	}
+}
+
+func TestPrecedenceForInit(t *testing.T) {
+	tests := []struct {
+		Src                    string
+		Expect, Minify, Layout string
+	}{
+		{"for ((a in b);;) {}", "for ((a in b); ; ) {\n\n}", "for((a in b);;){}", "for ((a in b); ; ) {}"},
+		{"for (x = (a in b);;) {}", "for (x = (a in b); ; ) {\n\n}", "for(x=(a in b);;){}", "for (x = (a in b); ; ) {}"},
+		{"for (var x = (a in b);;) {}", "for (var x = (a in b); ; ) {\n\n}", "for(var x=(a in b);;){}", "for (var x = (a in b); ; ) {}"},
+		{"for (;a in b;) {}", "for (; a in b; ) {\n\n}", "for(;a in b;){}", "for (; a in b; ) {}"},
+	}
+	for _, test := range tests {
+		prog, err := Parse("test.js", test.Src)
+		if assert.NoError(t, err, test.Src) {
+			assert.Equal(t, test.Expect, prog.String(), test.Src)
+			assert.Equal(t, test.Minify, (&PrinterConfig{Minify: true}).Sprint(prog), test.Src)
+			assert.Equal(t, test.Layout, (&PrinterConfig{PrintWidth: 80}).Sprint(prog), test.Src)
+		}
+	}
+}
@@ -0,0 +1,1312 @@
+package goesprima
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// PrinterConfig controls the output of Fprint. The zero value prints the
+// same as String. A config is not modified by printing and can be shared
+// by many goroutines.
+type PrinterConfig struct {
+	// Indent indents the contents of blocks, two spaces if nil.
+	Indent Indentor
+	// Quote is the quote of string literals.
+	Quote QuoteStyle
+	// ASCIIOnly escapes the characters of string and template literals
+	// that aren't ASCII.
+	ASCIIOnly bool
+	// HTMLSafe escapes </script and <!-- in string and template literals,
+	// so that the output can be inlined in an HTML script element.
+	HTMLSafe bool
+	// OmitSemicolons ends statements without semicolons, except before
+	// lines starting with (, [, `, +, - or /, which get a leading one.
+	OmitSemicolons bool
+	// DeclarationSemicolons ends variable declarations with a semicolon
+	// like other statements, where String omits it. It's implied by
+	// PrintWidth.
+	DeclarationSemicolons bool
+	// TrailingCommas controls the commas after the last element of lists
+	// printed on multiple lines.
+	TrailingCommas TrailingCommaStyle
+	// OmitBracketSpacing prints import and export specifiers, and objects
+	// laid out on one line by PrintWidth, as {a} instead of { a }.
+	OmitBracketSpacing bool
+	// ArrowParens controls the parentheses around a single arrow function
+	// parameter.
+	ArrowParens ArrowParensStyle
+	// Minify prints without newlines, indentation, trailing commas or
+	// whitespace other than that separating words. Numbers take their
+	// shortest spelling, strings the quote needing the fewest escapes and
+	// single arrow function parameters no parentheses. Indent,
+	// OmitSemicolons, TrailingCommas, OmitBracketSpacing and ArrowParens are
+	// ignored.
+	Minify bool
+	// PrintWidth lays the output out in PrintWidth columns like prettier:
+	// arguments, parameters, object and array literals, member chains and
+	// binary expressions stay on one line if they fit and break
+	// consistently otherwise, and mixed operators are parenthesized as in
+	// (a && b) || c. Numbers are spelled as in 1.5 rather than 1.500000.
+	// Tabs of Indent count as two columns. Zero keeps the fixed layout of
+	// String. It's ignored by minified output.
+	PrintWidth int
+}
+
+// QuoteStyle is the quote of string literals.
+type QuoteStyle int
+
+const (
+	QuoteDouble QuoteStyle = iota
+	QuoteSingle
+)
+
+// TrailingCommaStyle controls the commas after the last element of lists.
+type TrailingCommaStyle int
+
+const (
+	// TrailingCommaES5 adds trailing commas to object and array literals
+	// and object patterns.
+	TrailingCommaES5 TrailingCommaStyle = iota
+	TrailingCommaNone
+	// TrailingCommaAll adds trailing commas to array patterns as well,
+	// and to arguments and parameters broken by PrintWidth.
+	TrailingCommaAll
+)
+
+// ArrowParensStyle controls the parentheses around a single arrow function
+// parameter.
+type ArrowParensStyle int
+
+const (
+	// ArrowParensAlways prints (x) => x.
+	ArrowParensAlways ArrowParensStyle = iota
+	// ArrowParensAvoid prints x => x when the parameter is an identifier.
+	ArrowParensAvoid
+)
+
+var defaultPrinterConfig PrinterConfig
+
+// Fprint writes the JavaScript source of node to w in a single pass.
+func (c *PrinterConfig) Fprint(w io.Writer, node JSElement) error {
+	return c.fprint(w, node, nil)
+}
+
+// fprint prints node to w, recording its mappings in m if it isn't nil.
+func (c *PrinterConfig) fprint(w io.Writer, node JSElement, m *SourceMap) error {
+	bw := bufio.NewWriter(w)
+	p := newPrinter(bw, c)
+	if m != nil {
+		p.sm = newSourceMapper(m)
+	}
+	p.root(node)
+	if p.err != nil {
+		return p.err
+	}
+	return bw.Flush()
+}
+
+// Sprint returns the JavaScript source of node.
+func (c *PrinterConfig) Sprint(node JSElement) string {
+	var sb strings.Builder
+	newPrinter(&sb, c).root(node)
+	return sb.String()
+}
+
+// sprint prints node with the default configuration.
+func sprint(node JSElement) string {
+	return defaultPrinterConfig.Sprint(node)
+}
+
+type printer struct {
+	*PrinterConfig
+	w io.Writer
+	// unit is one level of indentation
+	unit  string
+	depth int
+	// bol is set at the beginning of a line, before the indentation is
+	// written.
+	bol bool
+	// asi is set after a statement without a semicolon.
+	asi bool
+	// semi is set after a statement of minified output, whose semicolon is
+	// written before the next token.
+	semi bool
+	// last is the last byte written, and prev the byte before it.
+	last, prev byte
+	// glued is set before a token that must not be separated from the
+	// previous one, as spaces would be part of a template literal.
+	glued bool
+	// noIn is set while printing the initializer of a for statement, where
+	// an in expression must be parenthesized.
+	noIn bool
+	// sm records the source map, if any.
+	sm *sourceMapper
+	// parts collects the document of the width-aware printer, nil when
+	// printing directly.
+	parts    *[]doc
+	fitsCmds []docCmd
+	err      error
+}
+
+func newPrinter(w io.Writer, c *PrinterConfig) *printer {
+	if c == nil {
+		c = &defaultPrinterConfig
+	}
+	var ind Indentor = &Spaces{2}
+	if c.Indent != nil {
+		ind = c.Indent
+	}
+	return &printer{PrinterConfig: c, w: w, unit: ind.Indent("")}
+}
+
+// root prints node, laying it out by width if PrintWidth is set.
+func (p *printer) root(node JSElement) {
+	if p.PrintWidth <= 0 || p.Minify {
+		p.node(node)
+		return
+	}
+	d := p.capture(func() { p.node(node) })
+	p.render(d)
+}
+
+// add appends d to the document.
+func (p *printer) add(d doc) {
+	*p.parts = append(*p.parts, d)
+}
+
+// capture returns the document printed by f.
+func (p *printer) capture(f func()) *docConcat {
+	saved := p.parts
+	var parts []doc
+	p.parts = &parts
+	f()
+	p.parts = saved
+	return cat(parts...)
+}
+
+// group prints the output of f as a group of the document.
+func (p *printer) group(f func()) {
+	p.add(group(p.capture(f), false))
+}
+
+// indent prints the output of f one level deeper.
+func (p *printer) indent(f func()) {
+	if p.parts == nil {
+		p.depth++
+		f()
+		p.depth--
+		return
+	}
+	p.add(&docIndent{p.capture(f)})
+}
+
+// enter records the source location of node for the source map.
+func (p *printer) enter(node JSElement) {
+	switch {
+	case p.sm == nil || node == nil:
+	case p.parts != nil:
+		p.add(docMark{node})
+	default:
+		p.sm.enter(node)
+	}
+}
+
+// print writes the tokens of s. Spaces in s are layout, dropped by
+// minified output.
+func (p *printer) print(s string) {
+	if p.Minify {
+		for _, f := range strings.Fields(s) {
+			p.token(f)
+		}
+		return
+	}
+	p.token(s)
+}
+
+// token writes s verbatim, after the indentation at the beginning of a
+// line. s should not contain newlines other than those of template
+// literals.
+func (p *printer) token(s string) {
+	if s == "" || p.err != nil {
+		return
+	}
+	if p.parts != nil {
+		p.add(docText(s))
+		return
+	}
+	p.emit(s)
+}
+
+// emit writes the token s.
+func (p *printer) emit(s string) {
+	if p.semi {
+		// the last semicolon of a block is dropped
+		p.semi = false
+		if s[0] != '}' {
+			p.write(";")
+		}
+	}
+	if p.Minify && !p.glued && separate(p.prev, p.last, s) {
+		p.write(" ")
+	}
+	p.glued = false
+	if p.bol {
+		p.bol = false
+		for i := 0; i < p.depth; i++ {
+			p.write(p.unit)
+		}
+	}
+	if p.asi {
+		p.asi = false
+		if strings.IndexByte("([`+-/", s[0]) >= 0 {
+			p.write(";")
+		}
+	}
+	if p.sm != nil {
+		p.sm.mark()
+	}
+	p.write(s)
+}
+
+// glue writes the token s of a template literal, never separated from the
+// previous token.
+func (p *printer) glue(s string) {
+	p.glued = p.Minify
+	p.token(s)
+}
+
+func (p *printer) write(s string) {
+	if p.err != nil || len(s) == 0 {
+		return
+	}
+	_, p.err = io.WriteString(p.w, s)
+	if len(s) > 1 {
+		p.prev = s[len(s)-2]
+	} else {
+		p.prev = p.last
+	}
+	p.last = s[len(s)-1]
+	if p.sm != nil {
+		p.sm.advance(s)
+	}
+}
+
+// separate reports whether a space is needed between a token ending with
+// prev and last and s, so that they aren't read as a single token or as
+// the start of an HTML-like comment.
+func separate(prev, last byte, s string) bool {
+	switch {
+	case isWordByte(last) && isWordByte(s[0]):
+		return true
+	case (last == '+' || last == '-') && s[0] == last:
+		return true
+	case last == '/' && s[0] == '/':
+		return true
+	case last == '<' && s[0] == '!':
+		return true
+	case prev == '<' && last == '!' && strings.HasPrefix(s, "--"):
+		return true
+	}
+	return false
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c == '\\' || c >= 0x80
+}
+
+// trailingComma writes the comma after the last element of a list printed
+// on multiple lines.
+func (p *printer) trailingComma() {
+	if p.TrailingCommas != TrailingCommaNone && !p.Minify {
+		p.print(",")
+	}
+}
+
+func isRest(n JSElement) bool {
+	_, ok := n.(*RestElement)
+	return ok
+}
+
+func isIdentifier(n JSElement) bool {
+	_, ok := n.(*Identifier)
+	return ok
+}
+
+// semicolon ends a statement.
+func (p *printer) semicolon() {
+	if p.Minify {
+		p.semi = true
+		return
+	}
+	if p.OmitSemicolons {
+		p.omitSemicolon()
+		return
+	}
+	p.print(";")
+}
+
+// declarationSemicolons reports whether variable declarations end with a
+// semicolon.
+func (p *printer) declarationSemicolons() bool {
+	return p.DeclarationSemicolons || p.PrintWidth > 0 || p.Minify
+}
+
+// omitSemicolon marks the end of a statement without a semicolon.
+func (p *printer) omitSemicolon() {
+	if p.parts != nil {
+		p.add(docASI{})
+		return
+	}
+	p.asi = true
+}
+
+// braces writes the output of body between braces on a single line.
+func (p *printer) braces(body func()) {
+	if p.OmitBracketSpacing || p.Minify {
+		p.print("{")
+		body()
+		p.print("}")
+		return
+	}
+	p.print("{ ")
+	body()
+	p.print(" }")
+}
+
+// quote writes s as a string literal.
+func (p *printer) quote(s string) {
+	single := p.Quote == QuoteSingle
+	if p.Minify {
+		// the cheaper quote
+		double, quote := strings.Count(s, `"`), strings.Count(s, "'")
+		single = double > quote || double == quote && single
+	}
+	opts := QuoteOptions{Quote: QuoteDouble, ASCIIOnly: p.ASCIIOnly, HTMLSafe: p.HTMLSafe}
+	if single {
+		opts.Quote = QuoteSingle
+	}
+	p.token(Quote(s, opts))
+}
+
+func (p *printer) newline() {
+	switch {
+	case p.Minify:
+	case p.parts != nil:
+		p.add(hardline)
+	default:
+		p.breakLine()
+	}
+}
+
+// breakLine writes a line break, the indentation being written before the
+// next token.
+func (p *printer) breakLine() {
+	p.write("\n")
+	p.bol = true
+}
+
+// text writes s, indenting every line.
+func (p *printer) text(s string) {
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			p.newline()
+		}
+		p.token(line)
+	}
+}
+
+// block writes the output of body between braces, one level deeper. The
+// width-aware printer prints empty blocks as {}.
+func (p *printer) block(body func()) {
+	noIn := p.noIn
+	p.noIn = false
+	defer func() { p.noIn = noIn }()
+	if p.parts != nil {
+		d := p.capture(body)
+		if isEmptyDoc(d) {
+			p.print("{}")
+			return
+		}
+		body = func() { p.add(d) }
+	}
+	p.print("{")
+	p.indent(func() {
+		p.newline()
+		body()
+	})
+	p.newline()
+	p.print("}")
+}
+
+// lines writes every element of list on its own line.
+func lines[T JSElement](p *printer, list []T) {
+	for i, n := range list {
+		if i > 0 {
+			p.newline()
+		}
+		p.node(n)
+	}
+}
+
+// list writes the elements of list separated by sep. Nil elements are
+// holes, eg. [a, , b], and sequence expressions are parenthesized.
+func list[T JSElement](p *printer, list []T, sep string) {
+	for i, n := range list {
+		if i > 0 {
+			p.print(sep)
+		}
+		p.element(n)
+	}
+}
+
+// element writes an element of a list.
+func (p *printer) element(n JSElement) {
+	if n == nil {
+		return
+	}
+	if e, ok := n.(Expression); ok {
+		p.operand(e, precAssign)
+	} else {
+		p.node(n)
+	}
+}
+
+// operand writes e, parenthesized if it binds looser than min.
+func (p *printer) operand(e Expression, min int) {
+	p.wrap(precedence(e) < min, e)
+}
+
+// wrap writes n, parenthesized if parens is set.
+func (p *printer) wrap(parens bool, n JSElement) {
+	if parens {
+		noIn := p.noIn
+		p.noIn = false
+		p.print("(")
+		p.node(n)
+		p.print(")")
+		p.noIn = noIn
+		return
+	}
+	p.node(n)
+}
+
+func (p *printer) node(node JSElement) {
+	if p.noIn && isIn(node) {
+		p.wrap(true, node)
+		return
+	}
+	p.enter(node)
+	if p.parts != nil && p.layout(node) {
+		return
+	}
+	switch n := node.(type) {
+	case nil:
+
+	// Program
+	case *Generator:
+		lines(p, n.Statements)
+	case *Program:
+		lines(p, n.Body)
+
+	// Exports
+	case *ExportAllDeclaration:
+		p.print("export * ")
+		if n.Exported != nil {
+			p.print("as ")
+			p.node(n.Exported)
+			p.print(" ")
+		}
+		p.print("from ")
+		p.node(n.Source)
+		p.semicolon()
+
+	case *ExportDefaultDeclaration:
+		p.print("export default ")
+		switch d := n.Declaration.(type) {
+		case *FunctionDeclaration, *ClassDeclaration:
+			p.node(d)
+			return
+		case Expression:
+			p.wrap(precedence(d) < precAssign || startsWith(d, isFunctionOrClass), d)
+		default:
+			p.node(d)
+		}
+		p.semicolon()
+
+	case *ExportNamedDeclaration:
+		p.print("export ")
+		if n.Declaration != nil {
+			p.node(n.Declaration)
+			if _, ok := n.Declaration.(*VariableDeclaration); ok && p.declarationSemicolons() {
+				// ended by the declaration
+				return
+			}
+		} else {
+			specifiers(p, n.Specifiers)
+			if n.Source != nil {
+				p.print(" from ")
+				p.node(n.Source)
+			}
+		}
+		p.semicolon()
+
+	case ExportSpecifier:
+		p.node(&n)
+	case *ExportSpecifier:
+		if n.Local != nil && n.Local.Name != n.Exported.Name {
+			p.node(n.Local)
+			p.print(" as ")
+		}
+		p.node(n.Exported)
+
+	// Patterns
+	case *ArrayPattern:
+		if len(n.Elements) == 0 {
+			p.print("[]")
+			return
+		}
+		p.print("[")
+		p.indent(func() {
+			p.newline()
+			list(p, n.Elements, ", ")
+			if p.TrailingCommas == TrailingCommaAll && !isRest(n.Elements[len(n.Elements)-1]) {
+				p.trailingComma()
+			}
+		})
+		p.newline()
+		p.print("]")
+
+	case *ObjectPattern:
+		if len(n.Properties) == 0 {
+			p.print("{}")
+			return
+		}
+		p.print("{")
+		p.indent(func() {
+			for i, prop := range n.Properties {
+				p.newline()
+				p.node(prop)
+				if i < len(n.Properties)-1 {
+					p.print(",")
+				} else if !isRest(prop) {
+					p.trailingComma()
+				}
+			}
+		})
+		p.newline()
+		p.print("}")
+
+	case *AssignmentPattern:
+		p.node(n.Left)
+		p.print("=")
+		p.operand(n.Right, precAssign)
+
+	case *RestElement:
+		p.print("...")
+		p.node(n.Argument)
+
+	case *PropertyPattern:
+		switch {
+		case n.Value == nil:
+			p.key(n.Key, n.Computed)
+		case n.ShortHand:
+			p.node(n.Value)
+		default:
+			p.key(n.Key, n.Computed)
+			p.print(": ")
+			p.node(n.Value)
+		}
+
+	// Literals
+	case *Identifier:
+		p.token(n.Name)
+	case *literalValueNull:
+		p.print("null")
+	case *literalValueUndefined:
+		p.print("undefined")
+	case *LiteralValueString:
+		p.quote(string(*n))
+	case *LiteralValueBool:
+		p.print(strconv.FormatBool(bool(*n)))
+	case *LiteralValueNumber:
+		p.token(p.number(float64(*n)))
+	case *LiteralValueBigFloat:
+		p.token(bigFloat(n).String())
+
+	// Expressions
+	case *ArrayExpression:
+		if len(n.Elements) == 0 {
+			p.print("[]")
+			return
+		}
+		p.print("[")
+		p.indent(func() {
+			p.newline()
+			list(p, n.Elements, ", ")
+			// a trailing hole needs its comma
+			if n.Elements[len(n.Elements)-1] == nil {
+				p.print(",")
+			} else {
+				p.trailingComma()
+			}
+		})
+		p.newline()
+		p.print("]")
+
+	case *ArrowFunctionExpression:
+		if n.Async {
+			p.print("async ")
+		}
+		if len(n.Params) == 1 && isIdentifier(n.Params[0]) && (p.ArrowParens == ArrowParensAvoid || p.Minify) {
+			p.node(n.Params[0])
+		} else {
+			p.params(n.Params)
+		}
+		p.print(" => ")
+		if n.Expression != nil {
+			// a body starting with { would be read as a block
+			p.wrap(precedence(n.Expression) < precAssign || startsWith(n.Expression, isObjectExpression), n.Expression)
+			return
+		}
+		p.block(func() { p.node(&n.Body) })
+
+	case *AwaitExpression:
+		p.print("await ")
+		p.operand(n.Arguement, precUnary)
+
+	case *AssignmentExpression:
+		p.node(n.Left)
+		p.print(" " + string(n.Operator) + " ")
+		p.operand(n.Right, precAssign)
+
+	case *BinaryExpression, *LogicalExpression:
+		op, left, right, lparens, rparens := binaryOperands(n.(Expression))
+		p.wrap(lparens, left)
+		p.print(" " + op + " ")
+		p.wrap(rparens, right)
+
+	case *CallExpression:
+		p.wrap(objectNeedsParens(n.Callee), n.Callee)
+		if n.Optional {
+			p.print("?.")
+		}
+		p.arguments(n.Arguments)
+
+	case *ChainExpression:
+		if chainHasOptional(n.Expression) {
+			p.node(n.Expression)
+			return
+		}
+		// the optional access applies to the outermost element
+		switch e := n.Expression.(type) {
+		case *CallExpression:
+			c := *e
+			c.Optional = true
+			p.node(&c)
+		case *ComputedMemberExpression:
+			c := *e
+			c.Optional = true
+			p.node(&c)
+		case *StaticMemberExpression:
+			c := *e
+			c.Optional = true
+			p.node(&c)
+		}
+
+	case *ClassExpression:
+		p.class(n.ID, n.SuperClass, n.Body)
+
+	case *ComputedMemberExpression:
+		p.wrap(objectNeedsParens(n.Object), n.Object)
+		if n.Optional {
+			p.print("?.")
+		}
+		p.print("[")
+		p.node(n.Property)
+		p.print("]")
+
+	case *ConditionalExpression:
+		p.operand(n.Test, precConditional+1)
+		p.print("? ")
+		p.operand(n.Consequent, precAssign)
+		p.print(": ")
+		p.operand(n.Alternate, precAssign)
+
+	case *FunctionExpression:
+		p.function(n.FunctionType, n.ID, n.Params, &n.Body)
+
+	case *NewExpression:
+		p.print("new ")
+		p.wrap(newCalleeNeedsParens(n.Callee), n.Callee)
+		p.arguments(n.Arguments)
+
+	case *ObjectExpression:
+		p.block(func() {
+			for i, prop := range n.Properties {
+				if i > 0 {
+					p.newline()
+				}
+				p.node(prop)
+				if i < len(n.Properties)-1 {
+					p.print(",")
+				}
+			}
+			if len(n.Properties) > 0 {
+				p.trailingComma()
+			}
+		})
+
+	case *SequenceExpression:
+		list(p, n.Expressions, ", ")
+
+	case *StaticMemberExpression:
+		// 1.x would be read as the number 1. and x
+		num, integer := n.Object.(*LiteralValueNumber)
+		integer = integer && !strings.ContainsAny(p.number(float64(*num)), ".ex")
+		p.wrap(integer || objectNeedsParens(n.Object), n.Object)
+		if n.Optional {
+			p.print("?.")
+		} else {
+			p.print(".")
+		}
+		switch prop := n.Property.(type) {
+		case *Identifier, *StaticMemberExpression, *CallExpression:
+			p.node(prop)
+		default:
+			p.wrap(true, prop)
+		}
+
+	case *TaggedTemplateExpression:
+		p.wrap(objectNeedsParens(n.Tag), n.Tag)
+		p.node(&n.Quasi)
+
+	case *TemplateLiteral:
+		p.print("`")
+		for i := range n.Quasis {
+			p.node(&n.Quasis[i])
+			if i < len(n.Expressions) {
+				p.glue("${")
+				p.node(n.Expressions[i])
+				p.glue("}")
+			}
+		}
+		p.print("`")
+
+	case *TemplateElement:
+		p.token(escapeTemplate(n.Raw, QuoteOptions{ASCIIOnly: p.ASCIIOnly, HTMLSafe: p.HTMLSafe}))
+
+	case *UnaryExpression:
+		op := string(n.Operator)
+		if isPostfix(n.Operator) {
+			p.operand(n.Argument, precCall)
+			p.print(op[2:])
+			return
+		}
+		prefix := op[:len(op)-2]
+		p.print(prefix)
+		// - -a and + +a must not be read as decrements and increments
+		sign := len(prefix) == 1 && (prefix[0] == '-' || prefix[0] == '+') && startsWithSign(n.Argument, prefix[0])
+		p.wrap(sign || precedence(n.Argument) < precUnary, n.Argument)
+
+	case *UpdateExpression:
+		p.node(n.Argument)
+
+	case *YieldExpression:
+		p.print("yield")
+		if n.Delegate {
+			p.print("*")
+		}
+		if n.Argument != nil {
+			p.print(" ")
+			p.operand(n.Argument, precAssign)
+		}
+
+	case *Import:
+		p.print("import")
+	case *MetaProperty:
+		p.node(&n.Meta)
+		p.print(".")
+		p.node(&n.Property)
+	case *Super:
+		p.print("super")
+	case *ThisExpression:
+		p.print("this")
+	case *SpreadElement:
+		p.print("...")
+		p.operand(n.Argument, precAssign)
+
+	// Classes
+	case *ClassDeclaration:
+		p.class(n.ID, n.SuperClass, n.Body)
+
+	case *ClassBody:
+		lines(p, n.Properties)
+
+	case *MethodDefinition:
+		if n.Static {
+			p.print("static ")
+		}
+		p.method(n.Kind, n.Key, n.Computed, &n.Value)
+
+	case *PropertyDefinition:
+		if n.Static {
+			p.print("static ")
+		}
+		p.key(n.Key, n.Computed)
+		if n.Value != nil {
+			p.print(" = ")
+			p.operand(n.Value, precAssign)
+		}
+		p.semicolon()
+
+	case *Property:
+		if f, ok := n.Value.(*FunctionExpression); ok && (n.Method || n.Kind == "get" || n.Kind == "set") {
+			p.method(n.Kind, n.Key, n.Computed, f)
+			return
+		}
+		p.key(n.Key, n.Computed)
+		if n.Value == nil || n.ShortHand && isShorthand(n) {
+			return
+		}
+		p.print(": ")
+		p.operand(n.Value, precAssign)
+
+	// Declarations
+	case *FunctionDeclaration:
+		p.function(n.FunctionType, n.ID, n.Params, &n.Body)
+
+	case *ImportDeclaration:
+		p.importDeclaration(n)
+
+	case *VariableDeclaration:
+		p.declarations(n)
+		if p.declarationSemicolons() {
+			p.semicolon()
+		} else {
+			p.omitSemicolon()
+		}
+
+	case VariableDeclarator:
+		p.node(&n)
+	case *VariableDeclarator:
+		p.node(n.ID)
+		if n.Init != nil {
+			p.print(" = ")
+			p.operand(n.Init, precAssign)
+		}
+
+	// Statements
+	case *BlockStatement:
+		lines(p, n.Items)
+
+	case *BreakStatement:
+		p.print("break")
+		if n.Label != nil {
+			p.print(" ")
+			p.node(n.Label)
+		}
+		p.semicolon()
+
+	case *ContinueStatement:
+		p.print("continue")
+		if n.Label != nil {
+			p.print(" ")
+			p.node(n.Label)
+		}
+		p.semicolon()
+
+	case *DebuggerStatement:
+		p.print("debugger")
+		p.semicolon()
+
+	case *DoWhileStatement:
+		p.print("do ")
+		p.block(func() { p.node(&n.Body) })
+		p.print(" while")
+		p.condition(n.Test)
+		p.semicolon()
+
+	case *EmptyStatement:
+
+	case *ExpressionStatement:
+		p.wrap(startsWith(n.Expression, isBraceOrDeclaration), n.Expression)
+		p.semicolon()
+
+	case *Directive:
+		p.node(n.Expression)
+		p.semicolon()
+
+	case *ForStatement:
+		p.print("for (")
+		for i, e := range []JSElement{n.Init, n.Test, n.Update} {
+			if i > 0 {
+				p.print("; ")
+			}
+			p.noIn = i == 0
+			p.forInit(e)
+			p.noIn = false
+		}
+		p.print(") ")
+		p.block(func() { p.node(&n.Body) })
+
+	case *ForInStatement:
+		p.print("for(")
+		p.forInit(n.Left)
+		p.print(" in ")
+		p.node(n.Right)
+		p.print(")")
+		p.block(func() { p.node(&n.Body) })
+
+	case *ForOfStatement:
+		p.print("for")
+		if n.Await {
+			p.print(" await")
+		}
+		p.print("(")
+		p.forInit(n.Left)
+		p.print(" of ")
+		p.operand(n.Right, precAssign)
+		p.print(")")
+		p.block(func() { p.node(&n.Body) })
+
+	case *IfStatement:
+		p.print("if ")
+		p.condition(n.Test)
+		p.print(" ")
+		p.block(func() { p.node(n.Consequent) })
+		if n.Alternate != nil {
+			p.print(" else ")
+			if _, ok := n.Alternate.(*IfStatement); ok {
+				p.node(n.Alternate)
+			} else {
+				p.block(func() { p.node(n.Alternate) })
+			}
+		}
+
+	case *LabeledStatement:
+		p.node(&n.Label)
+		p.print(":")
+		p.newline()
+		p.node(n.Body)
+
+	case *ReturnStatement:
+		p.print("return")
+		if n.Argument != nil {
+			p.print(" ")
+			p.node(n.Argument)
+		}
+		p.semicolon()
+
+	case *SwitchStatement:
+		p.print("switch ")
+		p.condition(n.Discriminant)
+		p.print(" ")
+		p.block(func() {
+			for i := range n.Cases {
+				if i > 0 {
+					p.newline()
+				}
+				p.node(&n.Cases[i])
+			}
+		})
+
+	case SwitchCase:
+		p.node(&n)
+	case *SwitchCase:
+		if n.Test == nil {
+			p.print("default:")
+		} else {
+			p.print("case ")
+			p.node(n.Test)
+			p.print(":")
+		}
+		p.indent(func() {
+			p.newline()
+			p.node(&n.Consequent)
+		})
+
+	case *ThrowStatement:
+		p.print("throw ")
+		p.node(n.Argument)
+		p.semicolon()
+
+	case *TryStatement:
+		p.print("try ")
+		p.block(func() { p.node(&n.Block) })
+		if n.HasHandler() {
+			p.print(" ")
+			p.node(&n.Handler)
+		}
+		if n.Finalizer != nil {
+			p.print(" finally ")
+			p.block(func() { p.node(n.Finalizer) })
+		}
+
+	case CatchClause:
+		p.node(&n)
+	case *CatchClause:
+		p.print("catch ")
+		if n.BindingIdentifierOrPattern != nil {
+			p.print("(")
+			p.node(n.BindingIdentifierOrPattern)
+			p.print(") ")
+		}
+		p.block(func() { p.node(&n.Body) })
+
+	case *WhileStatement:
+		p.print("while ")
+		p.condition(n.Test)
+		p.print(" ")
+		p.block(func() { p.node(n.Body) })
+
+	case *WithStatement:
+		p.print("with ")
+		p.condition(n.Object)
+		p.print(" ")
+		p.block(func() { p.node(n.Body) })
+
+	// Imports
+	case *ImportDefaultSpecifier:
+		p.node(n.Local)
+	case *ImportNamespaceSpecifier:
+		p.print("* as ")
+		p.node(n.Local)
+	case ImportSpecifier:
+		p.node(&n)
+	case *ImportSpecifier:
+		if len(n.NamedImports) == 0 {
+			p.print("{}")
+			return
+		}
+		specifiers(p, n.NamedImports)
+	case NamedImport:
+		p.node(&n)
+	case *NamedImport:
+		p.print(n.Imported.Name)
+		if n.Local != nil && n.Local.Name != n.Imported.Name {
+			p.print(" as " + n.Local.Name)
+		}
+
+	default:
+		p.text(node.String())
+	}
+}
+
+func (p *printer) declarations(n *VariableDeclaration) {
+	p.print(string(n.Kind) + " ")
+	for i := range n.Declarations {
+		if i > 0 {
+			p.print(",")
+		}
+		p.node(&n.Declarations[i])
+	}
+}
+
+// forInit writes the initializer of a for statement or the left side of a
+// for-in or for-of statement.
+func (p *printer) forInit(n JSElement) {
+	switch {
+	case n == nil || isNilValue(reflect.ValueOf(n)):
+	case isDeclaration(n):
+		p.declarations(n.(*VariableDeclaration))
+	default:
+		p.node(n)
+	}
+}
+
+func isDeclaration(n JSElement) bool {
+	_, ok := n.(*VariableDeclaration)
+	return ok
+}
+
+// number spells n. Minified output uses the shortest spelling and
+// PrintWidth the shortest decimal one.
+func (p *printer) number(n float64) string {
+	if !p.Minify && p.PrintWidth <= 0 {
+		return strconv.FormatFloat(n, 'f', 6, 64)
+	}
+	switch {
+	case math.IsNaN(n):
+		return "NaN"
+	case math.IsInf(n, 0):
+		if n < 0 {
+			return "-Infinity"
+		}
+		return "Infinity"
+	case n < 0 || n == 0 && math.Signbit(n):
+		return "-" + p.number(-n)
+	}
+	if !p.Minify {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	// 0.5 as .5
+	best := strings.TrimPrefix(strconv.FormatFloat(n, 'f', -1, 64), "0.")
+	if len(best) < len(strconv.FormatFloat(n, 'f', -1, 64)) {
+		best = "." + best
+	}
+
+	// 1000 as 1e3 and 0.00015 as 15e-5
+	e := strconv.FormatFloat(n, 'e', -1, 64)
+	mantissa, exp := e[:strings.IndexByte(e, 'e')], e[strings.IndexByte(e, 'e')+1:]
+	digits := strings.Replace(mantissa, ".", "", 1)
+	x, _ := strconv.Atoi(exp)
+	x -= len(digits) - 1
+	if sci := digits + "e" + strconv.Itoa(x); x != 0 && len(sci) < len(best) {
+		best = sci
+	}
+
+	// 0xff when shorter
+	if n == math.Trunc(n) && n < 1<<53 {
+		if hex := "0x" + strconv.FormatUint(uint64(n), 16); len(hex) < len(best) {
+			best = hex
+		}
+	}
+	return best
+}
+
+func (p *printer) function(t FunctionType, id *Identifier, params []FunctionParameter, body *BlockStatement) {
+	p.print(functionKeyword(t))
+	if id != nil {
+		p.node(id)
+	}
+	p.params(params)
+	p.print(" ")
+	p.block(func() { p.node(body) })
+}
+
+func functionKeyword(t FunctionType) string {
+	switch t {
+	case FunctionTypeAsync:
+		return "async function "
+	case FunctionTypeGenerator:
+		return "function* "
+	case FunctionTypeAsyncGenerator:
+		return "async function* "
+	default:
+		return "function "
+	}
+}
+
+func (p *printer) method(kind string, key PropertyKey, computed bool, f *FunctionExpression) {
+	switch kind {
+	case "get", "set":
+		p.print(kind + " ")
+	}
+	switch f.FunctionType {
+	case FunctionTypeAsync:
+		p.print("async ")
+	case FunctionTypeGenerator:
+		p.print("*")
+	case FunctionTypeAsyncGenerator:
+		p.print("async *")
+	}
+	p.key(key, computed)
+	p.params(f.Params)
+	p.print(" ")
+	p.block(func() { p.node(&f.Body) })
+}
+
+// key brackets computed keys. Keys that are neither identifiers nor
+// literals can only be computed.
+func (p *printer) key(key PropertyKey, computed bool) {
+	switch key.(type) {
+	case *Identifier, Literal:
+		if !computed {
+			p.node(key)
+			return
+		}
+	}
+	p.print("[")
+	p.node(key)
+	p.print("]")
+}
+
+// isShorthand reports whether the value of a shorthand property prints
+// the same as its key.
+func isShorthand(prop *Property) bool {
+	if k, ok := prop.Key.(*Identifier); ok && !prop.Computed {
+		if v, ok := prop.Value.(*Identifier); ok {
+			return k.Name == v.Name
+		}
+	}
+	var key strings.Builder
+	kp := newPrinter(&key, nil)
+	kp.key(prop.Key, prop.Computed)
+	return sprint(prop.Value) == key.String()
+}
+
+func (p *printer) class(id *Identifier, super Expression, body *ClassBody) {
+	p.print("class ")
+	if id != nil {
+		p.node(id)
+		p.print(" ")
+	}
+	if super != nil {
+		p.print("extends ")
+		p.wrap(objectNeedsParens(super), super)
+		p.print(" ")
+	}
+	p.block(func() { p.node(body) })
+}
+
+func (p *printer) importDeclaration(n *ImportDeclaration) {
+	p.print("import ")
+	if len(n.Specifiers) > 0 {
+		var def, ns JSElement
+		var named []NamedImport
+		for _, spec := range n.Specifiers {
+			switch v := spec.(type) {
+			case *ImportDefaultSpecifier:
+				def = v
+			case *ImportNamespaceSpecifier:
+				ns = v
+			case *ImportSpecifier:
+				named = append(named, v.NamedImports...)
+			}
+		}
+		if def != nil {
+			p.node(def)
+		}
+		if ns != nil {
+			if def != nil {
+				p.print(", ")
+			}
+			p.node(ns)
+		}
+		if len(named) > 0 {
+			if def != nil || ns != nil {
+				p.print(", ")
+			}
+			specifiers(p, named)
+		}
+		p.print(" from ")
+	}
+	p.quote(n.Source)
+	p.semicolon()
+}
+
+// chainHasOptional reports whether any element of the chain carries its own
+// Optional flag. Otherwise the optional access applies to the outermost
+// element.
+func chainHasOptional(e JSElement) bool {
+	switch v := e.(type) {
+	case *CallExpression:
+		return v.Optional || chainHasOptional(v.Callee)
+	case *StaticMemberExpression:
+		return v.Optional || chainHasOptional(v.Object)
+	case *ComputedMemberExpression:
+		return v.Optional || chainHasOptional(v.Object)
+	}
+	return false
+}
+
+func bigFloat(l *LiteralValueBigFloat) *big.Float {
+	f := big.Float(*l)
+	return &f
+}
@@ -0,0 +1,252 @@
+package goesprima
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrinterConfigFprint(t *testing.T) {
+	prog, err := Parse("test.js", "function f(a) {\n  if (a) {\n    return `x\ny`;\n  }\n  return {\n    a,\n  };\n}")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, (&PrinterConfig{}).Fprint(&buf, prog))
+	assert.Equal(t, prog.String(), buf.String())
+	// template literals are printed verbatim
+	assert.Equal(t, "function f(a) {\n  if (a) {\n    return `x\ny`;\n  }\n  return {\n    a,\n  };\n}", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, (&PrinterConfig{Indent: &Tabs{1}}).Fprint(&buf, prog))
+	assert.Equal(t, "function f(a) {\n\tif (a) {\n\t\treturn `x\ny`;\n\t}\n\treturn {\n\t\ta,\n\t};\n}", buf.String())
+}
+
+func TestPrinterZeroIndent(t *testing.T) {
+	prog, err := Parse("test.js", "if (a) {\n  if (b) {\n    c();\n  }\n}")
+	assert.NoError(t, err)
+	for _, indent := range []Indentor{&Spaces{0}, &Tabs{0}} {
+		var buf bytes.Buffer
+		assert.NoError(t, (&PrinterConfig{Indent: indent}).Fprint(&buf, prog))
+		assert.Equal(t, "if (a) {\nif (b) {\nc();\n}\n}", buf.String())
+	}
+}
+
+func TestPrinterDeep(t *testing.T) {
+	const depth = 2000
+	var stmt Statement = &ReturnStatement{}
+	for i := 0; i < depth; i++ {
+		stmt = &IfStatement{Test: &Identifier{Name: "a"}, Consequent: stmt}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, (&PrinterConfig{Indent: &Spaces{1}}).Fprint(&buf, stmt))
+	lines := strings.Split(buf.String(), "\n")
+	assert.Len(t, lines, 2*depth+1)
+	assert.Equal(t, strings.Repeat(" ", depth)+"return;", lines[depth])
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestPrinterWriteError(t *testing.T) {
+	prog, err := Parse("test.js", strings.Repeat("f();\n", 2000))
+	assert.NoError(t, err)
+	assert.EqualError(t, (&PrinterConfig{}).Fprint(errWriter{}, prog), "write failed")
+}
+
+func TestPrinterConfigOptions(t *testing.T) {
+	prog, err := Parse("test.js", `import a, { b as c } from "m";
+const f = (x) => [x, "it's \"q\""];
+let { y, ...rest } = o;
+(g || h)();
+let [u, ...v] = w;
+export { f };`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `import a, { b as c } from "m";
+const f = (x) => [
+  x, "it's \"q\"",
+]
+let {
+  y,
+  ...rest
+} = o
+;(g || h)();
+let [
+  u, ...v
+] = w
+export { f };`, prog.String())
+
+	cfg := &PrinterConfig{
+		Indent:             &Tabs{1},
+		Quote:              QuoteSingle,
+		OmitSemicolons:     true,
+		TrailingCommas:     TrailingCommaNone,
+		OmitBracketSpacing: true,
+		ArrowParens:        ArrowParensAvoid,
+	}
+	assert.Equal(t, `import a, {b as c} from 'm'
+const f = x => [
+	x, 'it\'s "q"'
+]
+let {
+	y,
+	...rest
+} = o
+;(g || h)()
+let [
+	u, ...v
+] = w
+export {f}`, cfg.Sprint(prog))
+
+	assert.Equal(t, `import a, { b as c } from "m";
+const f = (x) => [
+  x, "it's \"q\"",
+];
+let {
+  y,
+  ...rest
+} = o;
+(g || h)();
+let [
+  u, ...v
+] = w;
+export { f };`, (&PrinterConfig{DeclarationSemicolons: true}).Sprint(prog))
+
+	arr, err := ParseExpr(`[a, b]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "[\n  a, b,\n]", (&PrinterConfig{TrailingCommas: TrailingCommaAll}).Sprint(arr))
+
+	g := NewGenerator().AddStatement(prog.Body[0])
+	g.Config = cfg
+	assert.Equal(t, `import a, {b as c} from 'm'`, g.String())
+}
+
+func TestPrinterConfigConcurrent(t *testing.T) {
+	prog, err := Parse("test.js", "if (a) {\n  b(\"c\");\n}")
+	assert.NoError(t, err)
+	configs := []*PrinterConfig{
+		{},
+		{Indent: &Tabs{1}, Quote: QuoteSingle},
+		{Indent: &Spaces{4}, OmitSemicolons: true},
+	}
+	expect := []string{
+		"if (a) {\n  b(\"c\");\n}",
+		"if (a) {\n\tb('c');\n}",
+		"if (a) {\n    b(\"c\")\n}",
+	}
+	done := make(chan bool)
+	for i := 0; i < 30; i++ {
+		go func(i int) {
+			var buf bytes.Buffer
+			err := configs[i%3].Fprint(&buf, prog)
+			done <- err == nil && buf.String() == expect[i%3]
+		}(i)
+	}
+	for i := 0; i < 30; i++ {
+		assert.True(t, <-done)
+	}
+}
+
+func TestPrinterMinify(t *testing.T) {
+	prog, err := Parse("test.js", `import a, { b as c } from "m";
+const f = async (x) => [x, "it's", 'say "hi"'];
+let { y, z } = o;
+(g || h)();
+export default function def(a, b = 2) {
+  for (let i = 0; i < n; i++) {
+    if (typeof a === "string" && a instanceof B) {
+      continue;
+    } else if (a in b) {
+      return - -a + +b - -c;
+    }
+  }
+  switch (a) {
+    case 1:
+      f();
+    default:
+      g();
+  }
+  return ({}).x;
+}
+class K extends (a, b) {
+  static async *gen() {
+    yield* z;
+  }
+}
+var q = new (a())(), s = `+"`a ${b}  c`"+`;`)
+	assert.NoError(t, err)
+	assert.Equal(t, `import a,{b as c}from"m";const f=async x=>[x,"it's",'say "hi"'];let{y,z}=o;(g||h)();`+
+		`export default function def(a,b=2){for(let i=0;i<n;i++){if(typeof a==="string"&&a instanceof B){continue}`+
+		`else if(a in b){return-(-a)+ +b- -c}}switch(a){case 1:f();default:g()}return{}.x}`+
+		`class K extends(a,b){static async*gen(){yield*z}}var q=new(a())(),s=`+"`a ${b}  c`",
+		(&PrinterConfig{Minify: true}).Sprint(prog))
+}
+
+func TestPrinterMinifyTemplates(t *testing.T) {
+	cfg := &PrinterConfig{Minify: true}
+	for _, src := range []string{
+		"`foo_${bar}`",
+		"`a${b}c`",
+		"`${a}${b}`",
+		"tag`x${y}z`",
+		"`${`in${a}ner`}`",
+	} {
+		expr, err := ParseExpr(src)
+		if assert.NoError(t, err, src) {
+			assert.Equal(t, src, cfg.Sprint(expr))
+		}
+	}
+}
+
+func TestPrinterMinifyHTMLComments(t *testing.T) {
+	cfg := &PrinterConfig{Minify: true}
+	tests := []struct {
+		Source string
+		Expect string
+	}{
+		{"x = a < !--b", "x=a< !--b"},
+		{"x = a < !b", "x=a< !b"},
+		{"x = a < --b", "x=a<--b"},
+		{"x = !--a < b", "x=!--a<b"},
+	}
+	for _, test := range tests {
+		prog, err := Parse("test.js", test.Source)
+		if assert.NoError(t, err, test.Source) {
+			assert.Equal(t, test.Expect, cfg.Sprint(prog), test.Source)
+		}
+	}
+}
+
+func TestPrinterMinifyNumbers(t *testing.T) {
+	cfg := &PrinterConfig{Minify: true}
+	tests := []struct {
+		Value  float64
+		Expect string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{100, "100"},
+		{1000, "1e3"},
+		{1200, "1200"},
+		{0.5, ".5"},
+		{10.25, "10.25"},
+		{0.000015, "15e-6"},
+		{1e21, "1e21"},
+		{255, "255"},
+		{0xfffffffffff, "0xfffffffffff"},
+		{-2.5, "-2.5"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.Expect, cfg.Sprint(NumberLiteral(test.Value)), test.Expect)
+	}
+
+	expr, err := ParseExpr(`5..toString() + 1.5.toFixed()`)
+	assert.NoError(t, err)
+	assert.Equal(t, `(5).toString()+1.5.toFixed()`, cfg.Sprint(expr))
+}
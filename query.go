@@ -0,0 +1,843 @@
+package goesprima
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Query returns the nodes below and including root that match selector, in
+// traversal order. The selector follows the esquery grammar:
+//
+//	CallExpression                  node type, or * for any node
+//	[callee.name="require"]         attribute equality, also != < <= > >=
+//	[value=/^@aws-amplify\//i]      attribute matches a regular expression
+//	[value=type(string)]            attribute has the given JavaScript type
+//	[optional]                      attribute is present
+//	[params.length>1]               number of elements of a list
+//	[arguments.0.value="fs"]        element of a list by index
+//	.init                           node is held by the given parent field
+//	A B, A > B, A ~ B, A + B        descendant, child, sibling and adjacent
+//	:matches(A, B), :is(A, B)       any of the selectors match
+//	:not(A, B)                      none of the selectors match
+//	:has(A), :has(> A)              a descendant (or child) matches
+//	:has(~ A), :has(+ A)            a following (or the next) sibling matches
+//	:nth-child(n), :nth-last-child(n), :first-child, :last-child
+//	:statement, :expression, :declaration, :function, :pattern
+//
+// Type names are the Go type names of this package. The ESTree names
+// Literal, MemberExpression and Program are accepted as well. Attribute
+// names are matched against field names case-insensitively, so
+// [callee.name] reads CallExpression.Callee.Name. The value attribute of a
+// literal is its literal value.
+func Query(root JSElement, selector string) ([]JSElement, error) {
+	s, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.Query(root), nil
+}
+
+// Selector is a parsed esquery selector. See Query for the grammar.
+type Selector struct {
+	source string
+	alts   []*complexSelector
+}
+
+// ParseSelector parses an esquery selector so that it can be matched
+// repeatedly.
+func ParseSelector(selector string) (*Selector, error) {
+	p := &selectorParser{src: selector}
+	alts, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Selector{source: selector, alts: alts}, nil
+}
+
+func (s *Selector) String() string {
+	return s.source
+}
+
+// Query returns the nodes below and including root that match the selector,
+// in traversal order.
+func (s *Selector) Query(root JSElement) (out []JSElement) {
+	var visit func(p *NodePath)
+	visit = func(p *NodePath) {
+		if s.Match(p) {
+			out = append(out, p.Node)
+		}
+		for _, c := range p.children {
+			visit(c)
+		}
+	}
+	visit(NewPath(root))
+	return
+}
+
+// Match reports whether the node at p matches the selector.
+func (s *Selector) Match(p *NodePath) bool {
+	return matchAny(s.alts, p, nil)
+}
+
+func matchAny(alts []*complexSelector, p, scope *NodePath) bool {
+	for _, a := range alts {
+		if a.match(len(a.parts)-1, p, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// complexSelector is a chain of compound selectors joined by combinators,
+// eg. `A > B C`. combinators[i] joins parts[i] and parts[i+1].
+type complexSelector struct {
+	parts       []compoundSelector
+	combinators []byte
+}
+
+// match matches the chain right to left, starting with parts[i] at p.
+func (c *complexSelector) match(i int, p, scope *NodePath) bool {
+	if p == nil || !c.parts[i].match(p, scope) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	switch c.combinators[i-1] {
+	case ' ':
+		for a := p.Parent; a != nil; a = a.Parent {
+			if c.match(i-1, a, scope) {
+				return true
+			}
+		}
+	case '>':
+		return c.match(i-1, p.Parent, scope)
+	case '~':
+		for _, s := range p.Container() {
+			if s.Index < p.Index && c.match(i-1, s, scope) {
+				return true
+			}
+		}
+	case '+':
+		for _, s := range p.Container() {
+			if s.Index == p.Index-1 {
+				return c.match(i-1, s, scope)
+			}
+		}
+	}
+	return false
+}
+
+// compoundSelector matches when all of its atoms match, eg. `Identifier[name="a"]`.
+type compoundSelector []selectorAtom
+
+func (c compoundSelector) match(p, scope *NodePath) bool {
+	for _, a := range c {
+		if !a.match(p, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+type selectorAtom interface {
+	match(p, scope *NodePath) bool
+}
+
+type wildcardAtom struct{}
+
+func (wildcardAtom) match(p, scope *NodePath) bool { return true }
+
+// scopeAtom matches the node a relative selector inside :has is evaluated
+// against.
+type scopeAtom struct{}
+
+func (scopeAtom) match(p, scope *NodePath) bool { return p == scope }
+
+type typeAtom string
+
+func (t typeAtom) match(p, scope *NodePath) bool {
+	name := string(t)
+	if strings.EqualFold(nodeTypeName(p.Node), name) {
+		return true
+	}
+	switch strings.ToLower(name) {
+	case "literal":
+		_, ok := p.Node.(Literal)
+		return ok
+	case "memberexpression":
+		switch p.Node.(type) {
+		case *StaticMemberExpression, *ComputedMemberExpression:
+			return true
+		}
+	case "program":
+		_, ok := p.Node.(*Generator)
+		return ok
+	}
+	return false
+}
+
+// fieldAtom matches nodes held by the given chain of parent fields.
+type fieldAtom []string
+
+func (f fieldAtom) match(p, scope *NodePath) bool {
+	for i := len(f) - 1; i >= 0; i-- {
+		if p == nil || !strings.EqualFold(p.Field, f[i]) {
+			return false
+		}
+		p = p.Parent
+	}
+	return true
+}
+
+type classAtom string
+
+func (c classAtom) match(p, scope *NodePath) bool {
+	switch c {
+	case "statement":
+		_, ok := p.Node.(Statement)
+		return ok
+	case "expression":
+		_, ok := p.Node.(Expression)
+		return ok
+	case "declaration":
+		_, ok := p.Node.(Declaration)
+		return ok
+	case "function":
+		return IsFunction(p.Node)
+	case "pattern":
+		switch p.Node.(type) {
+		case BindingPattern, *AssignmentPattern, *RestElement:
+			return true
+		}
+		return p.Field == "ID" || p.Field == "Params" || p.Field == "BindingIdentifierOrPattern"
+	}
+	return false
+}
+
+type nthChildAtom struct {
+	n       int
+	fromEnd bool
+}
+
+func (a nthChildAtom) match(p, scope *NodePath) bool {
+	c := p.Container()
+	if c == nil {
+		return false
+	}
+	if a.fromEnd {
+		return len(c)-p.Index == a.n
+	}
+	return p.Index+1 == a.n
+}
+
+type matchesAtom []*complexSelector
+
+func (m matchesAtom) match(p, scope *NodePath) bool {
+	return matchAny(m, p, scope)
+}
+
+type notAtom []*complexSelector
+
+func (n notAtom) match(p, scope *NodePath) bool {
+	return !matchAny(n, p, scope)
+}
+
+type hasAtom []*complexSelector
+
+func (h hasAtom) match(p, scope *NodePath) bool {
+	var found bool
+	var visit func(d *NodePath)
+	visit = func(d *NodePath) {
+		if found {
+			return
+		}
+		if matchAny(h, d, p) {
+			found = true
+			return
+		}
+		for _, c := range d.children {
+			visit(c)
+		}
+	}
+	for _, c := range p.children {
+		visit(c)
+	}
+	// relative selectors starting with ~ or + match the siblings of p and
+	// their descendants
+	for _, c := range h {
+		if c.combinators[0] == '~' || c.combinators[0] == '+' {
+			for _, s := range p.Siblings() {
+				visit(s)
+			}
+			break
+		}
+	}
+	return found
+}
+
+type attributeAtom struct {
+	path []string
+	op   string // empty when only checking for presence
+	// one of
+	str      string
+	num      float64
+	isNum    bool
+	regex    *regexp.Regexp
+	jsType   string
+	isJSType bool
+}
+
+func (a *attributeAtom) match(p, scope *NodePath) bool {
+	v, ok := selectorAttribute(p.Node, a.path)
+	if a.op == "" {
+		return ok && v != nil && v != jsUndefined
+	}
+	if !ok {
+		return a.op == "!="
+	}
+
+	var eq bool
+	switch {
+	case a.regex != nil:
+		eq = a.regex.MatchString(jsToString(v))
+	case a.isJSType:
+		eq = jsTypeOf(v) == a.jsType
+	case a.op == "=" || a.op == "!=":
+		eq = jsToString(v) == a.str
+	default:
+		n, ok := v.(float64)
+		if !ok || !a.isNum {
+			return false
+		}
+		switch a.op {
+		case "<":
+			return n < a.num
+		case "<=":
+			return n <= a.num
+		case ">":
+			return n > a.num
+		case ">=":
+			return n >= a.num
+		}
+	}
+	if a.op == "!=" {
+		return !eq
+	}
+	return eq
+}
+
+// Attribute resolution
+
+type jsUndefinedValue struct{}
+
+var jsUndefined = jsUndefinedValue{}
+
+// selectorFieldAliases maps ESTree property names to field names that
+// don't match them case-insensitively.
+var selectorFieldAliases = map[string]string{
+	"argument": "Arguement",
+	"param":    "BindingIdentifierOrPattern",
+	"handler":  "Handler",
+}
+
+// selectorAttribute resolves an attribute path on node. Values are
+// returned as string, bool, float64, nil (null), jsUndefined or a
+// JSElement.
+func selectorAttribute(node JSElement, path []string) (interface{}, bool) {
+	v := reflect.ValueOf(node)
+	for _, seg := range path {
+		cur := unwrapInterface(v)
+		if cur.Kind() == reflect.Slice {
+			if strings.EqualFold(seg, "length") {
+				v = reflect.ValueOf(float64(cur.Len()))
+				continue
+			}
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= cur.Len() {
+				return nil, false
+			}
+			v = cur.Index(i)
+			continue
+		}
+		if isNilValue(cur) {
+			return nil, false
+		}
+		if e := valueToElement(cur); e != nil {
+			if strings.EqualFold(seg, "type") {
+				v = reflect.ValueOf(nodeTypeName(e))
+				continue
+			}
+			if l, ok := e.(Literal); ok && strings.EqualFold(seg, "value") {
+				v = reflect.ValueOf(literalValue(l))
+				continue
+			}
+		}
+		for cur.Kind() == reflect.Ptr {
+			cur = cur.Elem()
+		}
+		if cur.Kind() != reflect.Struct {
+			return nil, false
+		}
+		f, ok := selectorField(cur.Type(), seg)
+		if !ok {
+			return nil, false
+		}
+		v = cur.FieldByIndex(f.Index)
+	}
+	return selectorValue(v), true
+}
+
+func selectorField(t reflect.Type, name string) (reflect.StructField, bool) {
+	if alias, ok := selectorFieldAliases[strings.ToLower(name)]; ok {
+		if f, ok := t.FieldByName(alias); ok {
+			return f, true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !isPositionField(f) && strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func selectorValue(v reflect.Value) interface{} {
+	if v.IsValid() && v.Type() == reflect.TypeOf(jsUndefined) {
+		return jsUndefined
+	}
+	v = unwrapInterface(v)
+	if isNilValue(v) {
+		return nil
+	}
+	if e := valueToElement(v); e != nil {
+		return e
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Slice:
+		return v.Interface()
+	}
+	return nil
+}
+
+// literalValue returns the JavaScript value of a literal.
+func literalValue(l Literal) interface{} {
+	switch v := l.(type) {
+	case *LiteralValueString:
+		return string(*v)
+	case *LiteralValueBool:
+		return bool(*v)
+	case *LiteralValueNumber:
+		return float64(*v)
+	case *LiteralValueBigFloat:
+		f, _ := (*big.Float)(v).Float64()
+		return f
+	case *literalValueUndefined:
+		return jsUndefined
+	}
+	return nil
+}
+
+func jsToString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case nil:
+		return "null"
+	case jsUndefinedValue:
+		return "undefined"
+	case JSElement:
+		return t.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func jsTypeOf(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case jsUndefinedValue:
+		return "undefined"
+	}
+	return "object"
+}
+
+func nodeTypeName(n JSElement) string {
+	t := reflect.TypeOf(n)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := t.Name()
+	if name != "" && name[0] >= 'a' && name[0] <= 'z' {
+		// literalValueNull and literalValueUndefined
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	return name
+}
+
+// Parsing
+
+type selectorParser struct {
+	src string
+	pos int
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("goesprima: invalid selector %q at offset %d: %s", p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *selectorParser) parse() ([]*complexSelector, error) {
+	alts, err := p.parseList(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return alts, nil
+}
+
+func (p *selectorParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte(" \t\n\r", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) expect(c byte) error {
+	p.skipSpace()
+	if p.peek() != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+// parseList parses comma separated selectors. Relative selectors, as used
+// by :has, may start with a combinator.
+func (p *selectorParser) parseList(relative bool) (alts []*complexSelector, err error) {
+	for {
+		c, err := p.parseComplex(relative)
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, c)
+		p.skipSpace()
+		if p.peek() != ',' {
+			return alts, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseComplex(relative bool) (*complexSelector, error) {
+	c := new(complexSelector)
+	p.skipSpace()
+	if relative {
+		comb := byte(' ')
+		if b := p.peek(); b == '>' || b == '~' || b == '+' {
+			comb = b
+			p.pos++
+			p.skipSpace()
+		}
+		c.parts = append(c.parts, compoundSelector{scopeAtom{}})
+		c.combinators = append(c.combinators, comb)
+	}
+
+	for {
+		part, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		c.parts = append(c.parts, part)
+
+		space := p.skipSpace()
+		comb := p.peek()
+		switch {
+		case comb == '>' || comb == '~' || comb == '+':
+			p.pos++
+			p.skipSpace()
+		case comb == ',' || comb == ')' || comb == 0:
+			return c, nil
+		case space:
+			comb = ' '
+		default:
+			return nil, p.errorf("unexpected %q", comb)
+		}
+		c.combinators = append(c.combinators, comb)
+	}
+}
+
+func (p *selectorParser) parseCompound() (c compoundSelector, err error) {
+	for {
+		var atom selectorAtom
+		switch b := p.peek(); {
+		case b == '*':
+			p.pos++
+			atom = wildcardAtom{}
+		case b == '[':
+			p.pos++
+			atom, err = p.parseAttribute()
+		case b == ':':
+			p.pos++
+			atom, err = p.parsePseudo()
+		case b == '.':
+			var f fieldAtom
+			for p.peek() == '.' {
+				p.pos++
+				f = append(f, p.parseName())
+			}
+			atom = f
+		case isSelectorNameByte(b, true):
+			atom = typeAtom(p.parseName())
+		default:
+			if len(c) == 0 {
+				return nil, p.errorf("expected selector")
+			}
+			return c, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		c = append(c, atom)
+	}
+}
+
+func isSelectorNameByte(b byte, first bool) bool {
+	return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') ||
+		(!first && (b == '-' || (b >= '0' && b <= '9')))
+}
+
+func (p *selectorParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.src) && isSelectorNameByte(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// parseIndex parses a list index in an attribute path.
+func (p *selectorParser) parseIndex() string {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *selectorParser) parseAttribute() (selectorAtom, error) {
+	p.skipSpace()
+	a := new(attributeAtom)
+	for {
+		name := p.parseName()
+		if name == "" && len(a.path) > 0 {
+			name = p.parseIndex()
+		}
+		if name == "" {
+			return nil, p.errorf("expected attribute name")
+		}
+		a.path = append(a.path, name)
+		if p.peek() != '.' {
+			break
+		}
+		p.pos++
+	}
+
+	p.skipSpace()
+	for _, op := range []string{"!=", "<=", ">=", "=", "<", ">"} {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			a.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if a.op == "" {
+		return a, p.expect(']')
+	}
+
+	p.skipSpace()
+	switch b := p.peek(); {
+	case b == '"' || b == '\'':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		a.str = s
+	case b == '/':
+		re, err := p.parseRegex()
+		if err != nil {
+			return nil, err
+		}
+		a.regex = re
+	case b == '-' || b == '.' || (b >= '0' && b <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.src[start:p.pos])
+		}
+		a.num, a.isNum = n, true
+		a.str = jsToString(n)
+	default:
+		name := p.parseName()
+		if name == "" {
+			return nil, p.errorf("expected attribute value")
+		}
+		if name == "type" && p.peek() == '(' {
+			p.pos++
+			p.skipSpace()
+			a.jsType, a.isJSType = p.parseName(), true
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+			break
+		}
+		for p.peek() == '.' {
+			p.pos++
+			name += "." + p.parseName()
+		}
+		a.str = name
+	}
+	if (a.regex != nil || a.isJSType) && a.op != "=" && a.op != "!=" {
+		return nil, p.errorf("operator %s cannot be used with this value", a.op)
+	}
+	return a, p.expect(']')
+}
+
+func (p *selectorParser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '\\' && p.pos+1 < len(p.src):
+			p.pos++
+			sb.WriteByte(p.src[p.pos])
+		default:
+			sb.WriteByte(c)
+		}
+		p.pos++
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+func (p *selectorParser) parseRegex() (*regexp.Regexp, error) {
+	start := p.pos
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.src) && p.src[p.pos] != '/' {
+		if p.src[p.pos] == '\\' && p.pos+1 < len(p.src) {
+			sb.WriteByte('\\')
+			p.pos++
+		}
+		sb.WriteByte(p.src[p.pos])
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		p.pos = start
+		return nil, p.errorf("unterminated regular expression")
+	}
+	p.pos++
+
+	var flags string
+	for p.pos < len(p.src) && strings.IndexByte("imsu", p.src[p.pos]) >= 0 {
+		if p.src[p.pos] != 'u' {
+			flags += string(p.src[p.pos])
+		}
+		p.pos++
+	}
+	expr := sb.String()
+	if flags != "" {
+		expr = "(?" + flags + ")" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("%v", err)
+	}
+	return re, nil
+}
+
+func (p *selectorParser) parsePseudo() (selectorAtom, error) {
+	name := strings.ToLower(p.parseName())
+	switch name {
+	case "statement", "expression", "declaration", "function", "pattern":
+		return classAtom(name), nil
+	case "first-child":
+		return nthChildAtom{n: 1}, nil
+	case "last-child":
+		return nthChildAtom{n: 1, fromEnd: true}, nil
+	case "nth-child", "nth-last-child":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+		n, err := strconv.Atoi(p.src[start:p.pos])
+		if err != nil || n < 1 {
+			return nil, p.errorf("expected a positive integer")
+		}
+		return nthChildAtom{n: n, fromEnd: name == "nth-last-child"}, p.expect(')')
+	case "matches", "is", "not", "has":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		alts, err := p.parseList(name == "has")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		switch name {
+		case "not":
+			return notAtom(alts), nil
+		case "has":
+			return hasAtom(alts), nil
+		}
+		return matchesAtom(alts), nil
+	}
+	return nil, p.errorf("unknown pseudo-class :%s", name)
+}
@@ -0,0 +1,94 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func queryTestProgram() *Program {
+	require := func(name, path string) *VariableDeclaration {
+		return &VariableDeclaration{
+			Kind: VariableDeclarationTypeConst,
+			Declarations: []VariableDeclarator{
+				{
+					ID: &Identifier{Name: name},
+					Init: &CallExpression{
+						Callee:    &Identifier{Name: "require"},
+						Arguments: []ArgumentListElement{StringLiteral(path)},
+					},
+				},
+			},
+		}
+	}
+	return &Program{
+		Body: []StatementListItem{
+			require("core", "@aws-amplify/core"),
+			require("fs", "fs"),
+			&ExpressionStatement{
+				Expression: &CallExpression{
+					Callee:    &Identifier{Name: "configure"},
+					Arguments: []ArgumentListElement{NumberLiteral(3), &Identifier{Name: "fs"}},
+					Optional:  true,
+				},
+			},
+			&FunctionDeclaration{
+				ID: &Identifier{Name: "main"},
+				Body: BlockStatement{
+					Items: []Statement{
+						&ReturnStatement{Argument: &AwaitExpression{Arguement: &Identifier{Name: "core"}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestQuery(t *testing.T) {
+	program := queryTestProgram()
+	tests := []struct {
+		Selector string
+		Expect   []string
+	}{
+		{`CallExpression[callee.name="require"]`, []string{`require("@aws-amplify/core")`, `require("fs")`}},
+		{`Literal[value=/^@aws-amplify\//]`, []string{`"@aws-amplify/core"`}},
+		{`[value=type(number)]`, []string{`3.000000`}},
+		{`[value>2]`, []string{`3.000000`}},
+		{`CallExpression[optional=true] > Identifier`, []string{`configure`, `fs`}},
+		{`CallExpression[optional=false] > .Callee`, []string{`require`, `require`}},
+		{`VariableDeclarator > Identifier.ID`, []string{`core`, `fs`}},
+		{`CallExpression[arguments.length>1] > .Callee`, []string{`configure`}},
+		{`CallExpression[arguments.0.value="fs"] > .Callee`, []string{`require`}},
+		{`[declarations.0.id.name="fs"]`, []string{`const fs = require("fs")`}},
+		{`[arguments.2]`, nil},
+		{`VariableDeclaration ~ ExpressionStatement Identifier.Callee`, []string{`configure`}},
+		{`VariableDeclaration + FunctionDeclaration`, nil},
+		{`FunctionDeclaration AwaitExpression > Identifier`, []string{`core`}},
+		{`:function:has(AwaitExpression) > Identifier.ID`, []string{`main`}},
+		{`VariableDeclaration:has(> VariableDeclarator > Identifier[name="fs"])`, []string{`const fs = require("fs")`}},
+		{`VariableDeclaration:has(~ FunctionDeclaration)`, []string{`const core = require("@aws-amplify/core")`, `const fs = require("fs")`}},
+		{`VariableDeclaration:has(+ ExpressionStatement)`, []string{`const fs = require("fs")`}},
+		{`:has(+ FunctionDeclaration AwaitExpression)`, []string{`configure?.(3.000000, fs);`}},
+		{`FunctionDeclaration:has(~ *)`, nil},
+		{`Identifier:not([name=/^(require|configure)$/], .ID)`, []string{`fs`, `core`}},
+		{`:matches(AwaitExpression, ReturnStatement)`, []string{`return await core;`, `await core`}},
+		{`Program > :nth-child(2)`, []string{`const fs = require("fs")`}},
+		{`Program > :last-child > .ID`, []string{`main`}},
+		{`CallExpression > Literal:first-child, ExpressionStatement :nth-last-child(1)`, []string{`"@aws-amplify/core"`, `"fs"`, `3.000000`, `fs`}},
+		{`[optional] > Identifier.Callee`, []string{`require`, `require`, `configure`}},
+	}
+	for _, test := range tests {
+		nodes, err := Query(program, test.Selector)
+		assert.NoError(t, err, test.Selector)
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.String())
+		}
+		assert.Equal(t, test.Expect, out, test.Selector)
+	}
+
+	for _, invalid := range []string{``, `A >`, `[name`, `:unknown`, `[name=/(/]`, `A:nth-child(0)`} {
+		_, err := Query(program, invalid)
+		assert.Error(t, err, invalid)
+	}
+}
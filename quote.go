@@ -0,0 +1,157 @@
+package goesprima
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// QuoteOptions controls the escaping of Quote.
+type QuoteOptions struct {
+	Quote QuoteStyle
+	// ASCIIOnly escapes the characters that aren't ASCII.
+	ASCIIOnly bool
+	// HTMLSafe escapes </script and <!--, so that the literal can be
+	// inlined in an HTML script element.
+	HTMLSafe bool
+}
+
+// Quote returns s as a JavaScript string literal. Line terminators, control
+// characters and lone surrogates, which s holds as in WTF-8, are escaped.
+// Bytes of s that aren't UTF-8 are replaced by U+FFFD.
+func Quote(s string, opts QuoteOptions) string {
+	q := byte('"')
+	if opts.Quote == QuoteSingle {
+		q = '\''
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte(q)
+	for i := 0; i < len(s); {
+		r, n := decodeWTF8(s[i:])
+		switch {
+		case r == rune(q) || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\b':
+			b.WriteString(`\b`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == '\v':
+			b.WriteString(`\v`)
+		case r == 0:
+			// \0 followed by a digit would be an octal escape
+			if i+1 < len(s) && isDecimalDigit(rune(s[i+1])) {
+				b.WriteString(`\x00`)
+			} else {
+				b.WriteString(`\0`)
+			}
+		case r < ' ':
+			b.WriteString(`\x`)
+			writeHex(&b, r, 2)
+		case r == '<' && opts.HTMLSafe && closesScript(s[i+1:]):
+			b.WriteString(`<\`)
+		case r >= utf8.RuneSelf && (opts.ASCIIOnly || mustEscape(r, n)):
+			writeUnicodeEscape(&b, r)
+		default:
+			b.WriteString(s[i : i+n])
+		}
+		i += n
+	}
+	b.WriteByte(q)
+	return b.String()
+}
+
+// escapeTemplate escapes the raw text of a template element as Quote does
+// with opts, leaving its escape sequences alone.
+func escapeTemplate(raw string, opts QuoteOptions) string {
+	if !opts.ASCIIOnly && !opts.HTMLSafe {
+		return raw
+	}
+	var b strings.Builder
+	for i := 0; i < len(raw); {
+		r, n := decodeWTF8(raw[i:])
+		switch {
+		case r == '\\' && i+1 < len(raw):
+			next, m := decodeWTF8(raw[i+1:])
+			switch {
+			case next < utf8.RuneSelf || !opts.ASCIIOnly:
+				b.WriteString(raw[i : i+1+m])
+			case next == '\u2028' || next == '\u2029':
+				// a line continuation, which adds nothing
+			default:
+				// an escaped character is the character
+				writeUnicodeEscape(&b, next)
+			}
+			n += m
+		case r == '<' && opts.HTMLSafe && closesScript(raw[i+1:]):
+			b.WriteString(`<\`)
+		case r >= utf8.RuneSelf && (opts.ASCIIOnly || mustEscape(r, n)):
+			writeUnicodeEscape(&b, r)
+		default:
+			b.WriteString(raw[i : i+n])
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// decodeWTF8 decodes the first character of s, which may be a lone
+// surrogate encoded like other characters of the Basic Multilingual Plane.
+func decodeWTF8(s string) (rune, int) {
+	if len(s) >= 3 && s[0] == 0xed && s[1]&0xe0 == 0xa0 && s[2]&0xc0 == 0x80 {
+		return 0xd000 | rune(s[1]&0x3f)<<6 | rune(s[2]&0x3f), 3
+	}
+	return utf8.DecodeRuneInString(s)
+}
+
+// writeWTF8 writes r in UTF-8, or as in WTF-8 if it is a lone surrogate.
+func writeWTF8(b *strings.Builder, r rune) {
+	if !utf16.IsSurrogate(r) {
+		b.WriteRune(r)
+		return
+	}
+	b.WriteByte(0xe0 | byte(r>>12))
+	b.WriteByte(0x80 | byte(r>>6)&0x3f)
+	b.WriteByte(0x80 | byte(r)&0x3f)
+}
+
+// mustEscape reports whether the character r, decoded from n bytes, is
+// escaped even in non-ASCII output: line and paragraph separators, which
+// end lines in older engines, lone surrogates and invalid bytes.
+func mustEscape(r rune, n int) bool {
+	return r == '\u2028' || r == '\u2029' || utf16.IsSurrogate(r) || r == utf8.RuneError && n == 1
+}
+
+// closesScript reports whether s, following a <, would end or confuse an
+// HTML script element.
+func closesScript(s string) bool {
+	const tag = "/script"
+	return len(s) >= len(tag) && strings.EqualFold(s[:len(tag)], tag) || strings.HasPrefix(s, "!--")
+}
+
+// writeUnicodeEscape writes r as \uXXXX, or as a surrogate pair outside the
+// Basic Multilingual Plane.
+func writeUnicodeEscape(b *strings.Builder, r rune) {
+	if r > 0xffff {
+		r1, r2 := utf16.EncodeRune(r)
+		writeUnicodeEscape(b, r1)
+		writeUnicodeEscape(b, r2)
+		return
+	}
+	b.WriteString(`\u`)
+	writeHex(b, r, 4)
+}
+
+func writeHex(b *strings.Builder, r rune, digits int) {
+	const hex = "0123456789ABCDEF"
+	for i := digits - 1; i >= 0; i-- {
+		b.WriteByte(hex[r>>(4*i)&0xf])
+	}
+}
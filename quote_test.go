@@ -0,0 +1,62 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		s      string
+		opts   QuoteOptions
+		expect string
+	}{
+		{`a "b" 'c' \`, QuoteOptions{}, `"a \"b\" 'c' \\"`},
+		{`a "b" 'c'`, QuoteOptions{Quote: QuoteSingle}, `'a "b" \'c\''`},
+		// unlike JSON, HTML characters are left alone
+		{"<a & b>", QuoteOptions{}, `"<a & b>"`},
+		{"\n\r\t\b\f\v\x01\x1f\x7f", QuoteOptions{}, "\"\\n\\r\\t\\b\\f\\v\\x01\\x1F\x7f\""},
+		{"\x00a\x001", QuoteOptions{}, `"\0a\x001"`},
+		{"a\u2028b\u2029", QuoteOptions{}, `"a\u2028b\u2029"`},
+		// lone surrogates are held as in WTF-8
+		{"\xed\xa0\x80x\xed\xbf\xbf", QuoteOptions{}, `"\uD800x\uDFFF"`},
+		{"a\xffb", QuoteOptions{}, `"a\uFFFDb"`},
+		{"\u00e9\U0001F600", QuoteOptions{}, "\"\u00e9\U0001F600\""},
+		{"\u00e9\U0001F600", QuoteOptions{ASCIIOnly: true}, `"\u00E9\uD83D\uDE00"`},
+		{"</script><!-- </SCRIPT", QuoteOptions{HTMLSafe: true}, `"<\/script><\!-- <\/SCRIPT"`},
+		{"</scrip <!-", QuoteOptions{HTMLSafe: true}, `"</scrip <!-"`},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expect, Quote(test.s, test.opts), test.s)
+	}
+}
+
+func TestQuoteRoundTrip(t *testing.T) {
+	for _, src := range []string{
+		"x = \"\U00010000 \\uD83D \\u{DFFF}\";",
+		"x = \"\\0 \\x001 \u2028 \\x7F\";",
+	} {
+		prog, err := Parse("test.js", src)
+		if !assert.NoError(t, err, src) {
+			continue
+		}
+		out := (&PrinterConfig{ASCIIOnly: true}).Sprint(prog)
+		again, err := Parse("test.js", out)
+		if assert.NoError(t, err, out) {
+			assert.True(t, Equal(prog, again, EqualOptions{IgnoreLocations: true}), out)
+		}
+	}
+
+	prog, err := Parse("test.js", `x = "\uD83D!";`)
+	assert.NoError(t, err)
+	assert.Equal(t, `x = "\uD83D!";`, prog.String())
+}
+
+func TestPrinterEscapes(t *testing.T) {
+	prog, err := Parse("test.js", "import a from \"./\u00e9.js\";\nx = [\"</script>\", `</script> \u00e9 \\\u00e9 ${y}`];")
+	assert.NoError(t, err)
+	assert.Equal(t, "import a from \"./\u00e9.js\";\nx = [\n  \"</script>\", `</script> \u00e9 \\\u00e9 ${y}`,\n];", prog.String())
+	assert.Equal(t, "import a from \"./\\u00E9.js\";\nx = [\n  \"<\\/script>\", `<\\/script> \\u00E9 \\u00E9 ${y}`,\n];",
+		(&PrinterConfig{ASCIIOnly: true, HTMLSafe: true}).Sprint(prog))
+}
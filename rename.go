@@ -0,0 +1,129 @@
+package goesprima
+
+import "fmt"
+
+// Rename renames the binding declared or referred to by target, along with
+// every reference to it, to newName. The program is analyzed as a module.
+//
+// Rename fails without changing the program if newName isn't a valid
+// identifier, if the binding is exported by its declaration, or if the new
+// name would conflict with another binding: by redeclaring it, by being
+// shadowed at a reference, or by capturing a reference to another variable
+// or global of that name.
+//
+// Exported and imported names are kept, so shorthand properties become
+// {a: b}, export {a} becomes export {b as a} and import {a} becomes
+// import {a as b}.
+func Rename(program *Program, target *Identifier, newName string) error {
+	if !isIdentifierName(newName) || reservedWords[newName] {
+		return fmt.Errorf("goesprima: %s is not a valid identifier", newName)
+	}
+	m := Analyze(program, ScopeOptions{})
+	v := m.VariableOf(target)
+	if v == nil || len(v.Defs) == 0 {
+		return fmt.Errorf("goesprima: %s is not declared in the program", target.Name)
+	}
+	if v.Name == newName {
+		return nil
+	}
+	// a class declaration also binds its name inside the class
+	vars := []*Variable{v}
+	if d := v.Defs[0]; d.Type == DefinitionClass {
+		if _, ok := d.Node.(*ClassDeclaration); ok {
+			v = m.VariableOf(d.Name)
+			vars = []*Variable{v, m.Acquire(d.Node).Lookup(v.Name)}
+		}
+	}
+
+	exported := exportedDeclarations(program)
+	for _, d := range v.Defs {
+		if d.Type != DefinitionParameter && (exported[d.Node] || exported[d.Parent]) {
+			return fmt.Errorf("goesprima: %s is exported by its declaration", v.Name)
+		}
+	}
+	if v.Scope.Lookup(newName) != nil {
+		return fmt.Errorf("goesprima: %s is already declared in the scope of %s", newName, v.Name)
+	}
+	for _, w := range vars {
+		for _, r := range w.References {
+			for s := r.From; s != v.Scope; s = s.Upper {
+				if s.Lookup(newName) != nil {
+					return fmt.Errorf("goesprima: %s would be shadowed by another %s", v.Name, newName)
+				}
+			}
+		}
+	}
+	for _, r := range v.Scope.Through {
+		if r.Identifier.Name == newName {
+			return fmt.Errorf("goesprima: %s would capture a reference to another %s", v.Name, newName)
+		}
+	}
+
+	ids := make(map[*Identifier]bool)
+	for _, w := range vars {
+		for _, id := range w.Identifiers {
+			ids[id] = true
+		}
+		for _, r := range w.References {
+			ids[r.Identifier] = true
+		}
+	}
+
+	// keep property, export and import names
+	keep := func(id *Identifier) *Identifier {
+		return &Identifier{Name: id.Name, Node: id.Node}
+	}
+	Inspect(program, func(n JSElement) bool {
+		switch n := n.(type) {
+		case *Property:
+			if key, ok := n.Key.(*Identifier); ok && !n.Computed && ids[key] {
+				if n.Value == nil {
+					n.Value = key
+				}
+				n.Key = keep(key)
+				n.ShortHand = false
+			}
+			if n.ShortHand && renamesShorthand(n.Value, ids) {
+				n.ShortHand = false
+			}
+		case *PropertyPattern:
+			if key, ok := n.Key.(*Identifier); ok && !n.Computed && ids[key] {
+				if n.Value == nil {
+					n.Value = key
+				}
+				n.Key = keep(key)
+				n.ShortHand = false
+			}
+			if n.ShortHand && renamesShorthand(n.Value, ids) {
+				n.ShortHand = false
+			}
+		case *ExportSpecifier:
+			if ids[n.Exported] {
+				n.Local = n.Exported
+				n.Exported = keep(n.Exported)
+			}
+		case *NamedImport:
+			if ids[n.Imported] {
+				n.Local = n.Imported
+				n.Imported = keep(n.Imported)
+			}
+		}
+		return true
+	})
+	for id := range ids {
+		id.Name = newName
+	}
+	return nil
+}
+
+// renamesShorthand tells whether the value of a shorthand property is
+// renamed.
+func renamesShorthand(value JSElement, ids map[*Identifier]bool) bool {
+	switch v := value.(type) {
+	case *Identifier:
+		return ids[v]
+	case *AssignmentPattern:
+		return renamesShorthand(v.Left, ids)
+	}
+	return false
+}
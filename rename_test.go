@@ -0,0 +1,85 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRename(t *testing.T) {
+	prog, err := Parse("test.js", `import { a, c as d } from "m";
+import def from "n";
+const o = { a, d };
+const { a: x = a, q = d } = o;
+function f(q) {
+  return d + q;
+}
+export { a, d as e, def };`)
+	assert.NoError(t, err)
+	imports := prog.Body[0].(*ImportDeclaration).Specifiers[0].(*ImportSpecifier)
+
+	assert.NoError(t, Rename(prog, imports.NamedImports[0].Imported, "b"))
+	assert.NoError(t, Rename(prog, imports.NamedImports[1].Local, "c"))
+	fn := prog.Body[4].(*FunctionDeclaration)
+	assert.NoError(t, Rename(prog, fn.Params[0].(*Identifier), "r"))
+
+	q := prog.Body[3].(*VariableDeclaration).Declarations[0].ID.(*ObjectPattern).Properties[1].(*PropertyPattern)
+	assert.NoError(t, Rename(prog, q.Value.(*AssignmentPattern).Left.(*Identifier), "p"))
+
+	assert.Equal(t, `import { a as b, c } from "m";
+import def from "n";
+const o = {
+  a: b,
+  d: c,
+}
+const {
+  a: x=b,
+  q: p=c,
+} = o
+function f(r) {
+  return c + r;
+}
+export { b as a, c as e, def };`, prog.String())
+}
+
+func TestRenameClass(t *testing.T) {
+	prog, err := Parse("test.js", "class A {\n  static x = new A();\n}\nA.x;")
+	assert.NoError(t, err)
+	decl := prog.Body[0].(*ClassDeclaration)
+	ref := decl.Body.Properties[0].(*PropertyDefinition).Value.(*NewExpression).Callee.(*Identifier)
+	assert.NoError(t, Rename(prog, ref, "B"))
+	assert.Equal(t, "class B {\n  static x = new B();\n}\nB.x;", prog.String())
+}
+
+func TestRenameConflicts(t *testing.T) {
+	src := `import { a } from "m";
+let b = 1;
+function f(c) {
+  return a + c + g;
+}
+export const e = a;`
+	tests := []struct {
+		Name   string
+		Expect string
+	}{
+		{"b", "goesprima: b is already declared in the scope of a"},
+		{"c", "goesprima: a would be shadowed by another c"},
+		{"g", "goesprima: a would capture a reference to another g"},
+		{"class", "goesprima: class is not a valid identifier"},
+		{"1x", "goesprima: 1x is not a valid identifier"},
+	}
+	for _, test := range tests {
+		prog, err := Parse("test.js", src)
+		assert.NoError(t, err)
+		before := prog.String()
+		target := prog.Body[0].(*ImportDeclaration).Specifiers[0].(*ImportSpecifier).NamedImports[0].Imported
+		assert.EqualError(t, Rename(prog, target, test.Name), test.Expect)
+		assert.Equal(t, before, prog.String())
+	}
+
+	prog, err := Parse("test.js", src)
+	assert.NoError(t, err)
+	decl := prog.Body[3].(*ExportNamedDeclaration).Declaration.(*VariableDeclaration)
+	assert.EqualError(t, Rename(prog, decl.Declarations[0].ID.(*Identifier), "x"), "goesprima: e is exported by its declaration")
+	assert.EqualError(t, Rename(prog, &Identifier{Name: "g"}, "x"), "goesprima: g is not declared in the program")
+}
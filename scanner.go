@@ -0,0 +1,582 @@
+package goesprima
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// identifiers and keywords
+	tokenIdentifier
+	tokenPunctuator
+	tokenString
+	tokenNumber
+	// a part of a template literal up to the next substitution or the
+	// closing backtick
+	tokenTemplate
+	// a %[name]s placeholder in a code template
+	tokenPlaceholder
+)
+
+type token struct {
+	kind tokenKind
+	// value is the identifier name, punctuator, cooked string or template
+	// part or the placeholder name.
+	value  string
+	raw    string
+	number float64
+	// tail marks the last part of a template literal.
+	tail bool
+	// escaped marks identifiers containing unicode escapes, which can't be
+	// keywords.
+	escaped bool
+	// newline reports whether a line terminator precedes the token.
+	newline bool
+
+	start, end       int
+	startPos, endPos Position
+}
+
+// A SyntaxError is returned by Parse and ParseTemplate for malformed input.
+type SyntaxError struct {
+	Source string
+	Position
+	Offset  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.Source, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+}
+
+// scanner splits JavaScript source into tokens on demand. It is a plain
+// value so that the parser can save and restore its state when it needs to
+// look ahead. Offsets are counted in bytes and columns in UTF-16 code
+// units, like the indices of JavaScript strings. Lines start at 1.
+type scanner struct {
+	src    string
+	source string
+	// placeholders enables %[name]s tokens.
+	placeholders bool
+
+	offset    int
+	line      int
+	lineStart int
+	// col is the column of colOffset, cached as columns are counted from
+	// the start of the line.
+	col, colOffset int
+}
+
+func newScanner(source, src string) scanner {
+	return scanner{src: src, source: source, line: 1}
+}
+
+func (s *scanner) pos() Position {
+	if s.colOffset < s.lineStart || s.colOffset > s.offset {
+		s.col, s.colOffset = 0, s.lineStart
+	}
+	s.col += utf16Len(s.src[s.colOffset:s.offset])
+	s.colOffset = s.offset
+	return Position{Line: s.line, Column: s.col}
+}
+
+// utf16Len returns the number of UTF-16 code units encoding s.
+func utf16Len(s string) (n int) {
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return
+}
+
+func (s *scanner) errorf(offset int, format string, args ...interface{}) {
+	line, lineStart := 1, 0
+	end := offset
+	if end > len(s.src) {
+		end = len(s.src)
+	}
+	for i := 0; i < end; i++ {
+		if s.src[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	panic(bailout{&SyntaxError{
+		Source:   s.source,
+		Position: Position{Line: line, Column: utf16Len(s.src[lineStart:end])},
+		Offset:   offset,
+		Message:  fmt.Sprintf(format, args...),
+	}})
+}
+
+func (s *scanner) peekRune(offset int) (rune, int) {
+	if offset >= len(s.src) {
+		return -1, 0
+	}
+	if c := s.src[offset]; c < utf8.RuneSelf {
+		return rune(c), 1
+	}
+	return utf8.DecodeRuneInString(s.src[offset:])
+}
+
+func isLineTerminator(r rune) bool {
+	return r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'
+}
+
+// newline advances past a line terminator of size n.
+func (s *scanner) newline(r rune, n int) {
+	s.offset += n
+	if r == '\r' && s.offset < len(s.src) && s.src[s.offset] == '\n' {
+		s.offset++
+	}
+	s.line++
+	s.lineStart = s.offset
+}
+
+// skipSpace skips whitespace and comments and reports whether a line
+// terminator was skipped.
+func (s *scanner) skipSpace() (newline bool) {
+	for s.offset < len(s.src) {
+		r, n := s.peekRune(s.offset)
+		switch {
+		case isLineTerminator(r):
+			s.newline(r, n)
+			newline = true
+		case r == ' ' || r == '\t' || r == '\v' || r == '\f' || r == '\ufeff' || unicode.Is(unicode.Zs, r):
+			s.offset += n
+		case r == '/' && strings.HasPrefix(s.src[s.offset:], "//"):
+			s.skipLineComment()
+		case r == '/' && strings.HasPrefix(s.src[s.offset:], "/*"):
+			start := s.offset
+			s.offset += 2
+			for {
+				if s.offset >= len(s.src) {
+					s.errorf(start, "unterminated comment")
+				}
+				r, n := s.peekRune(s.offset)
+				if r == '*' && strings.HasPrefix(s.src[s.offset:], "*/") {
+					s.offset += 2
+					break
+				}
+				if isLineTerminator(r) {
+					s.newline(r, n)
+					newline = true
+					continue
+				}
+				s.offset += n
+			}
+		default:
+			return
+		}
+	}
+	return
+}
+
+func (s *scanner) skipLineComment() {
+	for s.offset < len(s.src) {
+		r, n := s.peekRune(s.offset)
+		if isLineTerminator(r) {
+			return
+		}
+		s.offset += n
+	}
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || r == '\\' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' ||
+		r >= utf8.RuneSelf && (unicode.IsLetter(r) || unicode.Is(unicode.Nl, r))
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || '0' <= r && r <= '9' || r == '\u200c' || r == '\u200d' ||
+		r >= utf8.RuneSelf && (unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc))
+}
+
+func isDecimalDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+// Punctuators ordered by length so the longest match wins.
+var punctuators = []string{
+	">>>=",
+	"...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<", ">>", "**",
+	"{", "}", "(", ")", "[", "]", ";", ",", "<", ">", "+", "-", "*",
+	"/", "%", "&", "|", "^", "!", "~", "?", ":", "=", ".", "@", "#",
+}
+
+// next scans the next token.
+func (s *scanner) next() (t token) {
+	t.newline = s.skipSpace()
+	t.start = s.offset
+	t.startPos = s.pos()
+	defer func() {
+		t.end = s.offset
+		t.endPos = s.pos()
+		if t.kind != tokenTemplate {
+			t.raw = s.src[t.start:t.end]
+		}
+	}()
+
+	if s.offset >= len(s.src) {
+		t.kind = tokenEOF
+		return
+	}
+
+	r, _ := s.peekRune(s.offset)
+	switch {
+	case isIdentifierStart(r):
+		t.kind = tokenIdentifier
+		t.value, t.escaped = s.scanIdentifier()
+	case isDecimalDigit(r) || r == '.' && s.offset+1 < len(s.src) && isDecimalDigit(rune(s.src[s.offset+1])):
+		t.kind = tokenNumber
+		t.number = s.scanNumber()
+	case r == '"' || r == '\'':
+		t.kind = tokenString
+		t.value = s.scanString(byte(r))
+	case r == '`':
+		s.offset++
+		t.kind = tokenTemplate
+		t.value, t.raw, t.tail = s.scanTemplate()
+	case r == '%' && s.placeholders && strings.HasPrefix(s.src[s.offset:], "%["):
+		t.kind = tokenPlaceholder
+		t.value = s.scanPlaceholder()
+	default:
+		t.kind = tokenPunctuator
+		for _, p := range punctuators {
+			if strings.HasPrefix(s.src[s.offset:], p) {
+				// a?.5:0 is a conditional expression
+				if p == "?." && s.offset+2 < len(s.src) && isDecimalDigit(rune(s.src[s.offset+2])) {
+					continue
+				}
+				t.value = p
+				s.offset += len(p)
+				return
+			}
+		}
+		s.errorf(s.offset, "unexpected character %q", r)
+	}
+	return
+}
+
+func (s *scanner) scanIdentifier() (name string, escaped bool) {
+	var b strings.Builder
+	for s.offset < len(s.src) {
+		r, n := s.peekRune(s.offset)
+		if r == '\\' {
+			start := s.offset
+			if !strings.HasPrefix(s.src[s.offset:], "\\u") {
+				s.errorf(start, "invalid escape in identifier")
+			}
+			s.offset += 2
+			r = s.scanUnicodeEscape(start)
+			if b.Len() == 0 && !isIdentifierStart(r) || !isIdentifierPart(r) || r == '\\' {
+				s.errorf(start, "invalid escape in identifier")
+			}
+			b.WriteRune(r)
+			escaped = true
+			continue
+		}
+		if !isIdentifierPart(r) {
+			break
+		}
+		b.WriteRune(r)
+		s.offset += n
+	}
+	return b.String(), escaped
+}
+
+// scanUnicodeEscape scans the part of \uXXXX or \u{X...} following \u.
+func (s *scanner) scanUnicodeEscape(start int) rune {
+	if s.offset < len(s.src) && s.src[s.offset] == '{' {
+		end := strings.IndexByte(s.src[s.offset:], '}')
+		if end < 0 {
+			s.errorf(start, "invalid unicode escape")
+		}
+		v, err := strconv.ParseUint(s.src[s.offset+1:s.offset+end], 16, 32)
+		if err != nil || v > unicode.MaxRune {
+			s.errorf(start, "invalid unicode escape")
+		}
+		s.offset += end + 1
+		return rune(v)
+	}
+	if s.offset+4 > len(s.src) {
+		s.errorf(start, "invalid unicode escape")
+	}
+	v, err := strconv.ParseUint(s.src[s.offset:s.offset+4], 16, 32)
+	if err != nil {
+		s.errorf(start, "invalid unicode escape")
+	}
+	s.offset += 4
+	return rune(v)
+}
+
+func (s *scanner) scanNumber() float64 {
+	start := s.offset
+	base := 10
+	if s.src[s.offset] == '0' && s.offset+1 < len(s.src) {
+		switch s.src[s.offset+1] | 0x20 {
+		case 'x':
+			base = 16
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+		if base != 10 {
+			s.offset += 2
+		}
+	}
+
+	digits := func(base int) {
+		for s.offset < len(s.src) {
+			c := s.src[s.offset]
+			if c == '_' || digitValue(c) < base {
+				s.offset++
+				continue
+			}
+			break
+		}
+	}
+
+	var f float64
+	if base != 10 {
+		digits(base)
+		text := strings.ReplaceAll(s.src[start+2:s.offset], "_", "")
+		f = s.parseInt(start, text, base)
+	} else {
+		digits(10)
+		text := s.src[start:s.offset]
+		legacyOctal := len(text) > 1 && text[0] == '0' && strings.Trim(text, "01234567") == ""
+		if legacyOctal {
+			f = s.parseInt(start, text[1:], 8)
+		} else {
+			if s.offset < len(s.src) && s.src[s.offset] == '.' {
+				s.offset++
+				digits(10)
+			}
+			if s.offset < len(s.src) && s.src[s.offset]|0x20 == 'e' {
+				s.offset++
+				if s.offset < len(s.src) && (s.src[s.offset] == '+' || s.src[s.offset] == '-') {
+					s.offset++
+				}
+				if s.offset >= len(s.src) || !isDecimalDigit(rune(s.src[s.offset])) {
+					s.errorf(start, "invalid number")
+				}
+				digits(10)
+			}
+			text = strings.ReplaceAll(s.src[start:s.offset], "_", "")
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil && v == 0 {
+				s.errorf(start, "invalid number %s", s.src[start:s.offset])
+			}
+			f = v
+		}
+	}
+
+	if s.offset < len(s.src) {
+		r, _ := s.peekRune(s.offset)
+		if r == 'n' {
+			s.errorf(start, "BigInt literals are not supported")
+		}
+		if isIdentifierStart(r) || isDecimalDigit(r) {
+			s.errorf(start, "identifier starts immediately after number")
+		}
+	}
+	return f
+}
+
+func (s *scanner) parseInt(start int, text string, base int) float64 {
+	i, ok := new(big.Int).SetString(text, base)
+	if !ok {
+		s.errorf(start, "invalid number %s", s.src[start:s.offset])
+	}
+	f, _ := new(big.Float).SetInt(i).Float64()
+	return f
+}
+
+func digitValue(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c|0x20 && c|0x20 <= 'f':
+		return int(c|0x20-'a') + 10
+	}
+	return 16
+}
+
+func (s *scanner) scanString(quote byte) string {
+	start := s.offset
+	s.offset++
+	var b strings.Builder
+	for {
+		if s.offset >= len(s.src) {
+			s.errorf(start, "unterminated string literal")
+		}
+		r, n := s.peekRune(s.offset)
+		switch {
+		case r == rune(quote):
+			s.offset++
+			return b.String()
+		case r == '\\':
+			s.scanEscape(&b, false)
+		case r == '\n' || r == '\r':
+			s.errorf(start, "unterminated string literal")
+		default:
+			b.WriteString(s.src[s.offset : s.offset+n])
+			s.offset += n
+		}
+	}
+}
+
+// scanEscape scans an escape sequence in a string or template literal and
+// writes its value to b. It reports false for escapes that are invalid in
+// templates.
+func (s *scanner) scanEscape(b *strings.Builder, template bool) bool {
+	start := s.offset
+	s.offset++
+	r, n := s.peekRune(s.offset)
+	if r < 0 {
+		s.errorf(start, "unterminated escape sequence")
+	}
+	s.offset += n
+	switch r {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'v':
+		b.WriteByte('\v')
+	case '\r', '\n', '\u2028', '\u2029':
+		// line continuation
+		s.offset -= n
+		s.newline(r, n)
+	case 'x':
+		if s.offset+2 > len(s.src) || digitValue(s.src[s.offset]) > 15 || digitValue(s.src[s.offset+1]) > 15 {
+			if template {
+				return false
+			}
+			s.errorf(start, "invalid hexadecimal escape sequence")
+		}
+		b.WriteRune(rune(digitValue(s.src[s.offset])<<4 | digitValue(s.src[s.offset+1])))
+		s.offset += 2
+	case 'u':
+		r := s.scanUnicodeEscape(start)
+		if utf16.IsSurrogate(r) && strings.HasPrefix(s.src[s.offset:], "\\u") {
+			saved := s.offset
+			s.offset += 2
+			if r2 := s.scanUnicodeEscape(start); utf16.DecodeRune(r, r2) != unicode.ReplacementChar {
+				r = utf16.DecodeRune(r, r2)
+			} else {
+				s.offset = saved
+			}
+		}
+		writeWTF8(b, r)
+	default:
+		if '0' <= r && r <= '7' {
+			if r == '0' && (s.offset >= len(s.src) || !isDecimalDigit(rune(s.src[s.offset]))) {
+				b.WriteByte(0)
+				break
+			}
+			if template {
+				return false
+			}
+			// legacy octal escape
+			v := int(r - '0')
+			for i := 0; i < 2 && s.offset < len(s.src) && '0' <= s.src[s.offset] && s.src[s.offset] <= '7' && v*8+int(s.src[s.offset]-'0') <= 0377; i++ {
+				v = v*8 + int(s.src[s.offset]-'0')
+				s.offset++
+			}
+			b.WriteRune(rune(v))
+			break
+		}
+		b.WriteString(s.src[s.offset-n : s.offset])
+	}
+	return true
+}
+
+// scanTemplate scans a template part following ` or }. It returns the
+// cooked value, which is empty if the part contains an invalid escape, and
+// the raw value.
+func (s *scanner) scanTemplate() (cooked, raw string, tail bool) {
+	start := s.offset
+	var b, r strings.Builder
+	valid := true
+	for {
+		if s.offset >= len(s.src) {
+			s.errorf(start-1, "unterminated template literal")
+		}
+		c, n := s.peekRune(s.offset)
+		switch {
+		case c == '`':
+			s.offset++
+			tail = true
+		case c == '$' && strings.HasPrefix(s.src[s.offset:], "${"):
+			s.offset += 2
+		case c == '\\':
+			escStart := s.offset
+			if !s.scanEscape(&b, true) {
+				valid = false
+			}
+			r.WriteString(strings.ReplaceAll(s.src[escStart:s.offset], "\r\n", "\n"))
+			continue
+		case isLineTerminator(c):
+			if c == '\r' {
+				c = '\n'
+			}
+			b.WriteRune(c)
+			r.WriteRune(c)
+			s.newline(c, n)
+			continue
+		default:
+			b.WriteString(s.src[s.offset : s.offset+n])
+			r.WriteString(s.src[s.offset : s.offset+n])
+			s.offset += n
+			continue
+		}
+		break
+	}
+	if !valid {
+		return "", r.String(), tail
+	}
+	return b.String(), r.String(), tail
+}
+
+// scanPlaceholder scans %[name]s.
+func (s *scanner) scanPlaceholder() string {
+	start := s.offset
+	s.offset += 2
+	end := strings.IndexByte(s.src[s.offset:], ']')
+	if end <= 0 || !strings.HasPrefix(s.src[s.offset+end:], "]s") {
+		s.errorf(start, "malformed placeholder, want %%[name]s")
+	}
+	name := s.src[s.offset : s.offset+end]
+	for _, r := range name {
+		if !isIdentifierPart(r) || r == '\\' {
+			s.errorf(start, "invalid placeholder name %q", name)
+		}
+	}
+	s.offset += end + 2
+	return name
+}
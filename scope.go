@@ -0,0 +1,828 @@
+package goesprima
+
+import "reflect"
+
+// ScopeType is the kind of a Scope.
+type ScopeType string
+
+const (
+	ScopeGlobal   ScopeType = "global"
+	ScopeModule   ScopeType = "module"
+	ScopeFunction ScopeType = "function"
+	ScopeBlock    ScopeType = "block"
+	ScopeCatch    ScopeType = "catch"
+	ScopeClass    ScopeType = "class"
+	ScopeWith     ScopeType = "with"
+)
+
+// DefinitionType is the kind of declaration that defines a Variable.
+type DefinitionType string
+
+const (
+	DefinitionVar         DefinitionType = "var"
+	DefinitionLet         DefinitionType = "let"
+	DefinitionConst       DefinitionType = "const"
+	DefinitionParameter   DefinitionType = "param"
+	DefinitionFunction    DefinitionType = "function"
+	DefinitionClass       DefinitionType = "class"
+	DefinitionImport      DefinitionType = "import"
+	DefinitionCatchClause DefinitionType = "catch-clause"
+)
+
+// ScopeOptions controls the behaviour of Analyze.
+type ScopeOptions struct {
+	// Script analyzes programs as classic scripts, whose top-level var and
+	// function declarations are properties of the global scope. Programs are
+	// analyzed as modules by default.
+	Script bool
+}
+
+// ScopeManager holds the scopes of a tree built by Analyze, similar to
+// eslint-scope.
+type ScopeManager struct {
+	// Scopes lists every scope in the order it was entered. The first scope
+	// is the global scope.
+	Scopes []*Scope
+
+	nodeScopes map[JSElement]*Scope
+	variables  map[*Identifier]*Variable
+	references map[*Identifier]*Reference
+}
+
+// A Scope is a region of the program in which bindings are visible.
+type Scope struct {
+	Type ScopeType
+	// Block is the node that created the scope, eg. a *Program,
+	// *FunctionDeclaration or *BlockStatement.
+	Block JSElement
+	Upper *Scope
+	// VariableScope is the closest function, module or global scope, which
+	// var declarations are hoisted to.
+	VariableScope *Scope
+	ChildScopes   []*Scope
+	// Variables declared in the scope in order of declaration.
+	Variables []*Variable
+	// References made directly in the scope.
+	References []*Reference
+	// Through lists the references made in the scope or its children that
+	// are not resolved in it.
+	Through []*Reference
+
+	set map[string]*Variable
+}
+
+// A Variable is a binding declared in a scope.
+type Variable struct {
+	Name  string
+	Scope *Scope
+	// Identifiers declaring the variable.
+	Identifiers []*Identifier
+	Defs        []*Definition
+	References  []*Reference
+}
+
+// A Definition is a declaration of a Variable.
+type Definition struct {
+	Type DefinitionType
+	Name *Identifier
+	// Node is the declaring node: a *VariableDeclarator, the function for
+	// parameters and function names, the class, the import specifier or the
+	// *CatchClause.
+	Node JSElement
+	// Parent is the *VariableDeclaration or *ImportDeclaration containing
+	// Node, if any.
+	Parent JSElement
+}
+
+// ReferenceFlag tells whether a reference reads, writes or updates a
+// variable.
+type ReferenceFlag uint8
+
+const (
+	ReferenceRead ReferenceFlag = 1 << iota
+	ReferenceWrite
+	ReferenceReadWrite = ReferenceRead | ReferenceWrite
+)
+
+// A Reference is an identifier referring to a variable.
+type Reference struct {
+	Identifier *Identifier
+	// From is the scope the reference is made in.
+	From *Scope
+	// Resolved is the variable referred to, or nil for globals that aren't
+	// declared in the program.
+	Resolved *Variable
+	Flag     ReferenceFlag
+	// WriteExpr is the value written by the reference, if known.
+	WriteExpr Expression
+	// Init marks writes initializing a declaration.
+	Init bool
+	// TDZ marks references to let, const, class and parameter bindings
+	// that are evaluated before the declaration, in its temporal dead zone.
+	TDZ bool
+
+	order int
+	// export marks references of export specifiers, which export the
+	// binding rather than read it.
+	export bool
+}
+
+func (r *Reference) IsRead() bool      { return r.Flag&ReferenceRead != 0 }
+func (r *Reference) IsWrite() bool     { return r.Flag&ReferenceWrite != 0 }
+func (r *Reference) IsReadOnly() bool  { return r.Flag == ReferenceRead }
+func (r *Reference) IsWriteOnly() bool { return r.Flag == ReferenceWrite }
+func (r *Reference) IsReadWrite() bool { return r.Flag == ReferenceReadWrite }
+
+// Lookup returns the variable named name declared in s, or nil.
+func (s *Scope) Lookup(name string) *Variable {
+	return s.set[name]
+}
+
+// Resolve returns the variable named name visible in s, or nil.
+func (s *Scope) Resolve(name string) *Variable {
+	for ; s != nil; s = s.Upper {
+		if v := s.set[name]; v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// GlobalScope returns the global scope.
+func (m *ScopeManager) GlobalScope() *Scope {
+	return m.Scopes[0]
+}
+
+// Acquire returns the scope created by node, or nil if node doesn't create
+// one. Value-typed blocks such as function bodies are identified by a
+// pointer to the field, as in Walk.
+func (m *ScopeManager) Acquire(node JSElement) *Scope {
+	return m.nodeScopes[node]
+}
+
+// VariableOf returns the variable declared by id, or the variable id
+// resolves to if it is a reference.
+func (m *ScopeManager) VariableOf(id *Identifier) *Variable {
+	if v := m.variables[id]; v != nil {
+		return v
+	}
+	if r := m.references[id]; r != nil {
+		return r.Resolved
+	}
+	return nil
+}
+
+// ReferenceOf returns the reference made by id, or nil if id isn't a
+// reference.
+func (m *ScopeManager) ReferenceOf(id *Identifier) *Reference {
+	return m.references[id]
+}
+
+// DeclaredVariables returns the variables declared by node, eg. by a
+// *VariableDeclaration, *ImportDeclaration, function or class.
+func (m *ScopeManager) DeclaredVariables(node JSElement) (out []*Variable) {
+	for _, s := range m.Scopes {
+		for _, v := range s.Variables {
+			for _, d := range v.Defs {
+				if d.Node == node || d.Parent == node {
+					out = append(out, v)
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+// Analyze builds the scopes of the tree rooted at root. Declarations are
+// hoisted before references are resolved, so a reference resolves to the
+// closest binding of its name regardless of where in the scope it is
+// declared.
+//
+// Property names, labels and the property side of a StaticMemberExpression
+// are not references.
+func Analyze(root JSElement, opts ScopeOptions) *ScopeManager {
+	a := &analyzer{
+		opts: opts,
+		m: &ScopeManager{
+			nodeScopes: make(map[JSElement]*Scope),
+			variables:  make(map[*Identifier]*Variable),
+			references: make(map[*Identifier]*Reference),
+		},
+		declaredAt: make(map[*Variable]int),
+	}
+	a.push(ScopeGlobal, root)
+	switch root.(type) {
+	case *Program, *Generator:
+		if !opts.Script {
+			a.push(ScopeModule, root)
+		}
+	}
+	a.walk(root)
+	a.resolve()
+	return a.m
+}
+
+type analyzer struct {
+	opts  ScopeOptions
+	m     *ScopeManager
+	scope *Scope
+	refs  []*Reference
+	order int
+	// declaredAt records when let, const, class and parameter bindings
+	// are initialized, for TDZ detection.
+	declaredAt map[*Variable]int
+}
+
+func (a *analyzer) push(t ScopeType, block JSElement) *Scope {
+	s := &Scope{Type: t, Block: block, Upper: a.scope, set: make(map[string]*Variable)}
+	switch t {
+	case ScopeGlobal, ScopeModule, ScopeFunction:
+		s.VariableScope = s
+	default:
+		s.VariableScope = a.scope.VariableScope
+	}
+	if a.scope != nil {
+		a.scope.ChildScopes = append(a.scope.ChildScopes, s)
+	}
+	if _, ok := a.m.nodeScopes[block]; !ok {
+		a.m.nodeScopes[block] = s
+	}
+	a.m.Scopes = append(a.m.Scopes, s)
+	a.scope = s
+	return s
+}
+
+func (a *analyzer) pop() {
+	a.scope = a.scope.Upper
+}
+
+func (a *analyzer) walk(node JSElement) {
+	if node != nil && !isNilValue(reflect.ValueOf(node)) {
+		Walk(a, node)
+	}
+}
+
+func walkAll[T JSElement](a *analyzer, list []T) {
+	for _, n := range list {
+		a.walk(n)
+	}
+}
+
+func (a *analyzer) declare(s *Scope, id *Identifier, def *Definition) *Variable {
+	v := s.set[id.Name]
+	if v == nil {
+		v = &Variable{Name: id.Name, Scope: s}
+		s.set[id.Name] = v
+		s.Variables = append(s.Variables, v)
+	}
+	v.Identifiers = append(v.Identifiers, id)
+	v.Defs = append(v.Defs, def)
+	a.m.variables[id] = v
+	return v
+}
+
+func (a *analyzer) reference(id *Identifier, flag ReferenceFlag, write Expression, init bool) {
+	a.order++
+	r := &Reference{Identifier: id, From: a.scope, Flag: flag, WriteExpr: write, Init: init, order: a.order}
+	a.scope.References = append(a.scope.References, r)
+	a.m.references[id] = r
+	a.refs = append(a.refs, r)
+}
+
+// initialized marks the end of the declarations of v for TDZ detection.
+func (a *analyzer) initialized(vars ...*Variable) {
+	a.order++
+	for _, v := range vars {
+		if _, ok := a.declaredAt[v]; !ok && v != nil {
+			a.declaredAt[v] = a.order
+		}
+	}
+}
+
+// resolve resolves every reference to the closest variable of its name and
+// fills in the Through lists of the scopes it passes.
+func (a *analyzer) resolve() {
+	for _, r := range a.refs {
+		s := r.From
+		for ; s != nil; s = s.Upper {
+			if v := s.set[r.Identifier.Name]; v != nil {
+				r.Resolved = v
+				v.References = append(v.References, r)
+				if at, ok := a.declaredAt[v]; ok && !r.export && r.order < at && r.From.VariableScope == v.Scope.VariableScope {
+					r.TDZ = true
+				}
+				break
+			}
+			s.Through = append(s.Through, r)
+		}
+	}
+}
+
+// Visit implements Visitor. Nodes that declare bindings, create scopes or
+// contain names that aren't references are handled explicitly; Walk
+// descends into all others.
+func (a *analyzer) Visit(node JSElement) Visitor {
+	switch n := node.(type) {
+	case nil:
+		return nil
+
+	case *Identifier:
+		a.reference(n, ReferenceRead, nil, false)
+
+	case *Program:
+		a.hoist(n.Body)
+		walkAll(a, n.Body)
+
+	case *Generator:
+		a.hoist(n.Statements)
+		walkAll(a, n.Statements)
+
+	// Modules
+	case *ImportDeclaration:
+		// bindings are hoisted
+
+	case *ExportNamedDeclaration:
+		a.walk(n.Declaration)
+		if n.Source == nil {
+			// re-exported names aren't bound in the module
+			for i := range n.Specifiers {
+				a.walk(&n.Specifiers[i])
+			}
+		}
+
+	case *ExportSpecifier:
+		id := n.Local
+		if id == nil {
+			id = n.Exported
+		}
+		if id != nil {
+			a.reference(id, ReferenceRead, nil, false)
+			a.m.references[id].export = true
+		}
+
+	case *ExportAllDeclaration:
+		// nothing to reference
+
+	// Names that aren't references
+	case *LabeledStatement:
+		a.walk(n.Body)
+
+	case *BreakStatement, *ContinueStatement, *MetaProperty, *Directive:
+		// nothing to reference
+
+	case *StaticMemberExpression:
+		a.walk(n.Object)
+		a.propertyName(n.Property)
+
+	case *Property:
+		if n.Computed {
+			a.walk(n.Key)
+		}
+		if n.Value != nil {
+			a.walk(n.Value)
+		} else if id, ok := n.Key.(*Identifier); ok && !n.Computed {
+			// shorthand property without a value
+			a.reference(id, ReferenceRead, nil, false)
+		}
+
+	case *MethodDefinition:
+		if n.Computed {
+			a.walk(n.Key)
+		}
+		a.walk(&n.Value)
+
+	case *PropertyDefinition:
+		if n.Computed {
+			a.walk(n.Key)
+		}
+		a.walk(n.Value)
+
+	// Writes
+	case *AssignmentExpression:
+		if id, ok := n.Left.(*Identifier); ok {
+			flag := ReferenceWrite
+			if n.Operator != AssignmentOperatorEq {
+				flag = ReferenceReadWrite
+			}
+			a.walk(n.Right)
+			a.reference(id, flag, n.Right, false)
+		} else {
+			a.walk(n.Left)
+			a.walk(n.Right)
+		}
+
+	case *UnaryExpression:
+		id, ok := n.Argument.(*Identifier)
+		switch n.Operator {
+		case UnaryOperatorTypeIncrementPrefix, UnaryOperatorTypeIncrementPostfix,
+			UnaryOperatorTypeDecrementPrefix, UnaryOperatorTypeDecrementPostfix:
+			if ok {
+				a.reference(id, ReferenceReadWrite, nil, false)
+				return nil
+			}
+		}
+		a.walk(n.Argument)
+
+	case *UpdateExpression:
+		if id, ok := n.Argument.(*Identifier); ok {
+			a.reference(id, ReferenceReadWrite, nil, false)
+		} else {
+			a.walk(n.Argument)
+		}
+
+	// Declarations
+	case *VariableDeclaration:
+		a.variableDeclaration(n, false)
+
+	case *FunctionDeclaration:
+		// the name is hoisted by the enclosing scope
+		a.function(n, nil, n.Params, &n.Body, nil, true)
+
+	case *FunctionExpression:
+		a.function(n, n.ID, n.Params, &n.Body, nil, true)
+
+	case *ArrowFunctionExpression:
+		a.function(n, nil, n.Params, &n.Body, n.Expression, false)
+
+	case *ClassDeclaration:
+		a.class(n, n.ID, n.SuperClass, n.Body)
+		if n.ID != nil {
+			a.initialized(a.scope.Lookup(n.ID.Name))
+		}
+
+	case *ClassExpression:
+		a.class(n, n.ID, n.SuperClass, n.Body)
+
+	// Scopes
+	case *BlockStatement:
+		a.push(ScopeBlock, n)
+		a.hoist(n.Items)
+		walkAll(a, n.Items)
+		a.pop()
+
+	case *ForStatement:
+		if decl, ok := n.Init.(*VariableDeclaration); ok && decl.Kind != VariableDeclarationTypeVar {
+			a.push(ScopeBlock, n)
+			defer a.pop()
+		}
+		a.walk(n.Init)
+		a.walk(n.Test)
+		a.walk(n.Update)
+		a.walk(&n.Body)
+
+	case *ForInStatement:
+		a.forIn(n, n.Left, n.Right, &n.Body)
+
+	case *ForOfStatement:
+		a.forIn(n, n.Left, n.Right, &n.Body)
+
+	case *SwitchStatement:
+		a.walk(n.Discriminant)
+		a.push(ScopeBlock, n)
+		for i := range n.Cases {
+			a.hoist(n.Cases[i].Consequent.Items)
+		}
+		for i := range n.Cases {
+			c := &n.Cases[i]
+			a.walk(c.Test)
+			walkAll(a, c.Consequent.Items)
+		}
+		a.pop()
+
+	case *CatchClause:
+		a.push(ScopeCatch, n)
+		if n.BindingIdentifierOrPattern != nil {
+			a.pattern(n.BindingIdentifierOrPattern, func(id *Identifier) {
+				a.declare(a.scope, id, &Definition{Type: DefinitionCatchClause, Name: id, Node: n})
+			})
+		}
+		a.walk(&n.Body)
+		a.pop()
+
+	case *WithStatement:
+		a.walk(n.Object)
+		a.push(ScopeWith, n)
+		a.walk(n.Body)
+		a.pop()
+
+	default:
+		return a
+	}
+	return nil
+}
+
+// propertyName walks the property side of a static member expression. The
+// leading identifier is a property name; arguments of calls and computed
+// properties in the chain are references.
+func (a *analyzer) propertyName(e Expression) {
+	switch p := e.(type) {
+	case *Identifier:
+		// a name, not a reference
+	case *CallExpression:
+		a.propertyName(p.Callee)
+		walkAll(a, p.Arguments)
+	case *StaticMemberExpression:
+		a.propertyName(p.Object)
+		a.propertyName(p.Property)
+	case *ComputedMemberExpression:
+		a.propertyName(p.Object)
+		a.walk(p.Property)
+	default:
+		a.walk(e)
+	}
+}
+
+// hoist declares the functions, classes and let and const bindings of a
+// statement list in the current scope, and the var declarations nested in
+// it in the variable scope.
+func (a *analyzer) hoist(list interface{}) {
+	rv := reflect.ValueOf(list)
+	for i := 0; i < rv.Len(); i++ {
+		a.hoistStatement(rv.Index(i).Interface().(JSElement), true)
+	}
+}
+
+func (a *analyzer) hoistStatement(node JSElement, top bool) {
+	switch n := node.(type) {
+	case *ImportDeclaration:
+		for _, spec := range n.Specifiers {
+			switch s := spec.(type) {
+			case *ImportDefaultSpecifier:
+				a.declareImport(s.Local, s, n)
+			case *ImportNamespaceSpecifier:
+				a.declareImport(s.Local, s, n)
+			case *ImportSpecifier:
+				for i := range s.NamedImports {
+					named := &s.NamedImports[i]
+					local := named.Local
+					if local == nil {
+						local = named.Imported
+					}
+					a.declareImport(local, named, n)
+				}
+			}
+		}
+
+	case *ExportNamedDeclaration:
+		if n.Declaration != nil {
+			a.hoistStatement(n.Declaration, top)
+		}
+	case *ExportDefaultDeclaration:
+		switch d := n.Declaration.(type) {
+		case *FunctionDeclaration, *ClassDeclaration:
+			a.hoistStatement(d, top)
+		}
+	case *VariableDeclaration:
+		if n.Kind != VariableDeclarationTypeVar && !top {
+			return
+		}
+		s := a.scope
+		if n.Kind == VariableDeclarationTypeVar {
+			s = a.scope.VariableScope
+		}
+		for i := range n.Declarations {
+			d := &n.Declarations[i]
+			a.pattern(d.ID, func(id *Identifier) {
+				a.declare(s, id, &Definition{Type: DefinitionType(n.Kind), Name: id, Node: d, Parent: n})
+			})
+		}
+	case *FunctionDeclaration:
+		if top && n.ID != nil {
+			a.declare(a.scope, n.ID, &Definition{Type: DefinitionFunction, Name: n.ID, Node: n})
+		}
+	case *ClassDeclaration:
+		if top && n.ID != nil {
+			a.declare(a.scope, n.ID, &Definition{Type: DefinitionClass, Name: n.ID, Node: n})
+		}
+
+	// var declarations nested in statements
+	case *IfStatement:
+		a.hoistStatement(n.Consequent, false)
+		if n.Alternate != nil {
+			a.hoistStatement(n.Alternate, false)
+		}
+	case *ForStatement:
+		if n.Init != nil {
+			a.hoistStatement(n.Init, false)
+		}
+		a.hoistStatement(&n.Body, false)
+	case *ForInStatement:
+		a.hoistStatement(n.Left, false)
+		a.hoistStatement(&n.Body, false)
+	case *ForOfStatement:
+		a.hoistStatement(n.Left, false)
+		a.hoistStatement(&n.Body, false)
+	case *WhileStatement:
+		a.hoistStatement(n.Body, false)
+	case *DoWhileStatement:
+		a.hoistStatement(&n.Body, false)
+	case *WithStatement:
+		a.hoistStatement(n.Body, false)
+	case *LabeledStatement:
+		a.hoistStatement(n.Body, top)
+	case *BlockStatement:
+		for _, item := range n.Items {
+			a.hoistStatement(item, false)
+		}
+	case *SwitchStatement:
+		for i := range n.Cases {
+			for _, item := range n.Cases[i].Consequent.Items {
+				a.hoistStatement(item, false)
+			}
+		}
+	case *TryStatement:
+		a.hoistStatement(&n.Block, false)
+		if n.HasHandler() {
+			a.hoistStatement(&n.Handler.Body, false)
+		}
+		if n.Finalizer != nil {
+			a.hoistStatement(n.Finalizer, false)
+		}
+	}
+}
+
+func (a *analyzer) declareImport(id *Identifier, spec, decl JSElement) {
+	if id == nil {
+		return
+	}
+	a.declare(a.scope, id, &Definition{Type: DefinitionImport, Name: id, Node: spec, Parent: decl})
+}
+
+// pattern calls declare for every identifier bound by a binding pattern and
+// walks default values and computed keys.
+func (a *analyzer) pattern(node JSElement, declare func(*Identifier)) {
+	switch p := node.(type) {
+	case *Identifier:
+		declare(p)
+	case *ArrayPattern:
+		for _, e := range p.Elements {
+			if e != nil {
+				a.pattern(e, declare)
+			}
+		}
+	case *ObjectPattern:
+		for _, prop := range p.Properties {
+			a.pattern(prop, declare)
+		}
+	case *PropertyPattern:
+		if p.Computed {
+			a.walk(p.Key)
+		}
+		if p.Value != nil {
+			a.pattern(p.Value, declare)
+		} else if id, ok := p.Key.(*Identifier); ok {
+			declare(id)
+		}
+	case *AssignmentPattern:
+		a.pattern(p.Left, declare)
+		a.walk(p.Right)
+	case *RestElement:
+		a.pattern(p.Argument, declare)
+	}
+}
+
+// patternIdentifiers returns the identifiers bound by a pattern without
+// walking default values.
+func patternIdentifiers(node JSElement) (out []*Identifier) {
+	switch p := node.(type) {
+	case *Identifier:
+		out = append(out, p)
+	case *ArrayPattern:
+		for _, e := range p.Elements {
+			if e != nil {
+				out = append(out, patternIdentifiers(e)...)
+			}
+		}
+	case *ObjectPattern:
+		for _, prop := range p.Properties {
+			out = append(out, patternIdentifiers(prop)...)
+		}
+	case *PropertyPattern:
+		if p.Value != nil {
+			out = patternIdentifiers(p.Value)
+		} else if id, ok := p.Key.(*Identifier); ok {
+			out = append(out, id)
+		}
+	case *AssignmentPattern:
+		out = patternIdentifiers(p.Left)
+	case *RestElement:
+		out = patternIdentifiers(p.Argument)
+	}
+	return
+}
+
+// variableDeclaration walks the initializers of a declaration whose
+// bindings have been hoisted. Bindings with an initializer, or of a for-in
+// or for-of loop, are written.
+func (a *analyzer) variableDeclaration(n *VariableDeclaration, loop bool) {
+	s := a.scope
+	if n.Kind == VariableDeclarationTypeVar {
+		s = a.scope.VariableScope
+	}
+	for i := range n.Declarations {
+		d := &n.Declarations[i]
+		if n.Kind != VariableDeclarationTypeVar && s.Lookup(firstName(d.ID)) == nil {
+			// not hoisted, eg. in the head of a for statement
+			a.pattern(d.ID, func(id *Identifier) {
+				a.declare(s, id, &Definition{Type: DefinitionType(n.Kind), Name: id, Node: d, Parent: n})
+			})
+		} else {
+			a.patternDefaults(d.ID)
+		}
+		a.walk(d.Init)
+		ids := patternIdentifiers(d.ID)
+		if n.Kind != VariableDeclarationTypeVar {
+			var vars []*Variable
+			for _, id := range ids {
+				vars = append(vars, s.Lookup(id.Name))
+			}
+			a.initialized(vars...)
+		}
+		if d.Init != nil || loop {
+			for _, id := range ids {
+				a.reference(id, ReferenceWrite, d.Init, true)
+			}
+		}
+	}
+}
+
+func firstName(node JSElement) string {
+	if ids := patternIdentifiers(node); len(ids) > 0 {
+		return ids[0].Name
+	}
+	return ""
+}
+
+// patternDefaults walks the default values and computed keys of a pattern
+// whose identifiers are already declared.
+func (a *analyzer) patternDefaults(node JSElement) {
+	a.pattern(node, func(*Identifier) {})
+}
+
+func (a *analyzer) forIn(n JSElement, left ForInit, right Expression, body *BlockStatement) {
+	decl, isDecl := left.(*VariableDeclaration)
+	if isDecl && decl.Kind != VariableDeclarationTypeVar {
+		a.push(ScopeBlock, n)
+		defer a.pop()
+	}
+	a.walk(right)
+	switch l := left.(type) {
+	case *VariableDeclaration:
+		a.variableDeclaration(l, true)
+	case *Identifier:
+		a.reference(l, ReferenceWrite, nil, false)
+	default:
+		a.walk(left)
+	}
+	a.walk(body)
+}
+
+// function declares the parameters of a function in a new function scope
+// and walks its body, which doesn't create a separate block scope.
+func (a *analyzer) function(n JSElement, name *Identifier, params []FunctionParameter, body *BlockStatement, expr Expression, hasArguments bool) {
+	a.push(ScopeFunction, n)
+	defer a.pop()
+	if name != nil {
+		a.declare(a.scope, name, &Definition{Type: DefinitionFunction, Name: name, Node: n})
+	}
+	if hasArguments {
+		v := &Variable{Name: "arguments", Scope: a.scope}
+		a.scope.set[v.Name] = v
+		a.scope.Variables = append(a.scope.Variables, v)
+	}
+	for _, p := range params {
+		var vars []*Variable
+		a.pattern(p, func(id *Identifier) {
+			vars = append(vars, a.declare(a.scope, id, &Definition{Type: DefinitionParameter, Name: id, Node: n}))
+		})
+		a.initialized(vars...)
+	}
+	if expr != nil {
+		a.walk(expr)
+		return
+	}
+	a.m.nodeScopes[body] = a.scope
+	a.hoist(body.Items)
+	walkAll(a, body.Items)
+}
+
+// class walks a class in a new class scope, where its name is bound to the
+// class itself. Declarations also bind the name in the enclosing scope,
+// which VariableOf reports for it.
+func (a *analyzer) class(n JSElement, name *Identifier, super Expression, body *ClassBody) {
+	a.walk(super)
+	a.push(ScopeClass, n)
+	defer a.pop()
+	if name != nil {
+		outer := a.m.variables[name]
+		v := a.declare(a.scope, name, &Definition{Type: DefinitionClass, Name: name, Node: n})
+		if outer != nil {
+			a.m.variables[name] = outer
+		}
+		a.initialized(v)
+	}
+	if body != nil {
+		walkAll(a, body.Properties)
+	}
+}
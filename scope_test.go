@@ -0,0 +1,198 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeScopes(t *testing.T) {
+	prog, err := Parse("test.js", `import def, { a as b } from "m";
+var v = 1;
+function f(p, { q = v } = {}) {
+  if (p) {
+    let x = q;
+    var h = x;
+  }
+  return arguments.length + h;
+}
+class C extends def {
+  m() {
+    return C;
+  }
+}
+try {
+  f();
+} catch (e) {
+  b(e);
+}
+for (let i = 0; i < 3; i++) {
+  g(i);
+}`)
+	assert.NoError(t, err)
+	m := Analyze(prog, ScopeOptions{})
+
+	var types []ScopeType
+	for _, s := range m.Scopes {
+		types = append(types, s.Type)
+	}
+	assert.Equal(t, []ScopeType{
+		ScopeGlobal, ScopeModule,
+		ScopeFunction, ScopeBlock, // f, if block
+		ScopeClass, ScopeFunction, // C, m
+		ScopeBlock, ScopeCatch, ScopeBlock, // try, catch, catch body
+		ScopeBlock, ScopeBlock, // for, for body
+	}, types)
+
+	module := m.Scopes[1]
+	assert.Equal(t, []string{"def", "b", "v", "f", "C"}, variableNames(module))
+	assert.Equal(t, DefinitionImport, module.Lookup("b").Defs[0].Type)
+	assert.Equal(t, DefinitionVar, module.Lookup("v").Defs[0].Type)
+	assert.Len(t, module.Lookup("v").References, 2)
+
+	// var is hoisted out of the block, let isn't
+	fn := m.Acquire(prog.Body[2])
+	assert.Equal(t, []string{"arguments", "p", "q", "h"}, variableNames(fn))
+	assert.Equal(t, DefinitionParameter, fn.Lookup("q").Defs[0].Type)
+	assert.Equal(t, []string{"x"}, variableNames(fn.ChildScopes[0]))
+	assert.Len(t, fn.Lookup("arguments").References, 1)
+	assert.Same(t, fn, fn.ChildScopes[0].VariableScope)
+
+	// the catch parameter and the let of the for statement
+	catch := m.Scopes[7]
+	assert.Equal(t, DefinitionCatchClause, catch.Lookup("e").Defs[0].Type)
+	assert.Len(t, catch.Lookup("e").References, 1)
+	loop := m.Scopes[9]
+	assert.Equal(t, DefinitionLet, loop.Lookup("i").Defs[0].Type)
+	assert.Len(t, loop.Lookup("i").References, 4)
+
+	// g is an undeclared global, length a property name
+	var through []string
+	for _, r := range m.GlobalScope().Through {
+		through = append(through, r.Identifier.Name)
+	}
+	assert.Equal(t, []string{"g"}, through)
+}
+
+func TestAnalyzeReferences(t *testing.T) {
+	prog, err := Parse("test.js", `let a = 1;
+a += 2;
+a++;
+b = a;
+({ a, k: a.x });
+export { a as c };`)
+	assert.NoError(t, err)
+	m := Analyze(prog, ScopeOptions{})
+	a := m.Scopes[1].Lookup("a")
+
+	var flags []ReferenceFlag
+	for _, r := range a.References {
+		flags = append(flags, r.Flag)
+	}
+	assert.Equal(t, []ReferenceFlag{
+		ReferenceWrite, ReferenceReadWrite, ReferenceReadWrite,
+		ReferenceRead, ReferenceRead, ReferenceRead, ReferenceRead,
+	}, flags)
+	assert.True(t, a.References[0].Init)
+	assert.Equal(t, "1.000000", a.References[0].WriteExpr.String())
+
+	id := a.Identifiers[0]
+	assert.Same(t, a, m.VariableOf(id))
+	assert.Nil(t, m.ReferenceOf(&Identifier{Name: "a"}))
+	assert.Equal(t, []*Variable{a}, m.DeclaredVariables(prog.Body[0]))
+
+	// b is an implicit global write
+	b := m.GlobalScope().Through[0]
+	assert.Equal(t, "b", b.Identifier.Name)
+	assert.True(t, b.IsWriteOnly())
+	assert.Nil(t, b.Resolved)
+}
+
+func TestAnalyzeTDZ(t *testing.T) {
+	prog, err := Parse("test.js", `x;
+function f() {
+  return x;
+}
+let x = x;
+var y = z;
+const z = 1;
+y;`)
+	assert.NoError(t, err)
+	m := Analyze(prog, ScopeOptions{Script: true})
+
+	x := m.GlobalScope().Lookup("x")
+	var tdz []bool
+	for _, r := range x.References {
+		tdz = append(tdz, r.TDZ)
+	}
+	// the closure may run after x is initialized
+	assert.Equal(t, []bool{true, false, true, false}, tdz)
+	assert.True(t, m.GlobalScope().Lookup("z").References[0].TDZ)
+	assert.False(t, m.GlobalScope().Lookup("y").References[1].TDZ)
+}
+
+func TestAnalyzeTDZExportsAndParams(t *testing.T) {
+	prog, err := Parse("test.js", `export { c };
+const c = 1;
+function f(a = b, b = a) {}`)
+	assert.NoError(t, err)
+	m := Analyze(prog, ScopeOptions{})
+
+	// exports are live bindings
+	assert.False(t, m.Scopes[1].Lookup("c").References[0].TDZ)
+	fn := m.Acquire(prog.Body[2])
+	assert.True(t, fn.Lookup("b").References[0].TDZ)
+	assert.False(t, fn.Lookup("a").References[0].TDZ)
+}
+
+func TestAnalyzeClassBinding(t *testing.T) {
+	prog, err := Parse("test.js", `new A();
+class A {
+  static x = new A();
+  static s = A;
+}`)
+	assert.NoError(t, err)
+	m := Analyze(prog, ScopeOptions{Script: true})
+
+	// references in the class body are to its inner binding
+	outer := m.GlobalScope().Lookup("A")
+	assert.Same(t, outer, m.VariableOf(prog.Body[1].(*ClassDeclaration).ID))
+	if assert.Len(t, outer.References, 1) {
+		assert.True(t, outer.References[0].TDZ)
+	}
+	inner := m.Acquire(prog.Body[1]).Lookup("A")
+	if assert.Len(t, inner.References, 2) {
+		assert.False(t, inner.References[0].TDZ)
+		assert.False(t, inner.References[1].TDZ)
+	}
+}
+
+func TestAnalyzeMemberQuirk(t *testing.T) {
+	// legacy trees put calls on the property side of member expressions
+	prog := &Program{
+		Body: []StatementListItem{
+			&ExpressionStatement{
+				Expression: &StaticMemberExpression{
+					Object: &Identifier{Name: "Amplify"},
+					Property: &CallExpression{
+						Callee:    &Identifier{Name: "configure"},
+						Arguments: []ArgumentListElement{&Identifier{Name: "config"}},
+					},
+				},
+			},
+		},
+	}
+	m := Analyze(prog, ScopeOptions{})
+	var names []string
+	for _, r := range m.GlobalScope().Through {
+		names = append(names, r.Identifier.Name)
+	}
+	assert.Equal(t, []string{"Amplify", "config"}, names)
+}
+
+func variableNames(s *Scope) (names []string) {
+	for _, v := range s.Variables {
+		names = append(names, v.Name)
+	}
+	return
+}
@@ -0,0 +1,152 @@
+package goesprima
+
+import (
+	"encoding/base64"
+	"io"
+
+	"github.com/MichaelCombs28/goesprima/sourcemap"
+)
+
+// A SourceMap maps positions of printed output back to the source
+// locations of the printed nodes. It is encoded as a Source Map revision 3.
+type SourceMap struct {
+	// File is the name of the generated file.
+	File string
+	// SourceRoot is prepended to the source names by consumers.
+	SourceRoot string
+	// Sources are the SourceLocation.Source names of the printed nodes.
+	Sources []string
+	// SourcesContent optionally holds the content of the sources, in the
+	// order of Sources.
+	SourcesContent []string
+	// Names are the names of the printed identifiers.
+	Names    []string
+	Mappings []Mapping
+}
+
+// A Mapping maps a position of the output to a position of a source. Lines
+// and columns start at 0; columns are counted in UTF-16 code units.
+type Mapping = sourcemap.Mapping
+
+// FprintSourceMap is like Fprint and also returns the source map of the
+// output. Nodes without a source location are not mapped.
+func (c *PrinterConfig) FprintSourceMap(w io.Writer, node JSElement) (*SourceMap, error) {
+	m := new(SourceMap)
+	err := c.fprint(w, node, m)
+	return m, err
+}
+
+// sourceMapper records the mappings of a printer.
+type sourceMapper struct {
+	*SourceMap
+	line, col int
+	sources   map[string]int
+	names     map[string]int
+	// pending is the location of the node whose first token is printed
+	// next.
+	pending *SourceLocation
+	name    string
+}
+
+func newSourceMapper(m *SourceMap) *sourceMapper {
+	return &sourceMapper{SourceMap: m, sources: make(map[string]int), names: make(map[string]int)}
+}
+
+// enter records the location of a node about to be printed.
+func (s *sourceMapper) enter(node JSElement) {
+	loc := LocationOf(node)
+	if loc == nil {
+		return
+	}
+	s.pending, s.name = loc, ""
+	if id, ok := node.(*Identifier); ok {
+		s.name = id.Name
+	}
+}
+
+// mark maps the current position to the pending location.
+func (s *sourceMapper) mark() {
+	if s.pending == nil {
+		return
+	}
+	loc := s.pending
+	s.pending = nil
+	src, ok := s.sources[loc.Source]
+	if !ok {
+		src = len(s.Sources)
+		s.sources[loc.Source] = src
+		s.Sources = append(s.Sources, loc.Source)
+	}
+	name := -1
+	if s.name != "" {
+		if name, ok = s.names[s.name]; !ok {
+			name = len(s.Names)
+			s.names[s.name] = name
+			s.Names = append(s.Names, s.name)
+		}
+	}
+	m := Mapping{
+		GeneratedLine:   s.line,
+		GeneratedColumn: s.col,
+		Source:          src,
+		OriginalLine:    loc.Start.Line - 1,
+		OriginalColumn:  loc.Start.Column,
+		Name:            name,
+	}
+	if n := len(s.Mappings); n > 0 && s.Mappings[n-1].GeneratedLine == m.GeneratedLine && s.Mappings[n-1].GeneratedColumn == m.GeneratedColumn {
+		s.Mappings[n-1] = m
+		return
+	}
+	s.Mappings = append(s.Mappings, m)
+}
+
+// advance moves the current position past s.
+func (s *sourceMapper) advance(text string) {
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			s.line++
+			s.col = 0
+		case r >= 0x10000:
+			s.col += 2
+		default:
+			s.col++
+		}
+	}
+}
+
+// Map returns m as a sourcemap.Map.
+func (m *SourceMap) Map() *sourcemap.Map {
+	return &sourcemap.Map{
+		File:           m.File,
+		SourceRoot:     m.SourceRoot,
+		Sources:        m.Sources,
+		SourcesContent: m.SourcesContent,
+		Names:          m.Names,
+		Mappings:       append([]Mapping(nil), m.Mappings...),
+	}
+}
+
+// MarshalJSON encodes m as a Source Map revision 3.
+func (m *SourceMap) MarshalJSON() ([]byte, error) {
+	return m.Map().MarshalJSON()
+}
+
+// DataURL returns m as a base64 data URL.
+func (m *SourceMap) DataURL() (string, error) {
+	b, err := m.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	return "data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// InlineComment returns the //# sourceMappingURL= comment embedding m,
+// to be appended to the output on its own line.
+func (m *SourceMap) InlineComment() (string, error) {
+	url, err := m.DataURL()
+	if err != nil {
+		return "", err
+	}
+	return "//# sourceMappingURL=" + url, nil
+}
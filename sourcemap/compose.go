@@ -0,0 +1,40 @@
+package sourcemap
+
+// Compose returns the map of a file C generated from a file B, whose map
+// to C is bc, back to the sources of B, whose map is ab. The sources of bc
+// are taken to be B. Positions of C mapped to parts of B without an
+// original position are left without one. Names are taken from ab if it
+// has them and from bc otherwise.
+func Compose(ab, bc *Map) *Map {
+	m := &Map{
+		File:           bc.File,
+		SourceRoot:     ab.SourceRoot,
+		Sources:        append([]string(nil), ab.Sources...),
+		SourcesContent: append([]string(nil), ab.SourcesContent...),
+		Names:          append([]string(nil), ab.Names...),
+	}
+	b := newBuilder(m)
+	for _, mp := range bc.Mappings {
+		out := Mapping{GeneratedLine: mp.GeneratedLine, GeneratedColumn: mp.GeneratedColumn, Source: -1, Name: -1}
+		if mp.Source >= 0 {
+			if orig := ab.find(mp.OriginalLine, mp.OriginalColumn); orig != nil && orig.Source >= 0 {
+				out.Source, out.OriginalLine, out.OriginalColumn, out.Name = orig.Source, orig.OriginalLine, orig.OriginalColumn, orig.Name
+				if out.Name < 0 && mp.Name >= 0 {
+					out.Name = b.name(bc.Names[mp.Name])
+				}
+			}
+		}
+		if out.Source < 0 && unmapped(m.Mappings, out.GeneratedLine) {
+			// a line starts unmapped
+			continue
+		}
+		m.Mappings = append(m.Mappings, out)
+	}
+	return m
+}
+
+// unmapped reports whether the end of line is unmapped by mappings.
+func unmapped(mappings []Mapping, line int) bool {
+	n := len(mappings)
+	return n == 0 || mappings[n-1].GeneratedLine != line || mappings[n-1].Source < 0
+}
@@ -0,0 +1,60 @@
+package sourcemap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompose(t *testing.T) {
+	// a.ts -> b.js
+	ab := &Map{
+		File:           "b.js",
+		Sources:        []string{"a.ts"},
+		SourcesContent: []string{"a"},
+		Names:          []string{"foo"},
+		Mappings: []Mapping{
+			{GeneratedLine: 0, GeneratedColumn: 0, Source: 0, OriginalLine: 2, OriginalColumn: 4, Name: 0},
+			{GeneratedLine: 0, GeneratedColumn: 10, Source: -1, Name: -1},
+			{GeneratedLine: 1, GeneratedColumn: 2, Source: 0, OriginalLine: 3, OriginalColumn: 0, Name: -1},
+		},
+	}
+	// b.js -> c.min.js
+	bc := &Map{
+		File:    "c.min.js",
+		Sources: []string{"b.js"},
+		Names:   []string{"f", "x"},
+		Mappings: []Mapping{
+			{GeneratedLine: 0, GeneratedColumn: 0, Source: 0, OriginalLine: 0, OriginalColumn: 0, Name: 0},
+			// unmapped in b.js
+			{GeneratedLine: 0, GeneratedColumn: 3, Source: 0, OriginalLine: 0, OriginalColumn: 12, Name: -1},
+			{GeneratedLine: 0, GeneratedColumn: 5, Source: 0, OriginalLine: 1, OriginalColumn: 6, Name: 1},
+			{GeneratedLine: 0, GeneratedColumn: 7, Source: -1, Name: -1},
+			{GeneratedLine: 1, GeneratedColumn: 0, Source: 0, OriginalLine: 5, OriginalColumn: 0, Name: -1},
+			{GeneratedLine: 1, GeneratedColumn: 4, Source: 0, OriginalLine: 1, OriginalColumn: 2, Name: -1},
+		},
+	}
+
+	ac := Compose(ab, bc)
+	assert.Equal(t, "c.min.js", ac.File)
+	assert.Equal(t, []string{"a.ts"}, ac.Sources)
+	assert.Equal(t, []string{"a"}, ac.SourcesContent)
+	assert.Equal(t, []string{"foo", "x"}, ac.Names)
+	assert.Equal(t, []Mapping{
+		// names of ab take precedence
+		{GeneratedLine: 0, GeneratedColumn: 0, Source: 0, OriginalLine: 2, OriginalColumn: 4, Name: 0},
+		{GeneratedLine: 0, GeneratedColumn: 3, Source: -1, Name: -1},
+		{GeneratedLine: 0, GeneratedColumn: 5, Source: 0, OriginalLine: 3, OriginalColumn: 0, Name: 1},
+		{GeneratedLine: 0, GeneratedColumn: 7, Source: -1, Name: -1},
+		{GeneratedLine: 1, GeneratedColumn: 4, Source: 0, OriginalLine: 3, OriginalColumn: 0, Name: -1},
+	}, ac.Mappings)
+
+	pos, ok := ac.Lookup(0, 6)
+	assert.True(t, ok)
+	assert.Equal(t, Position{"a.ts", 3, 0, "x"}, pos)
+	_, ok = ac.Lookup(1, 2)
+	assert.False(t, ok)
+
+	// the inputs are unchanged
+	assert.Equal(t, []string{"foo"}, ab.Names)
+}
@@ -0,0 +1,272 @@
+// Package sourcemap reads, queries and composes Source Maps revision 3.
+//
+// Lines and columns are 0-based, and columns are counted in UTF-16 code
+// units, as in the encoded maps.
+package sourcemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Map maps positions of a generated file to positions of its sources.
+type Map struct {
+	// File is the name of the generated file.
+	File string
+	// SourceRoot is prepended to the names of Sources by Lookup.
+	SourceRoot string
+	Sources    []string
+	// SourcesContent holds the content of Sources, "" when it isn't known.
+	// It is either empty or as long as Sources.
+	SourcesContent []string
+	Names          []string
+	// Mappings are sorted by generated position.
+	Mappings []Mapping
+}
+
+// A Mapping maps a generated position to an original one.
+type Mapping struct {
+	GeneratedLine   int
+	GeneratedColumn int
+	// Source is an index into Map.Sources, or -1 if the generated position
+	// has no original.
+	Source         int
+	OriginalLine   int
+	OriginalColumn int
+	// Name is an index into Map.Names, or -1.
+	Name int
+}
+
+// A Position is an original position found by Lookup.
+type Position struct {
+	// Source is the name of the source, prefixed with the SourceRoot.
+	Source string
+	Line   int
+	Column int
+	// Name is the original name of the identifier at the position, if
+	// known.
+	Name string
+}
+
+type mapJSON struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	SourceRoot     string    `json:"sourceRoot,omitempty"`
+	Sources        []*string `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+	Sections       []section `json:"sections,omitempty"`
+}
+
+type section struct {
+	Offset struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"offset"`
+	Map json.RawMessage `json:"map"`
+	URL string          `json:"url"`
+}
+
+// Parse parses a source map. Index maps are flattened into a single map,
+// whose sources are prefixed with the SourceRoot of their section.
+func Parse(data []byte) (*Map, error) {
+	var raw mapJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("sourcemap: %v", err)
+	}
+	if raw.Version != 3 {
+		return nil, fmt.Errorf("sourcemap: unsupported version %d", raw.Version)
+	}
+	if raw.Sections != nil {
+		return parseIndex(&raw)
+	}
+
+	m := &Map{File: raw.File, SourceRoot: raw.SourceRoot, Names: raw.Names}
+	for _, s := range raw.Sources {
+		m.Sources = append(m.Sources, deref(s))
+	}
+	if len(raw.SourcesContent) > 0 {
+		m.SourcesContent = make([]string, len(m.Sources))
+		for i, c := range raw.SourcesContent {
+			if i < len(m.SourcesContent) {
+				m.SourcesContent[i] = deref(c)
+			}
+		}
+	}
+	mappings, err := decodeMappings(raw.Mappings, len(m.Sources), len(m.Names))
+	if err != nil {
+		return nil, err
+	}
+	m.Mappings = mappings
+	return m, nil
+}
+
+// parseIndex flattens the sections of an index map.
+func parseIndex(raw *mapJSON) (*Map, error) {
+	m := &Map{File: raw.File}
+	b := newBuilder(m)
+	for i, sec := range raw.Sections {
+		if sec.URL != "" {
+			return nil, fmt.Errorf("sourcemap: section %d refers to %s, only embedded maps are supported", i, sec.URL)
+		}
+		if i > 0 {
+			prev := raw.Sections[i-1].Offset
+			if sec.Offset.Line < prev.Line || sec.Offset.Line == prev.Line && sec.Offset.Column < prev.Column {
+				return nil, fmt.Errorf("sourcemap: section %d is out of order", i)
+			}
+		}
+		sub, err := Parse(sec.Map)
+		if err != nil {
+			return nil, fmt.Errorf("sourcemap: section %d: %s", i, strings.TrimPrefix(err.Error(), "sourcemap: "))
+		}
+		for _, mp := range sub.Mappings {
+			if mp.GeneratedLine == 0 {
+				mp.GeneratedColumn += sec.Offset.Column
+			}
+			mp.GeneratedLine += sec.Offset.Line
+			if mp.Source >= 0 {
+				content := ""
+				if len(sub.SourcesContent) > 0 {
+					content = sub.SourcesContent[mp.Source]
+				}
+				mp.Source = b.source(join(sub.SourceRoot, sub.Sources[mp.Source]), content)
+			}
+			if mp.Name >= 0 {
+				mp.Name = b.name(sub.Names[mp.Name])
+			}
+			m.Mappings = append(m.Mappings, mp)
+		}
+	}
+	sortMappings(m.Mappings)
+	return m, nil
+}
+
+// Lookup returns the original position of the generated position, that of
+// the closest mapping at or before it on the same line.
+func (m *Map) Lookup(line, column int) (Position, bool) {
+	mp := m.find(line, column)
+	if mp == nil || mp.Source < 0 {
+		return Position{}, false
+	}
+	pos := Position{
+		Source: join(m.SourceRoot, m.Sources[mp.Source]),
+		Line:   mp.OriginalLine,
+		Column: mp.OriginalColumn,
+	}
+	if mp.Name >= 0 {
+		pos.Name = m.Names[mp.Name]
+	}
+	return pos, true
+}
+
+// find returns the closest mapping at or before a generated position on
+// the same line, or nil.
+func (m *Map) find(line, column int) *Mapping {
+	i := sort.Search(len(m.Mappings), func(i int) bool {
+		mp := &m.Mappings[i]
+		return mp.GeneratedLine > line || mp.GeneratedLine == line && mp.GeneratedColumn > column
+	})
+	if i == 0 || m.Mappings[i-1].GeneratedLine != line {
+		return nil
+	}
+	return &m.Mappings[i-1]
+}
+
+// MarshalJSON encodes m as a source map.
+func (m *Map) MarshalJSON() ([]byte, error) {
+	out := mapJSON{
+		Version:    3,
+		File:       m.File,
+		SourceRoot: m.SourceRoot,
+		Sources:    []*string{},
+		Names:      m.Names,
+		Mappings:   encodeMappings(m.Mappings),
+	}
+	for i := range m.Sources {
+		out.Sources = append(out.Sources, &m.Sources[i])
+	}
+	if out.Names == nil {
+		out.Names = []string{}
+	}
+	for i := range m.SourcesContent {
+		if m.SourcesContent[i] != "" {
+			out.SourcesContent = make([]*string, len(m.SourcesContent))
+			break
+		}
+	}
+	for i := range out.SourcesContent {
+		if m.SourcesContent[i] != "" {
+			out.SourcesContent[i] = &m.SourcesContent[i]
+		}
+	}
+	return json.Marshal(out)
+}
+
+// builder adds sources and names to a map without duplicates.
+type builder struct {
+	m       *Map
+	sources map[string]int
+	names   map[string]int
+}
+
+func newBuilder(m *Map) *builder {
+	b := &builder{m: m, sources: make(map[string]int), names: make(map[string]int)}
+	for i, s := range m.Sources {
+		b.sources[s] = i
+	}
+	for i, n := range m.Names {
+		b.names[n] = i
+	}
+	return b
+}
+
+func (b *builder) source(name, content string) int {
+	i, ok := b.sources[name]
+	if !ok {
+		i = len(b.m.Sources)
+		b.sources[name] = i
+		b.m.Sources = append(b.m.Sources, name)
+		b.m.SourcesContent = append(b.m.SourcesContent, content)
+	} else if b.m.SourcesContent[i] == "" {
+		b.m.SourcesContent[i] = content
+	}
+	return i
+}
+
+func (b *builder) name(name string) int {
+	i, ok := b.names[name]
+	if !ok {
+		i = len(b.m.Names)
+		b.names[name] = i
+		b.m.Names = append(b.m.Names, name)
+	}
+	return i
+}
+
+func sortMappings(mappings []Mapping) {
+	sort.SliceStable(mappings, func(i, j int) bool {
+		a, b := &mappings[i], &mappings[j]
+		return a.GeneratedLine < b.GeneratedLine || a.GeneratedLine == b.GeneratedLine && a.GeneratedColumn < b.GeneratedColumn
+	})
+}
+
+// join prefixes a source with a source root.
+func join(root, source string) string {
+	if root == "" || strings.Contains(source, "://") || strings.HasPrefix(source, "/") {
+		return source
+	}
+	if !strings.HasSuffix(root, "/") {
+		root += "/"
+	}
+	return root + source
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,141 @@
+package sourcemap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMap is the example map of the mozilla/source-map tests.
+const testMap = `{
+  "version": 3,
+  "file": "min.js",
+  "names": ["bar", "baz", "n"],
+  "sources": ["one.js", "two.js"],
+  "sourceRoot": "/the/root",
+  "mappings": "CAAC,IAAI,IAAM,SAAUA,GAClB,OAAOC,IAAID;CCDb,IAAI,IAAM,SAAUE,GAClB,OAAOA"
+}`
+
+func TestParseLookup(t *testing.T) {
+	m, err := Parse([]byte(testMap))
+	assert.NoError(t, err)
+	assert.Equal(t, "min.js", m.File)
+	assert.Equal(t, []string{"one.js", "two.js"}, m.Sources)
+	assert.Len(t, m.Mappings, 13)
+
+	for _, test := range []struct {
+		line, col int
+		want      Position
+	}{
+		{0, 1, Position{"/the/root/one.js", 0, 1, ""}},
+		{0, 5, Position{"/the/root/one.js", 0, 5, ""}},
+		{0, 9, Position{"/the/root/one.js", 0, 11, ""}},
+		{0, 18, Position{"/the/root/one.js", 0, 21, "bar"}},
+		{0, 21, Position{"/the/root/one.js", 1, 3, ""}},
+		{0, 28, Position{"/the/root/one.js", 1, 10, "baz"}},
+		// the closest mapping before the column
+		{0, 30, Position{"/the/root/one.js", 1, 10, "baz"}},
+		{1, 1, Position{"/the/root/two.js", 0, 1, ""}},
+		{1, 18, Position{"/the/root/two.js", 0, 21, "n"}},
+		{1, 28, Position{"/the/root/two.js", 1, 10, "n"}},
+	} {
+		pos, ok := m.Lookup(test.line, test.col)
+		assert.True(t, ok, "%d:%d", test.line, test.col)
+		assert.Equal(t, test.want, pos, "%d:%d", test.line, test.col)
+	}
+
+	_, ok := m.Lookup(0, 0)
+	assert.False(t, ok)
+	_, ok = m.Lookup(2, 0)
+	assert.False(t, ok)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	m, err := Parse([]byte(testMap))
+	assert.NoError(t, err)
+	b, err := m.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, testMap, string(b))
+
+	m.SourcesContent = []string{"", "two"}
+	b, err = m.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"sourcesContent":[null,"two"]`)
+	again, err := Parse(b)
+	assert.NoError(t, err)
+	assert.Equal(t, m, again)
+}
+
+func TestParseUnmapped(t *testing.T) {
+	m, err := Parse([]byte(`{"version":3,"sources":["a.js"],"names":[],"mappings":"AAAA,E;;GACA"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Mapping{
+		{GeneratedLine: 0, GeneratedColumn: 0, Source: 0, Name: -1},
+		{GeneratedLine: 0, GeneratedColumn: 2, Source: -1, Name: -1},
+		{GeneratedLine: 2, GeneratedColumn: 3, Source: 0, OriginalLine: 1, Name: -1},
+	}, m.Mappings)
+	_, ok := m.Lookup(0, 1)
+	assert.True(t, ok)
+	_, ok = m.Lookup(0, 2)
+	assert.False(t, ok)
+}
+
+func TestParseIndex(t *testing.T) {
+	m, err := Parse([]byte(`{
+	  "version": 3,
+	  "file": "app.js",
+	  "sections": [
+	    {"offset": {"line": 0, "column": 0}, "map": {"version": 3, "sources": ["a.js"], "names": ["a"], "mappings": "AAAAA;AACA"}},
+	    {"offset": {"line": 1, "column": 10}, "map": {"version": 3, "sourceRoot": "lib", "sources": ["b.js"], "sourcesContent": ["b"], "names": ["b", "a"], "mappings": "AAAAA,EAAEC;AACF"}}
+	  ]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "app.js", m.File)
+	assert.Equal(t, []string{"a.js", "lib/b.js"}, m.Sources)
+	assert.Equal(t, []string{"", "b"}, m.SourcesContent)
+	assert.Equal(t, []string{"a", "b"}, m.Names)
+
+	pos, ok := m.Lookup(1, 5)
+	assert.True(t, ok)
+	assert.Equal(t, Position{"a.js", 1, 0, ""}, pos)
+	// the offset column applies to the first line of a section only
+	pos, ok = m.Lookup(1, 12)
+	assert.True(t, ok)
+	assert.Equal(t, Position{"lib/b.js", 0, 2, "a"}, pos)
+	pos, ok = m.Lookup(2, 0)
+	assert.True(t, ok)
+	assert.Equal(t, Position{"lib/b.js", 1, 0, ""}, pos)
+}
+
+func TestParseErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		`{"version":2}`: "unsupported version 2",
+		`{"version":3,"sources":[],"names":[],"mappings":"AAAA"}`:                                                                              `segment "AAAA" at line 0 refers to source 0 of 0`,
+		`{"version":3,"sources":["a"],"names":[],"mappings":"AAAAA"}`:                                                                          `segment "AAAAA" at line 0 refers to name 0 of 0`,
+		`{"version":3,"sources":["a"],"names":[],"mappings":"AA"}`:                                                                             `segment "AA" at line 0 has 2 fields`,
+		`{"version":3,"sources":["a"],"names":[],"mappings":"AAAAAA"}`:                                                                         `segment "AAAAAA" at line 0 has more than 5 fields`,
+		`{"version":3,"sources":["a"],"names":[],"mappings":";A!AA"}`:                                                                          `segment "A!AA" at line 1: invalid base64 character '!'`,
+		`{"version":3,"sources":["a"],"names":[],"mappings":"g"}`:                                                                              `segment "g" at line 0: truncated VLQ`,
+		`{"version":3,"sections":[{"offset":{"line":0,"column":0},"url":"a.map"}]}`:                                                            "section 0 refers to a.map, only embedded maps are supported",
+		`{"version":3,"sections":[{"offset":{"line":1,"column":0},"map":{"version":3}},{"offset":{"line":0,"column":0},"map":{"version":3}}]}`: "section 1 is out of order",
+		`{"version":3,"sections":[{"offset":{"line":0,"column":0},"map":{"version":4}}]}`:                                                      "section 0: unsupported version 4",
+	} {
+		_, err := Parse([]byte(src))
+		if assert.Error(t, err, src) {
+			assert.Equal(t, "sourcemap: "+want, err.Error())
+		}
+	}
+}
+
+func TestVLQ(t *testing.T) {
+	for v, want := range map[int]string{0: "A", 1: "C", -1: "D", 15: "e", 16: "gB", -16: "hB", 1000: "w+B", 1 << 30: "ggggggC"} {
+		var sb strings.Builder
+		writeVLQ(&sb, v)
+		assert.Equal(t, want, sb.String(), v)
+		got, rest, err := readVLQ(want + "A")
+		assert.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.Equal(t, "A", rest)
+	}
+}
@@ -0,0 +1,147 @@
+package sourcemap
+
+import (
+	"fmt"
+	"strings"
+)
+
+const base64VLQ = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+var base64Index [256]int8
+
+func init() {
+	for i := range base64Index {
+		base64Index[i] = -1
+	}
+	for i := 0; i < len(base64VLQ); i++ {
+		base64Index[base64VLQ[i]] = int8(i)
+	}
+}
+
+// decodeMappings decodes the mappings field of a map with the given
+// numbers of sources and names.
+func decodeMappings(s string, sources, names int) ([]Mapping, error) {
+	var mappings []Mapping
+	var src, origLine, origCol, name int
+	sorted := true
+	for line, l := range strings.Split(s, ";") {
+		col := 0
+		for _, seg := range strings.Split(l, ",") {
+			if seg == "" {
+				continue
+			}
+			var fields [5]int
+			n := 0
+			for rest := seg; rest != ""; n++ {
+				if n == len(fields) {
+					return nil, fmt.Errorf("sourcemap: segment %q at line %d has more than 5 fields", seg, line)
+				}
+				v, r, err := readVLQ(rest)
+				if err != nil {
+					return nil, fmt.Errorf("sourcemap: segment %q at line %d: %v", seg, line, err)
+				}
+				fields[n], rest = v, r
+			}
+			if n != 1 && n != 4 && n != 5 {
+				return nil, fmt.Errorf("sourcemap: segment %q at line %d has %d fields", seg, line, n)
+			}
+			if fields[0] < 0 {
+				sorted = false
+			}
+			col += fields[0]
+			mp := Mapping{GeneratedLine: line, GeneratedColumn: col, Source: -1, Name: -1}
+			if n >= 4 {
+				src += fields[1]
+				origLine += fields[2]
+				origCol += fields[3]
+				if src < 0 || src >= sources {
+					return nil, fmt.Errorf("sourcemap: segment %q at line %d refers to source %d of %d", seg, line, src, sources)
+				}
+				mp.Source, mp.OriginalLine, mp.OriginalColumn = src, origLine, origCol
+			}
+			if n == 5 {
+				name += fields[4]
+				if name < 0 || name >= names {
+					return nil, fmt.Errorf("sourcemap: segment %q at line %d refers to name %d of %d", seg, line, name, names)
+				}
+				mp.Name = name
+			}
+			mappings = append(mappings, mp)
+		}
+	}
+	if !sorted {
+		sortMappings(mappings)
+	}
+	return mappings, nil
+}
+
+// readVLQ reads a base64 VLQ from the start of s.
+func readVLQ(s string) (int, string, error) {
+	v, shift := 0, 0
+	for i := 0; i < len(s); i++ {
+		digit := int(base64Index[s[i]])
+		if digit < 0 {
+			return 0, "", fmt.Errorf("invalid base64 character %q", s[i])
+		}
+		if shift > 31 {
+			return 0, "", fmt.Errorf("VLQ overflows")
+		}
+		v |= digit & 31 << shift
+		shift += 5
+		if digit&32 == 0 {
+			if v&1 != 0 {
+				return -(v >> 1), s[i+1:], nil
+			}
+			return v >> 1, s[i+1:], nil
+		}
+	}
+	return 0, "", fmt.Errorf("truncated VLQ")
+}
+
+// encodeMappings encodes sorted mappings.
+func encodeMappings(mappings []Mapping) string {
+	var sb strings.Builder
+	var line, col, src, origLine, origCol, name int
+	for i, mp := range mappings {
+		if mp.GeneratedLine != line || i == 0 {
+			for ; line < mp.GeneratedLine; line++ {
+				sb.WriteByte(';')
+			}
+			col = 0
+		} else {
+			sb.WriteByte(',')
+		}
+		writeVLQ(&sb, mp.GeneratedColumn-col)
+		col = mp.GeneratedColumn
+		if mp.Source < 0 {
+			continue
+		}
+		writeVLQ(&sb, mp.Source-src)
+		writeVLQ(&sb, mp.OriginalLine-origLine)
+		writeVLQ(&sb, mp.OriginalColumn-origCol)
+		src, origLine, origCol = mp.Source, mp.OriginalLine, mp.OriginalColumn
+		if mp.Name >= 0 {
+			writeVLQ(&sb, mp.Name-name)
+			name = mp.Name
+		}
+	}
+	return sb.String()
+}
+
+func writeVLQ(sb *strings.Builder, v int) {
+	u := v << 1
+	if v < 0 {
+		u = -v<<1 | 1
+	}
+	for {
+		digit := u & 31
+		u >>= 5
+		if u > 0 {
+			digit |= 32
+		}
+		sb.WriteByte(base64VLQ[digit])
+		if u == 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package goesprima
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/MichaelCombs28/goesprima/sourcemap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFprintSourceMap(t *testing.T) {
+	prog, err := Parse("a.js", "let x = 1;\nif (x) {\n  foo(x);\n}\n")
+	assert.NoError(t, err)
+
+	var sb strings.Builder
+	m, err := (&PrinterConfig{}).FprintSourceMap(&sb, prog)
+	assert.NoError(t, err)
+	assert.Equal(t, prog.String(), sb.String())
+	assert.Equal(t, []string{"a.js"}, m.Sources)
+	assert.Equal(t, []string{"x", "foo"}, m.Names)
+	assert.Equal(t, []Mapping{
+		mapping(0, 0, 0, 0, 0, -1),
+		mapping(0, 4, 0, 0, 4, 0),
+		mapping(1, 0, 0, 1, 0, -1),
+		mapping(1, 4, 0, 1, 4, 0),
+		mapping(2, 2, 0, 2, 2, 1),
+		mapping(2, 6, 0, 2, 6, 0),
+	}, m.Mappings)
+
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":3,"sources":["a.js"],"names":["x","foo"],"mappings":"AAAA,IAAIA;AACJ,IAAIA;EACFC,IAAID"}`, string(b))
+
+	parsed, err := sourcemap.Parse(b)
+	assert.NoError(t, err)
+	pos, ok := parsed.Lookup(2, 7)
+	assert.True(t, ok)
+	assert.Equal(t, sourcemap.Position{Source: "a.js", Line: 2, Column: 6, Name: "x"}, pos)
+
+	comment, err := m.InlineComment()
+	assert.NoError(t, err)
+	const prefix = "//# sourceMappingURL=data:application/json;charset=utf-8;base64,"
+	assert.True(t, strings.HasPrefix(comment, prefix))
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(comment, prefix))
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), string(decoded))
+}
+
+func TestFprintSourceMapMinify(t *testing.T) {
+	prog, err := Parse("a.js", "let x = 1;\nfoo(x);\n")
+	assert.NoError(t, err)
+
+	var sb strings.Builder
+	m, err := (&PrinterConfig{Minify: true}).FprintSourceMap(&sb, prog)
+	assert.NoError(t, err)
+	assert.Equal(t, "let x=1;foo(x)", sb.String())
+	assert.Equal(t, []Mapping{
+		mapping(0, 0, 0, 0, 0, -1),
+		mapping(0, 4, 0, 0, 4, 0),
+		mapping(0, 8, 0, 1, 0, 1),
+		mapping(0, 12, 0, 1, 4, 0),
+	}, m.Mappings)
+}
+
+func TestSourceMapColumns(t *testing.T) {
+	src := "f(\"😀\", é);"
+	prog, err := Parse("a.js", src)
+	assert.NoError(t, err)
+
+	var sb strings.Builder
+	m, err := (&PrinterConfig{}).FprintSourceMap(&sb, prog)
+	assert.NoError(t, err)
+	// columns are counted in UTF-16 code units, with or without the
+	// content of the source
+	assert.Equal(t, mapping(0, 8, 0, 0, 8, 1), m.Mappings[1])
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":3,"sources":["a.js"],"names":["f","é"],"mappings":"AAAAA,QAAQC"}`, string(b))
+
+	m.File = "a.min.js"
+	m.SourcesContent = []string{src}
+	b, err = json.Marshal(m)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":3,"file":"a.min.js","sources":["a.js"],"sourcesContent":["f(\"😀\", é);"],"names":["f","é"],"mappings":"AAAAA,QAAQC"}`, string(b))
+}
+
+func mapping(genLine, genCol, src, line, col, name int) Mapping {
+	return Mapping{GeneratedLine: genLine, GeneratedColumn: genCol, Source: src, OriginalLine: line, OriginalColumn: col, Name: name}
+}
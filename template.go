@@ -0,0 +1,484 @@
+package goesprima
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TemplateArgs maps placeholder names to the values substituted for them.
+type TemplateArgs map[string]interface{}
+
+// A Template is a JavaScript snippet containing placeholders of the form
+// %[name]s, eg.
+//
+//	const %[name]s = require(%[path]s);
+//
+// Executing a template parses the snippet and substitutes the arguments for
+// the placeholders according to where they appear:
+//
+//   - In expression position, nodes are used as is, strings become string
+//     literals, numbers and booleans become number and boolean literals and
+//     nil becomes null. Pass an *Identifier to reference a variable.
+//   - In binding, property name and label position, strings become
+//     identifiers.
+//   - As an element of an argument, array, parameter or property list, a
+//     slice is spliced into the list.
+//   - As a statement on its own, statements, slices of statements and
+//     expressions are spliced into the statement list.
+//
+// Node arguments are cloned for every placeholder they are substituted
+// for. The resulting nodes carry no positions.
+type Template struct {
+	src   string
+	names []string
+}
+
+type templateArgs struct {
+	values TemplateArgs
+	used   []string
+}
+
+func (t *templateArgs) use(name string) {
+	for _, n := range t.used {
+		if n == name {
+			return
+		}
+	}
+	t.used = append(t.used, name)
+}
+
+// ParseTemplate parses src as a template and reports syntax errors. The
+// template may be a list of statements or a single expression.
+func ParseTemplate(src string) (*Template, error) {
+	p := newTemplateParser(src, nil)
+	err := p.parseTemplate(func() { p.parseStatementList(false, p.atEOF) })
+	if err != nil {
+		p = newTemplateParser(src, nil)
+		if p.parseTemplate(func() { p.parseExpression(); p.expectEOF() }) != nil {
+			return nil, err
+		}
+	}
+	return &Template{src: src, names: p.tmpl.used}, nil
+}
+
+// MustParseTemplate is like ParseTemplate but panics if the template can't
+// be parsed. It simplifies initialization of global templates.
+func MustParseTemplate(src string) *Template {
+	t, err := ParseTemplate(src)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Names returns the placeholder names in order of first appearance.
+func (t *Template) Names() []string {
+	return append([]string(nil), t.names...)
+}
+
+func (t *Template) String() string {
+	return t.src
+}
+
+// Stmts executes the template and returns its statements.
+func (t *Template) Stmts(args TemplateArgs) (list []StatementListItem, err error) {
+	p := newTemplateParser(t.src, args)
+	err = p.parseTemplate(func() { list = p.parseStatementList(false, p.atEOF) })
+	return
+}
+
+// Stmt executes a template consisting of exactly one statement.
+func (t *Template) Stmt(args TemplateArgs) (StatementListItem, error) {
+	list, err := t.Stmts(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) != 1 {
+		return nil, fmt.Errorf("goesprima: template %q produced %d statements, want 1", t.src, len(list))
+	}
+	return list[0], nil
+}
+
+// Expr executes a template consisting of a single expression.
+func (t *Template) Expr(args TemplateArgs) (expr Expression, err error) {
+	p := newTemplateParser(t.src, args)
+	err = p.parseTemplate(func() {
+		expr = p.parseExpression()
+		p.expectEOF()
+	})
+	return
+}
+
+// Stmt parses and executes a template consisting of exactly one statement.
+func Stmt(src string, args TemplateArgs) (StatementListItem, error) {
+	return (&Template{src: src}).Stmt(args)
+}
+
+// Expr parses and executes a template consisting of a single expression.
+func Expr(src string, args TemplateArgs) (Expression, error) {
+	return (&Template{src: src}).Expr(args)
+}
+
+// MustStmt is like Stmt but panics on error.
+func MustStmt(src string, args TemplateArgs) StatementListItem {
+	s, err := Stmt(src, args)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// MustExpr is like Expr but panics on error.
+func MustExpr(src string, args TemplateArgs) Expression {
+	e, err := Expr(src, args)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// newTemplateParser returns a parser for a template. If args is nil the
+// template is only checked and placeholders stand in for themselves.
+func newTemplateParser(src string, args TemplateArgs) *parser {
+	p := &parser{
+		s:    newScanner("", src),
+		tmpl: &templateArgs{values: args},
+		ctx:  funcContext{inFunction: true, async: true},
+	}
+	p.s.placeholders = true
+	return p
+}
+
+func (p *parser) atEOF() bool {
+	return p.tok.kind == tokenEOF
+}
+
+// parseTemplate runs parse and checks that every argument was used.
+func (p *parser) parseTemplate(parse func()) (err error) {
+	defer p.recover(&err)
+	p.next()
+	parse()
+	if p.tmpl.values == nil {
+		return nil
+	}
+	var unused []string
+	for name := range p.tmpl.values {
+		if !contains(p.tmpl.used, name) {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return fmt.Errorf("goesprima: unused template arguments %s", strings.Join(unused, ", "))
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// A placeholder is a consumed placeholder token and its argument.
+type placeholder struct {
+	name   string
+	value  interface{}
+	offset int
+	// check is set when the template is only being checked.
+	check bool
+}
+
+func (p *parser) placeholder() placeholder {
+	if p.tok.kind != tokenPlaceholder {
+		p.unexpected()
+	}
+	ph := placeholder{name: p.tok.value, offset: p.tok.start}
+	p.tmpl.use(ph.name)
+	p.next()
+	if p.tmpl.values == nil {
+		ph.check = true
+		return ph
+	}
+	v, ok := p.tmpl.values[ph.name]
+	if !ok {
+		p.errorf(ph.offset, "missing value for %%[%s]s", ph.name)
+	}
+	ph.value = v
+	return ph
+}
+
+func (p *parser) invalidArgument(ph placeholder, v interface{}, what string) {
+	p.errorf(ph.offset, "cannot use %T as %s in %%[%s]s", v, what, ph.name)
+}
+
+// cloneArgument clones node arguments so that a node is never added to a
+// tree twice.
+func cloneArgument(v interface{}) interface{} {
+	if e, ok := v.(JSElement); ok && !isNilValue(reflect.ValueOf(e)) {
+		return Clone(e)
+	}
+	return v
+}
+
+// convertList converts a slice argument element by element, or a single
+// argument into a list of one.
+func convertList[T JSElement](p *parser, ph placeholder, what string, convert func(interface{}) (T, bool)) []T {
+	if rv := reflect.ValueOf(ph.value); ph.value != nil && rv.Kind() == reflect.Slice {
+		out := make([]T, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			v := rv.Index(i).Interface()
+			x, ok := convert(cloneArgument(v))
+			if !ok {
+				p.invalidArgument(ph, v, what)
+			}
+			out = append(out, x)
+		}
+		return out
+	}
+	x, ok := convert(cloneArgument(ph.value))
+	if !ok {
+		p.invalidArgument(ph, ph.value, what)
+	}
+	return []T{x}
+}
+
+func toExpression(v interface{}) (Expression, bool) {
+	switch v := v.(type) {
+	case nil:
+		return LiteralValueNull, true
+	case Expression:
+		return v, !isNilValue(reflect.ValueOf(v))
+	case string:
+		return StringLiteral(v), true
+	case bool:
+		return BoolLiteral(v), true
+	case *big.Float:
+		return NumberLiteral(v), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return NumberLiteral(reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0))).Float()), true
+	}
+	return nil, false
+}
+
+func toIdentifier(v interface{}) (*Identifier, bool) {
+	switch v := v.(type) {
+	case string:
+		return &Identifier{Name: v}, isIdentifierName(v)
+	case *Identifier:
+		return v, v != nil
+	}
+	return nil, false
+}
+
+func isIdentifierName(s string) bool {
+	for i, r := range s {
+		if r == '\\' || i == 0 && !isIdentifierStart(r) || !isIdentifierPart(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (p *parser) placeholderExpression() Expression {
+	ph := p.placeholder()
+	if ph.check {
+		return &Identifier{Name: ph.name}
+	}
+	e, ok := toExpression(cloneArgument(ph.value))
+	if !ok {
+		p.invalidArgument(ph, ph.value, "expression")
+	}
+	return e
+}
+
+func (p *parser) placeholderName() *Identifier {
+	ph := p.placeholder()
+	if ph.check {
+		return &Identifier{Name: ph.name}
+	}
+	id, ok := toIdentifier(cloneArgument(ph.value))
+	if !ok {
+		p.invalidArgument(ph, ph.value, "identifier")
+	}
+	return id
+}
+
+func toBindingTarget(v interface{}) (bindingTarget, bool) {
+	if id, ok := toIdentifier(v); ok {
+		return id, true
+	}
+	switch v := v.(type) {
+	case *ArrayPattern:
+		return v, v != nil
+	case *ObjectPattern:
+		return v, v != nil
+	}
+	return nil, false
+}
+
+func (p *parser) placeholderBinding() bindingTarget {
+	ph := p.placeholder()
+	if ph.check {
+		return &Identifier{Name: ph.name}
+	}
+	b, ok := toBindingTarget(cloneArgument(ph.value))
+	if !ok {
+		p.invalidArgument(ph, ph.value, "binding")
+	}
+	return b
+}
+
+func (p *parser) placeholderKey() (PropertyKey, bool) {
+	ph := p.placeholder()
+	if ph.check {
+		return &Identifier{Name: ph.name}, false
+	}
+	v := cloneArgument(ph.value)
+	if id, ok := toIdentifier(v); ok {
+		return id, false
+	}
+	switch v := v.(type) {
+	case Literal:
+		return v, false
+	case Expression:
+		return v, true
+	}
+	p.invalidArgument(ph, ph.value, "property key")
+	return nil, false
+}
+
+func (p *parser) placeholderSource() string {
+	ph := p.placeholder()
+	switch v := ph.value.(type) {
+	case string:
+		return v
+	case *LiteralValueString:
+		return string(*v)
+	}
+	if ph.check {
+		return ph.name
+	}
+	p.invalidArgument(ph, ph.value, "module source")
+	return ""
+}
+
+func (p *parser) placeholderArguments() []ArgumentListElement {
+	ph := p.placeholder()
+	if ph.check {
+		return []ArgumentListElement{&Identifier{Name: ph.name}}
+	}
+	return convertList(p, ph, "argument", func(v interface{}) (ArgumentListElement, bool) {
+		if s, ok := v.(*SpreadElement); ok {
+			return s, s != nil
+		}
+		return toExpression(v)
+	})
+}
+
+func (p *parser) placeholderElements() []ArrayExpressionElement {
+	ph := p.placeholder()
+	if ph.check {
+		return []ArrayExpressionElement{&Identifier{Name: ph.name}}
+	}
+	return convertList(p, ph, "array element", func(v interface{}) (ArrayExpressionElement, bool) {
+		if s, ok := v.(*SpreadElement); ok {
+			return s, s != nil
+		}
+		return toExpression(v)
+	})
+}
+
+func (p *parser) placeholderParams() []FunctionParameter {
+	ph := p.placeholder()
+	if ph.check {
+		return []FunctionParameter{&Identifier{Name: ph.name}}
+	}
+	return convertList(p, ph, "parameter", func(v interface{}) (FunctionParameter, bool) {
+		if id, ok := toIdentifier(v); ok {
+			return id, true
+		}
+		f, ok := v.(FunctionParameter)
+		return f, ok && !isNilValue(reflect.ValueOf(f))
+	})
+}
+
+func (p *parser) placeholderProperties() []ObjectExpressionProperty {
+	ph := p.placeholder()
+	if ph.check {
+		return []ObjectExpressionProperty{shorthandProperty(ph.name)}
+	}
+	return convertList(p, ph, "property", func(v interface{}) (ObjectExpressionProperty, bool) {
+		if s, ok := v.(string); ok {
+			return shorthandProperty(s), isIdentifierName(s)
+		}
+		prop, ok := v.(ObjectExpressionProperty)
+		return prop, ok && !isNilValue(reflect.ValueOf(prop))
+	})
+}
+
+func shorthandProperty(name string) *Property {
+	return &Property{Key: &Identifier{Name: name}, Value: &Identifier{Name: name}, ShortHand: true}
+}
+
+// parsePlaceholderStatements splices the argument of a placeholder that
+// stands on its own as a statement into a statement list. It reports false
+// if the placeholder is part of a larger statement or its argument is not
+// a statement, in which case nothing is consumed.
+func (p *parser) parsePlaceholderStatements() ([]StatementListItem, bool) {
+	t := p.peek()
+	if !isPunctuator(t, ";") && !isPunctuator(t, "}") && t.kind != tokenEOF && !t.newline {
+		return nil, false
+	}
+	if p.tmpl.values != nil {
+		v, ok := p.tmpl.values[p.tok.value]
+		if !ok || !isStatementArgument(v) {
+			return nil, false
+		}
+	}
+
+	ph := p.placeholder()
+	p.eat(";")
+	if ph.check || ph.value == nil {
+		return nil, true
+	}
+	if reflect.ValueOf(ph.value).Kind() == reflect.Slice && reflect.ValueOf(ph.value).Len() == 0 {
+		return nil, true
+	}
+	return convertList(p, ph, "statement", toStatement), true
+}
+
+func isStatementArgument(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		for i := 0; i < rv.Len(); i++ {
+			if _, ok := toStatement(rv.Index(i).Interface()); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	_, ok := toStatement(v)
+	return ok
+}
+
+func toStatement(v interface{}) (StatementListItem, bool) {
+	switch v := v.(type) {
+	case StatementListItem:
+		return v, !isNilValue(reflect.ValueOf(v))
+	case Expression:
+		if isNilValue(reflect.ValueOf(v)) {
+			return nil, false
+		}
+		return &ExpressionStatement{Expression: v}, true
+	}
+	return nil, false
+}
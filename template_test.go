@@ -0,0 +1,109 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("const %[name]s = require(%[path]s);")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "path"}, tmpl.Names())
+
+	stmt, err := tmpl.Stmt(TemplateArgs{"name": "fs", "path": "fs"})
+	assert.NoError(t, err)
+	assert.Equal(t, &VariableDeclaration{
+		Kind: VariableDeclarationTypeConst,
+		Declarations: []VariableDeclarator{
+			{
+				ID: &Identifier{Name: "fs"},
+				Init: &CallExpression{
+					Callee:    &Identifier{Name: "require"},
+					Arguments: []ArgumentListElement{StringLiteral("fs")},
+				},
+			},
+		},
+	}, stmt)
+
+	_, err = tmpl.Stmt(TemplateArgs{"name": "fs"})
+	assert.EqualError(t, err, "1:25: missing value for %[path]s")
+	_, err = tmpl.Stmt(TemplateArgs{"name": "fs", "path": "fs", "mode": 1})
+	assert.EqualError(t, err, "goesprima: unused template arguments mode")
+	_, err = tmpl.Stmt(TemplateArgs{"name": 1, "path": "fs"})
+	assert.EqualError(t, err, "1:6: cannot use int as binding in %[name]s")
+
+	_, err = ParseTemplate("const = %[x]s")
+	assert.EqualError(t, err, "1:6: unexpected token =")
+}
+
+func TestTemplateGenerator(t *testing.T) {
+	stmt := MustStmt("export const %[name]s = process.env.%[env]s;", TemplateArgs{
+		"name": "USER_POOL_CLIENT_ID_TODOUSERS",
+		"env":  "REACT_APP_USER_POOL_CLIENT_ID_TODOUSERS",
+	})
+	expect := &ExportNamedDeclaration{
+		Declaration: &VariableDeclaration{
+			Kind: VariableDeclarationTypeConst,
+			Declarations: []VariableDeclarator{
+				{
+					ID: &Identifier{Name: "USER_POOL_CLIENT_ID_TODOUSERS"},
+					Init: &StaticMemberExpression{
+						Object: &StaticMemberExpression{
+							Object:   &Identifier{Name: "process"},
+							Property: &Identifier{Name: "env"},
+						},
+						Property: &Identifier{Name: "REACT_APP_USER_POOL_CLIENT_ID_TODOUSERS"},
+					},
+				},
+			},
+		},
+	}
+	assert.Empty(t, Diff(expect, stmt))
+
+	config := MustExpr("{region: %[region]s, storage: localStorage, %[extra]s}", TemplateArgs{
+		"region": &Identifier{Name: "USER_POOL_REGION_TODOUSERS"},
+		"extra": []ObjectExpressionProperty{
+			&Property{Key: &Identifier{Name: "SecondaryAuths"}, Value: LiteralValueNull},
+		},
+	})
+	assert.Equal(t, `{
+  region: USER_POOL_REGION_TODOUSERS,
+  storage: localStorage,
+  SecondaryAuths: null,
+}`, config.String())
+}
+
+func TestTemplateSplice(t *testing.T) {
+	tmpl := MustParseTemplate(`function %[name]s(%[params]s) {
+  %[body]s
+  return f(%[args]s, [%[items]s]);
+}`)
+	body := []StatementListItem{
+		MustStmt("console.log(a);", nil),
+		MustStmt("b++;", nil),
+	}
+	arg := &Identifier{Name: "b"}
+	list, err := tmpl.Stmts(TemplateArgs{
+		"name":   "g",
+		"params": []string{"a", "b"},
+		"body":   body,
+		"args":   []interface{}{"a", 1, arg},
+		"items":  arg,
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, `function g(a, b) {
+  console.log(a);
+  b++;
+  return f("a", 1.000000, b, [
+    b,
+  ]);
+}`, list[0].String())
+	}
+
+	// node arguments are copied for every use
+	ret := list[0].(*FunctionDeclaration).Body.Items[2].(*ReturnStatement).Argument.(*CallExpression)
+	assert.NotSame(t, arg, ret.Arguments[2])
+	assert.NotSame(t, ret.Arguments[2], ret.Arguments[3].(*ArrayExpression).Elements[0])
+}
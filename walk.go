@@ -0,0 +1,397 @@
+package goesprima
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node JSElement) (w Visitor)
+}
+
+// Walk traverses a tree in depth-first order. It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor
+// w for each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+//
+// Value-typed children such as ArrowFunctionExpression.Body,
+// VariableDeclarator and SwitchCase are visited through a pointer to the
+// field, so visitors may modify them in place.
+func Walk(v Visitor, node JSElement) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	// Program
+	case *Generator:
+		walkList(v, n.Statements)
+
+	case *Program:
+		walkList(v, n.Body)
+
+	// Exports
+	case *ExportAllDeclaration:
+		if n.Exported != nil {
+			Walk(v, n.Exported)
+		}
+		if n.Source != nil {
+			Walk(v, n.Source)
+		}
+
+	case *ExportDefaultDeclaration:
+		Walk(v, n.Declaration)
+
+	case *ExportNamedDeclaration:
+		if n.Declaration != nil {
+			Walk(v, n.Declaration)
+		}
+		for i := range n.Specifiers {
+			Walk(v, &n.Specifiers[i])
+		}
+		if n.Source != nil {
+			Walk(v, n.Source)
+		}
+
+	case *ExportSpecifier:
+		if n.Exported != nil {
+			Walk(v, n.Exported)
+		}
+		if n.Local != nil {
+			Walk(v, n.Local)
+		}
+
+	// Patterns
+	case *ArrayPattern:
+		walkList(v, n.Elements)
+
+	case *ObjectPattern:
+		walkList(v, n.Properties)
+
+	case *AssignmentPattern:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *RestElement:
+		Walk(v, n.Argument)
+
+	case *PropertyPattern:
+		Walk(v, n.Key)
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	// Leaves
+	case *Identifier, *literalValueNull, *literalValueUndefined,
+		*LiteralValueString, *LiteralValueBool, *LiteralValueNumber,
+		*LiteralValueBigFloat, *TemplateElement, *DebuggerStatement,
+		*EmptyStatement, *ThisExpression, *Super, *Import:
+		// nothing to do
+
+	// Expressions
+	case *ArrayExpression:
+		walkList(v, n.Elements)
+
+	case *ArrowFunctionExpression:
+		walkList(v, n.Params)
+		if n.Expression != nil {
+			Walk(v, n.Expression)
+		} else {
+			Walk(v, &n.Body)
+		}
+
+	case *AwaitExpression:
+		Walk(v, n.Arguement)
+
+	case *AssignmentExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *BinaryExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *LogicalExpression:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case *CallExpression:
+		Walk(v, n.Callee)
+		walkList(v, n.Arguments)
+
+	case *ChainExpression:
+		Walk(v, n.Expression)
+
+	case *ClassExpression:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		if n.SuperClass != nil {
+			Walk(v, n.SuperClass)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *ComputedMemberExpression:
+		Walk(v, n.Object)
+		Walk(v, n.Property)
+
+	case *ConditionalExpression:
+		Walk(v, n.Test)
+		Walk(v, n.Consequent)
+		Walk(v, n.Alternate)
+
+	case *FunctionExpression:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		walkList(v, n.Params)
+		Walk(v, &n.Body)
+
+	case *MetaProperty:
+		Walk(v, &n.Meta)
+		Walk(v, &n.Property)
+
+	case *NewExpression:
+		Walk(v, n.Callee)
+		walkList(v, n.Arguments)
+
+	case *ObjectExpression:
+		walkList(v, n.Properties)
+
+	case *Property:
+		Walk(v, n.Key)
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	case *SequenceExpression:
+		walkList(v, n.Expressions)
+
+	case *SpreadElement:
+		Walk(v, n.Argument)
+
+	case *StaticMemberExpression:
+		Walk(v, n.Object)
+		Walk(v, n.Property)
+
+	case *TaggedTemplateExpression:
+		Walk(v, n.Tag)
+		Walk(v, &n.Quasi)
+
+	case *TemplateLiteral:
+		for i := range n.Quasis {
+			Walk(v, &n.Quasis[i])
+		}
+		walkList(v, n.Expressions)
+
+	case *UnaryExpression:
+		Walk(v, n.Argument)
+
+	case *UpdateExpression:
+		Walk(v, n.Argument)
+
+	case *YieldExpression:
+		if n.Argument != nil {
+			Walk(v, n.Argument)
+		}
+
+	// Classes
+	case *ClassDeclaration:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		if n.SuperClass != nil {
+			Walk(v, n.SuperClass)
+		}
+		if n.Body != nil {
+			Walk(v, n.Body)
+		}
+
+	case *ClassBody:
+		walkList(v, n.Properties)
+
+	case *MethodDefinition:
+		Walk(v, n.Key)
+		Walk(v, &n.Value)
+
+	case *PropertyDefinition:
+		Walk(v, n.Key)
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+
+	// Declarations
+	case *FunctionDeclaration:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		walkList(v, n.Params)
+		Walk(v, &n.Body)
+
+	case *ImportDeclaration:
+		walkList(v, n.Specifiers)
+
+	case *ImportDefaultSpecifier:
+		Walk(v, n.Local)
+
+	case *ImportNamespaceSpecifier:
+		Walk(v, n.Local)
+
+	case *ImportSpecifier:
+		for i := range n.NamedImports {
+			Walk(v, &n.NamedImports[i])
+		}
+
+	case *NamedImport:
+		Walk(v, n.Imported)
+		if n.Local != nil {
+			Walk(v, n.Local)
+		}
+
+	case *VariableDeclaration:
+		for i := range n.Declarations {
+			Walk(v, &n.Declarations[i])
+		}
+
+	case *VariableDeclarator:
+		Walk(v, n.ID)
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+
+	// Statements
+	case *BlockStatement:
+		walkList(v, n.Items)
+
+	case *BreakStatement:
+		if n.Label != nil {
+			Walk(v, n.Label)
+		}
+
+	case *ContinueStatement:
+		if n.Label != nil {
+			Walk(v, n.Label)
+		}
+
+	case *DoWhileStatement:
+		Walk(v, &n.Body)
+		Walk(v, n.Test)
+
+	case *ExpressionStatement:
+		Walk(v, n.Expression)
+
+	case *Directive:
+		if n.Expression != nil {
+			Walk(v, n.Expression)
+		}
+
+	case *ForStatement:
+		if n.Init != nil {
+			Walk(v, n.Init)
+		}
+		if n.Test != nil {
+			Walk(v, n.Test)
+		}
+		if n.Update != nil {
+			Walk(v, n.Update)
+		}
+		Walk(v, &n.Body)
+
+	case *ForInStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+		Walk(v, &n.Body)
+
+	case *ForOfStatement:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+		Walk(v, &n.Body)
+
+	case *IfStatement:
+		Walk(v, n.Test)
+		Walk(v, n.Consequent)
+		if n.Alternate != nil {
+			Walk(v, n.Alternate)
+		}
+
+	case *LabeledStatement:
+		Walk(v, &n.Label)
+		Walk(v, n.Body)
+
+	case *ReturnStatement:
+		if n.Argument != nil {
+			Walk(v, n.Argument)
+		}
+
+	case *SwitchStatement:
+		Walk(v, n.Discriminant)
+		for i := range n.Cases {
+			Walk(v, &n.Cases[i])
+		}
+
+	case *SwitchCase:
+		if n.Test != nil {
+			Walk(v, n.Test)
+		}
+		Walk(v, &n.Consequent)
+
+	case *ThrowStatement:
+		Walk(v, n.Argument)
+
+	case *TryStatement:
+		Walk(v, &n.Block)
+		if n.HasHandler() {
+			Walk(v, &n.Handler)
+		}
+		if n.Finalizer != nil {
+			Walk(v, n.Finalizer)
+		}
+
+	case *CatchClause:
+		if n.BindingIdentifierOrPattern != nil {
+			Walk(v, n.BindingIdentifierOrPattern)
+		}
+		Walk(v, &n.Body)
+
+	case *WhileStatement:
+		Walk(v, n.Test)
+		Walk(v, n.Body)
+
+	case *WithStatement:
+		Walk(v, n.Object)
+		Walk(v, n.Body)
+
+	default:
+		panic(fmt.Sprintf("goesprima.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkList[T JSElement](v Visitor, list []T) {
+	for _, node := range list {
+		if JSElement(node) != nil {
+			Walk(v, node)
+		}
+	}
+}
+
+type inspector func(JSElement) bool
+
+func (f inspector) Visit(node JSElement) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a tree in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node JSElement, f func(JSElement) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,111 @@
+package goesprima
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	tree := &TryStatement{
+		Block: BlockStatement{
+			Items: []Statement{
+				&SwitchStatement{
+					Discriminant: &Identifier{Name: "a"},
+					Cases: []SwitchCase{
+						{
+							Test: &Identifier{Name: "b"},
+							Consequent: BlockStatement{
+								Items: []Statement{
+									&ExpressionStatement{
+										Expression: &ArrowFunctionExpression{
+											Params: []FunctionParameter{&Identifier{Name: "c"}},
+											Body: BlockStatement{
+												Items: []Statement{
+													&ReturnStatement{Argument: &Identifier{Name: "d"}},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Handler: CatchClause{
+			BindingIdentifierOrPattern: &Identifier{Name: "e"},
+			Body: BlockStatement{
+				Items: []Statement{
+					&IfStatement{
+						Test:       &Identifier{Name: "f"},
+						Consequent: &ThrowStatement{Argument: &Identifier{Name: "e"}},
+					},
+				},
+			},
+		},
+	}
+	decl := &VariableDeclaration{
+		Kind: VariableDeclarationTypeLet,
+		Declarations: []VariableDeclarator{
+			{ID: &Identifier{Name: "g"}, Init: &ObjectExpression{
+				Properties: []ObjectExpressionProperty{
+					&Property{Key: &Identifier{Name: "h"}, Value: &Identifier{Name: "i"}},
+				},
+			}},
+		},
+	}
+	program := &Program{Body: []StatementListItem{decl, tree}}
+
+	var names []string
+	var depth, maxDepth int
+	Inspect(program, func(n JSElement) bool {
+		if n == nil {
+			depth--
+			return false
+		}
+		depth++
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		if id, ok := n.(*Identifier); ok {
+			names = append(names, id.Name)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"g", "h", "i", "a", "b", "c", "d", "e", "f", "e"}, names)
+	assert.Equal(t, 0, depth)
+	assert.Equal(t, 11, maxDepth)
+
+	var visited int
+	Inspect(program, func(n JSElement) bool {
+		if n != nil {
+			visited++
+		}
+		_, isDecl := n.(*VariableDeclaration)
+		return !isDecl
+	})
+	assert.Equal(t, 22, visited)
+}
+
+func TestInspectTemplateLiteral(t *testing.T) {
+	tmpl := &TaggedTemplateExpression{
+		Tag: &Identifier{Name: "tag"},
+		Quasi: TemplateLiteral{
+			Quasis:      []TemplateElement{{Raw: "a"}, {Raw: "c", Tail: true}},
+			Expressions: []Expression{&Identifier{Name: "b"}},
+		},
+	}
+	var visited []string
+	Inspect(tmpl, func(n JSElement) bool {
+		switch n := n.(type) {
+		case *Identifier:
+			visited = append(visited, n.Name)
+		case *TemplateElement:
+			visited = append(visited, n.Raw)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"tag", "a", "c", "b"}, visited)
+}